@@ -0,0 +1,47 @@
+package easybreaker
+
+import (
+	"errors"
+	"time"
+)
+
+// StrategyAccumulator is the strategy of WithAccumulator.
+const StrategyAccumulator = "accumulator"
+
+// Accumulator is a custom stats accumulator and decision engine, hosting in
+// the state machine of the breaker a windowing scheme the package doesn't ship.
+// Its methods are called concurrently.
+type Accumulator[S any] interface {
+	// Record accumulates the outcome of a request finished at now.
+	Record(now time.Time, err error)
+	// Stats returns the accumulated stats.
+	Stats() S
+	// ShouldOpen is called on every failure in the closed state.
+	ShouldOpen(stats S) bool
+	// ShouldClose is called in the half-open state once atLeastReqs requests
+	// are counted, the breaker opens again if it returns false.
+	ShouldClose(stats S) bool
+	// Reset discards the stats, it's called on every state change.
+	Reset()
+}
+
+// Accumulator replaces the state functions of the breaker with the
+// decisions of acc on its own stats, New fails if WithStateFunc is set too.
+func WithAccumulator[S any](acc Accumulator[S]) OptionCall {
+	return func(b *Breaker) error {
+		if acc == nil {
+			return errors.New("circuit: accumulator must be defined")
+		}
+		b.acc = acc
+		b.toOpenState = func(uint32, uint32) bool { return acc.ShouldOpen(acc.Stats()) }
+		b.toClosedState = func(uint32, uint32) bool { return acc.ShouldClose(acc.Stats()) }
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
+// recorder is the part of Accumulator independent from the stats type.
+type recorder interface {
+	Record(now time.Time, err error)
+	Reset()
+}
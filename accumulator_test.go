@@ -0,0 +1,76 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ewma is an exponentially weighted failure rate
+type ewma struct {
+	mu   sync.Mutex
+	rate float64
+}
+
+func (e *ewma) Record(now time.Time, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	v := 0.0
+	if err != nil {
+		v = 1
+	}
+	e.rate = 0.5*e.rate + 0.5*v
+}
+
+func (e *ewma) Stats() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.rate
+}
+
+func (e *ewma) ShouldOpen(rate float64) bool  { return rate > 0.7 }
+func (e *ewma) ShouldClose(rate float64) bool { return rate < 0.1 }
+
+func (e *ewma) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rate = 0
+}
+
+func TestBreaker_Accumulator(t *testing.T) {
+	acc := &ewma{}
+	b, err := New(time.Minute, time.Minute, WithAccumulator[float64](acc), WithLeastReqs(1), withTime(1520100000))
+	assert.NoError(t, err)
+
+	failed := errors.New("failed")
+	b.Execute(func() error { return failed })
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return failed })
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, 0.625, acc.Stats())
+
+	b.Execute(func() error { return failed })
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, float64(0), acc.Stats())
+	reason, _ := b.TripReason()
+	assert.Equal(t, StrategyAccumulator, reason.Strategy)
+
+	b.now = now(1520100060)
+	b.Execute(func() error { return nil })
+	assert.Equal(t, StateHalfOpen, b.State())
+	b.Execute(func() error { return nil })
+	assert.Equal(t, StateClosed, b.State())
+
+	_, err = New(time.Minute, time.Minute, WithAccumulator[float64](nil))
+	assert.Error(t, err)
+
+	// the state functions would override the decisions of the accumulator
+	stateFunc := WithStateFunc(defaultToOpen, defaultToClosed)
+	_, err = New(time.Minute, time.Minute, WithAccumulator[float64](acc), stateFunc)
+	assert.EqualError(t, err, "circuit: accumulator and state functions are exclusive")
+	_, err = New(time.Minute, time.Minute, stateFunc, WithAccumulator[float64](acc))
+	assert.Error(t, err)
+}
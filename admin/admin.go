@@ -0,0 +1,240 @@
+// Package admin exposes the breakers of a registry over HTTP for the
+// operators, the handler is mounted under a prefix of an internal port:
+//
+//	h, err := admin.NewHandler(registry)
+//	mux.Handle("/breakers/", http.StripPrefix("/breakers", h))
+//
+// The endpoints are:
+//
+//	GET  /                    the state and the counts of every breaker
+//	GET  /{name}              the internal state of the breaker, see easybreaker.DebugInfo
+//	GET  /{name}/history      the audit trail of the manual control actions
+//	POST /{name}/force-open   rejects the requests until released
+//	POST /{name}/disable      accepts the requests until released
+//	POST /{name}/release      hands the breaker back to its state machine
+//	POST /{name}/trip         opens the breaker for "for", a duration, or the cooldown
+//	POST /{name}/reset        closes the breaker and clears its counts
+//	POST /{name}/tag          sets the tag "key" to "value"
+//	POST /{name}/untag        removes the tag "key"
+//
+// The control actions record the "who" and "reason" form values, "who"
+// defaults to the header of WithUserHeader, set by a trusted proxy. They
+// require the bearer token set with WithToken, or WithInsecure, and are
+// refused in the read-only mode, the GET endpoints are always open.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Handler is the http.Handler of the admin endpoints.
+type Handler struct {
+	registry   *easybreaker.Registry
+	token      string
+	insecure   bool
+	userHeader string
+	readOnly   bool
+}
+
+// Breaker is the summary of a breaker listed by GET /.
+type Breaker struct {
+	Name   string             `json:"name"`
+	Tags   map[string]string  `json:"tags,omitempty"`
+	State  easybreaker.State  `json:"state"`
+	Counts easybreaker.Counts `json:"counts"`
+}
+
+type OptionCall func(*Handler) error
+
+// Token requires the control actions to be authorized
+// with the "Authorization: Bearer <token>" header.
+func WithToken(token string) OptionCall {
+	return func(h *Handler) error {
+		if token == "" {
+			return errors.New("admin: token must be set")
+		}
+		h.token = token
+		return nil
+	}
+}
+
+// Insecure accepts the control actions without a token, e.g. behind
+// a proxy authenticating the operators. Without it nor WithToken the
+// control actions are refused.
+func WithInsecure() OptionCall {
+	return func(h *Handler) error {
+		h.insecure = true
+		return nil
+	}
+}
+
+// UserHeader is the header of the operator of a control action without
+// the "who" form value, e.g. X-Forwarded-User, set by a proxy authenticating
+// the operators. It's not trusted by default, as any client can set it.
+func WithUserHeader(name string) OptionCall {
+	return func(h *Handler) error {
+		if name == "" {
+			return errors.New("admin: user header must be set")
+		}
+		h.userHeader = name
+		return nil
+	}
+}
+
+// ReadOnly refuses the control actions, only the stats are served.
+func WithReadOnly() OptionCall {
+	return func(h *Handler) error {
+		h.readOnly = true
+		return nil
+	}
+}
+
+// NewHandler returns the handler of the breakers of registry.
+func NewHandler(registry *easybreaker.Registry, fns ...OptionCall) (*Handler, error) {
+	if registry == nil {
+		return nil, errors.New("admin: registry must be set")
+	}
+
+	h := &Handler{registry: registry}
+	for _, fn := range fns {
+		if err := fn(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.list(w)
+		return
+	}
+
+	name, action := path, ""
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		name, action = path[:i], path[i+1:]
+	}
+	b, ok := h.registry.Lookup(name)
+	if !ok {
+		// the name of the breaker may contain slashes
+		if b, ok = h.registry.Lookup(path); !ok {
+			http.NotFound(w, r)
+			return
+		}
+		name, action = path, ""
+	}
+
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, b.Debug())
+	case "history":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, b.History())
+	case "force-open", "disable", "release", "trip", "reset", "tag", "untag":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if h.readOnly {
+			http.Error(w, "read-only", http.StatusForbidden)
+			return
+		}
+		if h.token == "" && !h.insecure {
+			http.Error(w, "control actions require a token", http.StatusForbidden)
+			return
+		}
+		if !h.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="easybreaker"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.control(w, r, name, b, action)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		return true
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+func (h *Handler) list(w http.ResponseWriter) {
+	breakers := []Breaker{}
+	h.registry.ForEach(func(name string, b *easybreaker.Breaker) bool {
+		breakers = append(breakers, summarize(name, b))
+		return true
+	})
+	writeJSON(w, breakers)
+}
+
+func (h *Handler) control(w http.ResponseWriter, r *http.Request, name string, b *easybreaker.Breaker, action string) {
+	who := r.FormValue("who")
+	if who == "" && h.userHeader != "" {
+		who = r.Header.Get(h.userHeader)
+	}
+	opts := []easybreaker.ControlOption{
+		easybreaker.By(who),
+		easybreaker.Because(r.FormValue("reason")),
+	}
+
+	switch action {
+	case "force-open":
+		b.ForceOpen(opts...)
+	case "disable":
+		b.Disable(opts...)
+	case "release":
+		b.Release(opts...)
+	case "trip":
+		var d time.Duration
+		if v := r.FormValue("for"); v != "" {
+			var err error
+			if d, err = time.ParseDuration(v); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		b.Trip(d, opts...)
+	case "reset":
+		b.Reset(opts...)
+	case "tag":
+		if err := b.SetTag(r.FormValue("key"), r.FormValue("value")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case "untag":
+		b.DeleteTag(r.FormValue("key"))
+	}
+	writeJSON(w, summarize(name, b))
+}
+
+func summarize(name string, b *easybreaker.Breaker) Breaker {
+	return Breaker{Name: name, Tags: b.Tags(), State: b.State(), Counts: b.Counts()}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
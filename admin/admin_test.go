@@ -0,0 +1,191 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(h http.Handler, method, target string, form url.Values) *httptest.ResponseRecorder {
+	var body *strings.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	} else {
+		body = strings.NewReader("")
+	}
+	req := httptest.NewRequest(method, target, body)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	reg := easybreaker.NewRegistry(time.Minute, time.Minute)
+	api, _ := reg.Get("api")
+	reg.Get("db/primary")
+
+	h, err := NewHandler(reg, WithInsecure(), WithUserHeader("X-Forwarded-User"))
+	assert.NoError(t, err)
+
+	w := serve(h, http.MethodGet, "/", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[
+		{"name": "api", "state": "closed", "counts": {"Total": 0, "Failures": 0, "Successes": 0, "Timeouts": 0, "Ignored": 0, "Slow": 0, "InFlight": 0}},
+		{"name": "db/primary", "state": "closed", "counts": {"Total": 0, "Failures": 0, "Successes": 0, "Timeouts": 0, "Ignored": 0, "Slow": 0, "InFlight": 0}}
+	]`, w.Body.String())
+
+	w = serve(h, http.MethodPost, "/api/force-open", url.Values{"who": {"alice"}, "reason": {"INC-42"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, easybreaker.StateOpen, api.State())
+	assert.Equal(t, easybreaker.ErrBreakerOpen, api.Allow())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/release", nil)
+	req.Header.Set("X-Forwarded-User", "bob")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.NoError(t, api.Allow())
+
+	w = serve(h, http.MethodGet, "/api/history", nil)
+	var history []easybreaker.Action
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Len(t, history, 2)
+	assert.Equal(t, easybreaker.ActionForceOpen, history[0].Kind)
+	assert.Equal(t, "alice", history[0].Who)
+	assert.Equal(t, "INC-42", history[0].Reason)
+	assert.Equal(t, easybreaker.ActionRelease, history[1].Kind)
+	assert.Equal(t, "bob", history[1].Who)
+
+	w = serve(h, http.MethodGet, "/db/primary", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var debug map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &debug))
+	assert.Equal(t, "db/primary", debug["Name"])
+
+	assert.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "/cache", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "/api/unknown", nil).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(h, http.MethodGet, "/api/disable", nil).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(h, http.MethodPost, "/api", nil).Code)
+
+	_, err = NewHandler(nil)
+	assert.Error(t, err)
+}
+
+func TestHandler_Token(t *testing.T) {
+	reg := easybreaker.NewRegistry(time.Minute, time.Minute)
+	api, _ := reg.Get("api")
+
+	h, err := NewHandler(reg, WithToken("secret"))
+	assert.NoError(t, err)
+
+	// the stats are open
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api", nil).Code)
+
+	w := serve(h, http.MethodPost, "/api/force-open", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="easybreaker"`, w.Header().Get("WWW-Authenticate"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/force-open", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, easybreaker.StateClosed, api.State())
+
+	req = httptest.NewRequest(http.MethodPost, "/api/force-open", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, easybreaker.StateOpen, api.State())
+
+	// the user header is not trusted by default
+	req = httptest.NewRequest(http.MethodPost, "/api/release", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Forwarded-User", "mallory")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, api.History()[1].Who)
+
+	_, err = NewHandler(reg, WithToken(""))
+	assert.Error(t, err)
+	_, err = NewHandler(reg, WithUserHeader(""))
+	assert.Error(t, err)
+}
+
+func TestHandler_NoToken(t *testing.T) {
+	reg := easybreaker.NewRegistry(time.Minute, time.Minute)
+	api, _ := reg.Get("api")
+
+	// the control actions are refused without a token nor WithInsecure
+	h, err := NewHandler(reg)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api", nil).Code)
+	assert.Equal(t, http.StatusForbidden, serve(h, http.MethodPost, "/api/force-open", nil).Code)
+	assert.Equal(t, easybreaker.StateClosed, api.State())
+	assert.Empty(t, api.History())
+}
+
+func TestHandler_ReadOnly(t *testing.T) {
+	reg := easybreaker.NewRegistry(time.Minute, time.Minute)
+	api, _ := reg.Get("api")
+
+	h, err := NewHandler(reg, WithReadOnly())
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/history", nil).Code)
+	assert.Equal(t, http.StatusForbidden, serve(h, http.MethodPost, "/api/disable", nil).Code)
+	assert.Empty(t, api.History())
+}
+
+func TestHandler_Tags(t *testing.T) {
+	reg := easybreaker.NewRegistry(time.Minute, time.Minute)
+	api, _ := reg.Get("api")
+
+	h, err := NewHandler(reg, WithInsecure())
+	assert.NoError(t, err)
+
+	w := serve(h, http.MethodPost, "/api/tag", url.Values{"key": {"severity"}, "value": {"critical"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name": "api", "tags": {"severity": "critical"}, "state": "closed",
+		"counts": {"Total": 0, "Failures": 0, "Successes": 0, "Timeouts": 0, "Ignored": 0, "Slow": 0, "InFlight": 0}}`, w.Body.String())
+	assert.Equal(t, map[string]string{"severity": "critical"}, api.Tags())
+
+	assert.Equal(t, http.StatusBadRequest, serve(h, http.MethodPost, "/api/tag", url.Values{"value": {"critical"}}).Code)
+
+	serve(h, http.MethodPost, "/api/untag", url.Values{"key": {"severity"}})
+	assert.Empty(t, api.Tags())
+}
+
+func TestHandler_TripReset(t *testing.T) {
+	reg := easybreaker.NewRegistry(time.Minute, time.Minute)
+	api, _ := reg.Get("api")
+
+	h, err := NewHandler(reg, WithInsecure())
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, serve(h, http.MethodPost, "/api/trip", url.Values{"for": {"soon"}}).Code)
+	assert.Equal(t, easybreaker.StateClosed, api.State())
+
+	w := serve(h, http.MethodPost, "/api/trip", url.Values{"for": {"1h"}, "who": {"alice"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, easybreaker.StateOpen, api.State())
+
+	w = serve(h, http.MethodPost, "/api/reset", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, easybreaker.StateClosed, api.State())
+
+	history := api.History()
+	assert.Len(t, history, 2)
+	assert.Equal(t, easybreaker.ActionTrip, history[0].Kind)
+	assert.Equal(t, "alice", history[0].Who)
+	assert.Equal(t, easybreaker.ActionReset, history[1].Kind)
+}
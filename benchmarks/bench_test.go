@@ -0,0 +1,139 @@
+// Package benchmarks compares easybreaker with sony/gobreaker and hystrix-go.
+//
+// It's a separate module so the main module doesn't depend on them:
+//
+//	cd benchmarks && go test -bench . -benchmem
+package benchmarks
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/sony/gobreaker"
+)
+
+var errFailed = errors.New("benchmarks: failed")
+
+func success() error { return nil }
+
+func newEasyBreaker(tb testing.TB) *easybreaker.Breaker {
+	b, err := easybreaker.New(time.Minute, time.Minute)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return b
+}
+
+func newGoBreaker() *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:     "bench",
+		Interval: time.Minute,
+		Timeout:  time.Minute,
+	})
+}
+
+func configureHystrix(name string) {
+	hystrix.ConfigureCommand(name, hystrix.CommandConfig{
+		Timeout:               int(time.Minute / time.Millisecond),
+		MaxConcurrentRequests: 100000,
+		SleepWindow:           int(time.Minute / time.Millisecond),
+	})
+}
+
+func BenchmarkEasyBreaker_Success(b *testing.B) {
+	cb := newEasyBreaker(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cb.Execute(success)
+	}
+}
+
+func BenchmarkGoBreaker_Success(b *testing.B) {
+	cb := newGoBreaker()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cb.Execute(func() (interface{}, error) { return nil, success() })
+	}
+}
+
+func BenchmarkHystrix_Success(b *testing.B) {
+	configureHystrix("bench-success")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hystrix.Do("bench-success", success, nil)
+	}
+}
+
+func BenchmarkEasyBreaker_SuccessParallel(b *testing.B) {
+	cb := newEasyBreaker(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			cb.Execute(success)
+		}
+	})
+}
+
+func BenchmarkGoBreaker_SuccessParallel(b *testing.B) {
+	cb := newGoBreaker()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			cb.Execute(func() (interface{}, error) { return nil, success() })
+		}
+	})
+}
+
+func BenchmarkHystrix_SuccessParallel(b *testing.B) {
+	configureHystrix("bench-parallel")
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			hystrix.Do("bench-parallel", success, nil)
+		}
+	})
+}
+
+func BenchmarkEasyBreaker_Open(b *testing.B) {
+	cb, err := easybreaker.New(
+		time.Minute, time.Hour,
+		easybreaker.WithStateFunc(
+			func(uint32, uint32) bool { return true },
+			func(uint32, uint32) bool { return false },
+		),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cb.Execute(func() error { return errFailed })
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cb.Execute(success)
+	}
+}
+
+func BenchmarkGoBreaker_Open(b *testing.B) {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "bench-open",
+		Timeout:     time.Hour,
+		ReadyToTrip: func(gobreaker.Counts) bool { return true },
+	})
+	cb.Execute(func() (interface{}, error) { return nil, errFailed })
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cb.Execute(func() (interface{}, error) { return nil, success() })
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/rfyiamcool/easybreaker/benchmarks
+
+go 1.20
+
+require (
+	github.com/afex/hystrix-go v0.0.0-20180502004556-fa1af6a1f4f5
+	github.com/rfyiamcool/easybreaker v0.0.0
+	github.com/sony/gobreaker v0.5.0
+	github.com/stretchr/testify v1.4.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
+
+replace github.com/rfyiamcool/easybreaker => ../
@@ -0,0 +1,107 @@
+package benchmarks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func always(uint32, uint32) bool { return true }
+
+// gobreaker opens again on the first failure in the half-open state,
+// easybreaker admits atLeastReqs requests and then asks toClosed.
+func TestSemantics_HalfOpenFailure(t *testing.T) {
+	gb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		MaxRequests: 3,
+		Timeout:     10 * time.Millisecond,
+		ReadyToTrip: func(gobreaker.Counts) bool { return true },
+	})
+	gb.Execute(func() (interface{}, error) { return nil, errFailed })
+	assert.Equal(t, gobreaker.StateOpen, gb.State())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, gobreaker.StateHalfOpen, gb.State())
+	gb.Execute(func() (interface{}, error) { return nil, errFailed })
+	assert.Equal(t, gobreaker.StateOpen, gb.State())
+
+	eb, err := easybreaker.New(
+		time.Minute, 10*time.Millisecond,
+		easybreaker.WithLeastReqs(3),
+		easybreaker.WithStateFunc(always, func(total uint32, failures uint32) bool { return failures == 0 }),
+	)
+	assert.NoError(t, err)
+	eb.Execute(func() error { return errFailed })
+	assert.Equal(t, easybreaker.StateOpen, eb.State())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, errFailed, eb.Execute(func() error { return errFailed }))
+	assert.Equal(t, easybreaker.StateHalfOpen, eb.State())
+	assert.NoError(t, eb.Execute(success))
+	assert.NoError(t, eb.Execute(success))
+	assert.Equal(t, easybreaker.StateHalfOpen, eb.State())
+
+	// atLeastReqs reached, toClosed rejects the failure seen in the half-open state
+	assert.Equal(t, easybreaker.ErrBreakerOpen, eb.Execute(success))
+	assert.Equal(t, easybreaker.StateOpen, eb.State())
+}
+
+// hystrix-go doesn't trip before RequestVolumeThreshold requests are seen
+// in its 10 seconds rolling window, easybreaker leaves it to toOpen.
+func TestSemantics_VolumeThreshold(t *testing.T) {
+	hystrix.ConfigureCommand("semantics-volume", hystrix.CommandConfig{
+		Timeout:                1000,
+		RequestVolumeThreshold: 10,
+		ErrorPercentThreshold:  50,
+		SleepWindow:            60000,
+	})
+	for i := 0; i < 5; i++ {
+		hystrix.Do("semantics-volume", func() error { return errFailed }, nil)
+	}
+	time.Sleep(50 * time.Millisecond) // hystrix collects the metrics asynchronously
+
+	cb, _, err := hystrix.GetCircuit("semantics-volume")
+	assert.NoError(t, err)
+	assert.False(t, cb.IsOpen())
+
+	eb, err := easybreaker.New(time.Minute, time.Minute)
+	assert.NoError(t, err)
+	eb.Execute(func() error { return errFailed })
+	assert.Equal(t, easybreaker.StateOpen, eb.State())
+}
+
+// gobreaker never clears the counts of the closed state when Interval is 0,
+// easybreaker requires an interval.
+func TestSemantics_Interval(t *testing.T) {
+	gb := gobreaker.NewCircuitBreaker(gobreaker.Settings{})
+	assert.Equal(t, gobreaker.StateClosed, gb.State())
+
+	_, err := easybreaker.New(0, time.Minute)
+	assert.Error(t, err)
+}
+
+// both report a rejected request with a sentinel error
+// and don't execute the request.
+func TestSemantics_Rejection(t *testing.T) {
+	gb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Timeout:     time.Minute,
+		ReadyToTrip: func(gobreaker.Counts) bool { return true },
+	})
+	gb.Execute(func() (interface{}, error) { return nil, errFailed })
+
+	executed := false
+	_, err := gb.Execute(func() (interface{}, error) { executed = true; return nil, nil })
+	assert.Equal(t, gobreaker.ErrOpenState, err)
+	assert.False(t, executed)
+
+	eb, err := easybreaker.New(time.Minute, time.Minute, easybreaker.WithStateFunc(always, always))
+	assert.NoError(t, err)
+	eb.Execute(func() error { return errFailed })
+
+	err = eb.Execute(func() error { executed = true; return nil })
+	assert.Equal(t, easybreaker.ErrBreakerOpen, err)
+	assert.False(t, executed)
+}
@@ -0,0 +1,141 @@
+// Package breakertest checks a circuit breaker configuration against
+// randomized schedules of request outcomes and timing.
+//
+// It's meant to be used from the tests of the applications defining
+// their own ToState functions:
+//
+//	func TestBreaker(t *testing.T) {
+//		breakertest.Check(t, breakertest.Config{
+//			New: func(now func() time.Time) (*easybreaker.Breaker, error) {
+//				return easybreaker.New(
+//					time.Minute, 10*time.Second,
+//					easybreaker.WithNow(now),
+//					easybreaker.WithStateFunc(toOpen, toClosed),
+//				)
+//			},
+//			ToOpen: toOpen,
+//		})
+//	}
+package breakertest
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+const (
+	defaultSteps        = 10000
+	defaultRecoverSteps = 100000
+	defaultFailureRate  = 0.5
+	defaultMaxAdvance   = time.Second
+)
+
+var errFailed = errors.New("breakertest: failed")
+
+type Config struct {
+	// New creates the circuit breaker under test,
+	// now must be passed to the breaker with easybreaker.WithNow.
+	New func(now func() time.Time) (*easybreaker.Breaker, error)
+
+	// ToOpen is the trip condition of the breaker, the breaker must not
+	// stay closed after a failed request once it holds. Optional.
+	ToOpen easybreaker.ToState
+
+	Seed         int64         // seed of the schedule, time based by default
+	Steps        int           // the number of random requests
+	RecoverSteps int           // the number of healthy requests the breaker must close within
+	FailureRate  float64       // the upper bound of the failure rate, changes randomly every interval of the schedule
+	MaxAdvance   time.Duration // the upper bound of the clock advance between the requests
+}
+
+// Check runs the random schedule against the breaker created by cfg.New
+// and reports the first violated invariant along with the seed and the step
+// to reproduce it:
+//
+//   - a closed breaker accepts requests;
+//   - a rejected request is not executed;
+//   - a closed breaker trips once ToOpen holds after a failure;
+//   - the breaker closes again once the requests succeed.
+func Check(t testing.TB, cfg Config) {
+	t.Helper()
+
+	if cfg.New == nil {
+		t.Fatalf("breakertest: New must be defined")
+		return
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	if cfg.Steps == 0 {
+		cfg.Steps = defaultSteps
+	}
+	if cfg.RecoverSteps == 0 {
+		cfg.RecoverSteps = defaultRecoverSteps
+	}
+	if cfg.FailureRate == 0 {
+		cfg.FailureRate = defaultFailureRate
+	}
+	if cfg.MaxAdvance == 0 {
+		cfg.MaxAdvance = defaultMaxAdvance
+	}
+
+	clock := NewClock(time.Unix(0, 0))
+
+	b, err := cfg.New(clock.Now)
+	if err != nil {
+		t.Fatalf("breakertest: seed %d: %v", cfg.Seed, err)
+		return
+	}
+
+	rnd := rand.New(rand.NewSource(cfg.Seed))
+	rate := rnd.Float64() * cfg.FailureRate
+
+	for step := 0; step < cfg.Steps; step++ {
+		clock.Advance(time.Duration(rnd.Int63n(int64(cfg.MaxAdvance) + 1)))
+		if rnd.Intn(100) == 0 {
+			rate = rnd.Float64() * cfg.FailureRate
+		}
+
+		fail := rnd.Float64() < rate
+		state := b.State()
+		executed := false
+
+		err := b.Execute(func() error {
+			executed = true
+			if fail {
+				return errFailed
+			}
+			return nil
+		})
+
+		if errors.Is(err, easybreaker.ErrBreakerOpen) && executed {
+			t.Fatalf("breakertest: seed %d step %d: rejected request was executed, %v", cfg.Seed, step, b)
+			return
+		}
+		if state == easybreaker.StateClosed && !executed {
+			t.Fatalf("breakertest: seed %d step %d: closed breaker rejected the request, %v", cfg.Seed, step, b)
+			return
+		}
+		if cfg.ToOpen != nil && executed && fail && state == easybreaker.StateClosed && b.State() == easybreaker.StateClosed {
+			counts := b.Counts()
+			if cfg.ToOpen(counts.Total, counts.Failures) {
+				t.Fatalf("breakertest: seed %d step %d: closed breaker did not trip, %v", cfg.Seed, step, b)
+				return
+			}
+		}
+	}
+
+	for step := 0; step < cfg.RecoverSteps; step++ {
+		clock.Advance(time.Duration(rnd.Int63n(int64(cfg.MaxAdvance) + 1)))
+		b.Execute(func() error { return nil })
+		if b.State() == easybreaker.StateClosed {
+			return
+		}
+	}
+
+	t.Fatalf("breakertest: seed %d: breaker did not recover within %d requests, %v", cfg.Seed, cfg.RecoverSteps, b)
+}
@@ -0,0 +1,74 @@
+package breakertest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	testing.TB
+	failure string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failure = fmt.Sprintf(format, args...)
+}
+
+func toOpen(total uint32, failures uint32) bool {
+	return total > 0 && float64(failures)/float64(total) >= 0.05
+}
+
+func TestCheck(t *testing.T) {
+	Check(t, Config{
+		New: func(now func() time.Time) (*easybreaker.Breaker, error) {
+			return easybreaker.New(
+				time.Minute, 10*time.Second,
+				easybreaker.WithNow(now),
+				easybreaker.WithLeastReqs(10),
+			)
+		},
+		ToOpen: toOpen,
+		Seed:   1520100000,
+	})
+}
+
+func TestCheck_NotRecoverable(t *testing.T) {
+	r := &recorder{TB: t}
+	Check(r, Config{
+		New: func(now func() time.Time) (*easybreaker.Breaker, error) {
+			return easybreaker.New(
+				time.Minute, 10*time.Second,
+				easybreaker.WithNow(now),
+				easybreaker.WithLeastReqs(10),
+				easybreaker.WithStateFunc(toOpen, func(uint32, uint32) bool { return false }),
+			)
+		},
+		Seed: 1520100000,
+	})
+	assert.Contains(t, r.failure, "breaker did not recover")
+}
+
+func TestCheck_NotTripped(t *testing.T) {
+	r := &recorder{TB: t}
+	Check(r, Config{
+		New: func(now func() time.Time) (*easybreaker.Breaker, error) {
+			return easybreaker.New(
+				time.Minute, 10*time.Second,
+				easybreaker.WithNow(now),
+				easybreaker.WithStateFunc(
+					func(uint32, uint32) bool { return false },
+					func(uint32, uint32) bool { return true },
+				),
+			)
+		},
+		ToOpen: toOpen,
+		Seed:   1520100000,
+	})
+	assert.Contains(t, r.failure, "closed breaker did not trip")
+}
@@ -0,0 +1,50 @@
+package breakertest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock_Timers(t *testing.T) {
+	c := NewClock(time.Unix(1520100000, 0))
+
+	t1 := c.NewTimer(time.Minute)
+	t2 := c.NewTimer(30 * time.Second)
+	t3 := c.NewTimer(time.Hour)
+
+	c.Advance(45 * time.Second)
+	assert.Equal(t, time.Unix(1520100045, 0), c.Now())
+	assert.Equal(t, time.Unix(1520100030, 0), <-t2.C())
+	assert.Len(t, t1.C(), 0)
+
+	assert.True(t, t3.Stop())
+	assert.False(t, t2.Stop())
+
+	c.Advance(time.Hour)
+	assert.Equal(t, time.Unix(1520100060, 0), <-t1.C())
+	assert.Len(t, t3.C(), 0)
+}
+
+func TestClock_Breakers(t *testing.T) {
+	c := NewClock(time.Unix(1520100000, 0))
+
+	breakers := make([]*easybreaker.Breaker, 1000)
+	for i := range breakers {
+		b, err := easybreaker.New(time.Minute, 10*time.Second, easybreaker.WithClock(c), easybreaker.WithLeastReqs(1))
+		assert.NoError(t, err)
+		b.Execute(func() error { return errors.New("failed") })
+		breakers[i] = b
+	}
+
+	c.Advance(11 * time.Second)
+	for _, b := range breakers {
+		assert.Equal(t, easybreaker.StateOpen, b.State())
+		assert.NoError(t, b.Execute(func() error { return nil }))
+		assert.NoError(t, b.Execute(func() error { return nil }))
+		assert.Equal(t, easybreaker.StateClosed, b.State())
+	}
+}
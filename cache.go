@@ -0,0 +1,160 @@
+package easybreaker
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultCacheCapacity is the number of the keys kept by a Cache
+// unless set by WithCacheCapacity.
+const DefaultCacheCapacity = 1024
+
+// Cached is a value returned by Cache.
+type Cached[V any] struct {
+	Value    V
+	Stale    bool      // the value comes from the cache, the breaker is open
+	StoredAt time.Time // when the value was returned by the request
+}
+
+// Age returns how long ago the value was returned by the request.
+func (c Cached[V]) Age(now time.Time) time.Duration {
+	return now.Sub(c.StoredAt)
+}
+
+// CacheOption sets up NewCache.
+type CacheOption func(*cacheSettings)
+
+type cacheSettings struct {
+	capacity int
+}
+
+// CacheCapacity bounds the number of the keys of the cache, the least
+// recently stored ones are evicted, DefaultCacheCapacity by default.
+// A non-positive capacity keeps the default.
+func WithCacheCapacity(n int) CacheOption {
+	return func(s *cacheSettings) {
+		if n > 0 {
+			s.capacity = n
+		}
+	}
+}
+
+// Cache keeps the last successful value of the requests by key
+// and serves it while the breaker is open. The cache of a single
+// value uses struct{} as the key.
+type Cache[K comparable, V any] struct {
+	b        *Breaker
+	ttl      time.Duration
+	capacity int
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	lru     *list.List // the entries, the most recently stored first
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key    K
+	cached Cached[V]
+}
+
+// NewCache returns the cache of the breaker, the values older than ttl
+// are not served, ttl 0 means the values never expire. The expired values
+// are removed as the values are stored.
+func NewCache[K comparable, V any](b *Breaker, ttl time.Duration, opts ...CacheOption) *Cache[K, V] {
+	s := cacheSettings{capacity: DefaultCacheCapacity}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return &Cache[K, V]{
+		b:        b,
+		ttl:      ttl,
+		capacity: s.capacity,
+		entries:  make(map[K]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Do runs fn with the breaker and stores its value for the key. When the
+// breaker is open, the stored value is returned with Stale set, ErrBreakerOpen
+// is returned if there is no value or it has expired.
+func (c *Cache[K, V]) Do(key K, fn func() (V, error)) (Cached[V], error) {
+	var value V
+	err := c.b.Execute(func() error {
+		var err error
+		value, err = fn()
+		return err
+	})
+
+	if err == nil {
+		cached := Cached[V]{Value: value, StoredAt: c.b.now()}
+		c.store(key, cached)
+		return cached, nil
+	}
+
+	if !errors.Is(err, ErrBreakerOpen) {
+		return Cached[V]{}, err
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return Cached[V]{}, err
+	}
+	cached := e.Value.(*cacheEntry[K, V]).cached
+	if c.expired(cached, c.b.now()) {
+		c.evict(e)
+		c.mu.Unlock()
+		return Cached[V]{}, err
+	}
+	c.mu.Unlock()
+
+	cached.Stale = true
+	return cached, nil
+}
+
+// Forget removes the value of the key.
+func (c *Cache[K, V]) Forget(key K) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.evict(e)
+	}
+	c.mu.Unlock()
+}
+
+// Len returns the number of the keys with a value, expired or not.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// store stores the value of the key, then removes the expired values,
+// the least recently stored first, and the ones above the capacity.
+func (c *Cache[K, V]) store(key K, cached Cached[V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.evict(e)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry[K, V]{key: key, cached: cached})
+	for e := c.lru.Back(); e != nil; e = c.lru.Back() {
+		if c.lru.Len() <= c.capacity && !c.expired(e.Value.(*cacheEntry[K, V]).cached, cached.StoredAt) {
+			break
+		}
+		c.evict(e)
+	}
+}
+
+func (c *Cache[K, V]) expired(cached Cached[V], now time.Time) bool {
+	return c.ttl > 0 && cached.Age(now) > c.ttl
+}
+
+// evict removes an entry, the caller must hold mu.
+func (c *Cache[K, V]) evict(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*cacheEntry[K, V]).key)
+}
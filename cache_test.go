@@ -0,0 +1,102 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Do(t *testing.T) {
+	b, err := New(
+		time.Minute, 10*time.Minute,
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	c := NewCache[string, int](b, time.Minute)
+	cached, err := c.Do("a", func() (int, error) { return 1, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, Cached[int]{Value: 1, StoredAt: time.Unix(1520100000, 0)}, cached)
+
+	// the failure is returned while the breaker is closed
+	failed := errors.New("failed")
+	_, err = c.Do("a", func() (int, error) { return 0, failed })
+	assert.Equal(t, failed, err)
+	assert.Equal(t, StateOpen, b.State())
+
+	b.now = now(1520100030)
+	cached, err = c.Do("a", func() (int, error) { return 2, nil })
+	assert.NoError(t, err)
+	assert.True(t, cached.Stale)
+	assert.Equal(t, 1, cached.Value)
+	assert.Equal(t, 30*time.Second, cached.Age(b.now()))
+
+	_, err = c.Do("b", func() (int, error) { return 2, nil })
+	assert.Equal(t, ErrBreakerOpen, err)
+
+	// expired
+	b.now = now(1520100061)
+	_, err = c.Do("a", func() (int, error) { return 2, nil })
+	assert.Equal(t, ErrBreakerOpen, err)
+}
+
+func TestCache_Capacity(t *testing.T) {
+	b, err := New(time.Minute, 10*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+
+	c := NewCache[int, int](b, time.Minute, WithCacheCapacity(2))
+	for i := 0; i < 3; i++ {
+		c.Do(i, func() (int, error) { return i, nil })
+	}
+	// the least recently stored key is evicted
+	assert.Equal(t, 2, c.Len())
+	b.ForceOpen()
+	_, err = c.Do(0, func() (int, error) { return 0, nil })
+	assert.Equal(t, ErrBreakerOpen, err)
+	cached, err := c.Do(2, func() (int, error) { return 0, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cached.Value)
+	b.Release()
+
+	// the expired values are removed as the values are stored
+	b.now = now(1520100061)
+	c.Do(3, func() (int, error) { return 3, nil })
+	assert.Equal(t, 1, c.Len())
+
+	c = NewCache[int, int](b, 0, WithCacheCapacity(0))
+	assert.Equal(t, DefaultCacheCapacity, c.capacity)
+}
+
+func TestCache_OpenError(t *testing.T) {
+	b, err := New(
+		time.Minute, 10*time.Minute,
+		WithName("api"),
+		WithOpenError(),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	c := NewCache[string, int](b, time.Minute)
+	_, err = c.Do("a", func() (int, error) { return 1, nil })
+	assert.NoError(t, err)
+	c.Do("a", func() (int, error) { return 0, errors.New("failed") })
+
+	cached, err := c.Do("a", func() (int, error) { return 2, nil })
+	assert.NoError(t, err)
+	assert.True(t, cached.Stale)
+	assert.Equal(t, 1, cached.Value)
+
+	_, err = c.Do("b", func() (int, error) { return 2, nil })
+	var oe *OpenError
+	assert.True(t, errors.As(err, &oe))
+}
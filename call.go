@@ -0,0 +1,51 @@
+package easybreaker
+
+import (
+	"context"
+	"time"
+)
+
+// CallOption overrides the settings of the breaker for a single call
+// of Execute, so the slightly different call profiles of a dependency
+// share a breaker.
+type CallOption func(*call)
+
+type call struct {
+	timeout time.Duration
+	weight  uint32
+}
+
+// CallTimeout abandons the request after timeout, Execute returns
+// context.DeadlineExceeded, counted as a timeout, while the request
+// keeps running in its goroutine. It uses the clock of the breaker.
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(c *call) {
+		c.timeout = timeout
+	}
+}
+
+// Weight counts the request as cost ordinary requests, like ExecuteWeighted.
+func WithWeight(cost uint32) CallOption {
+	return func(c *call) {
+		c.weight = cost
+	}
+}
+
+// withTimeout returns req abandoned after timeout.
+func (b *Breaker) withTimeout(req func() error, timeout time.Duration) func() error {
+	return func() error {
+		done := make(chan error, 1)
+		go func() {
+			done <- req()
+		}()
+
+		t := b.clock.NewTimer(timeout)
+		defer t.Stop()
+		select {
+		case err := <-done:
+			return err
+		case <-t.C():
+			return context.DeadlineExceeded
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_ExecuteCallOptions(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithStateFunc(
+		func(total, failures uint32) bool { return false },
+		defaultToClosed,
+	))
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Execute(func() error { return nil }, WithWeight(3)))
+	assert.Equal(t, Counts{Total: 3, Successes: 3}, b.Counts())
+
+	// the request is abandoned
+	release := make(chan struct{})
+	defer close(release)
+	err = b.Execute(func() error {
+		<-release
+		return nil
+	}, WithCallTimeout(time.Millisecond), WithWeight(2))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, Counts{Total: 5, Failures: 2, Successes: 3, Timeouts: 2}, b.Counts())
+
+	failed := errors.New("failed")
+	assert.Equal(t, failed, b.Execute(func() error { return failed }, WithCallTimeout(time.Minute)))
+	assert.Equal(t, Counts{Total: 6, Failures: 3, Successes: 3, Timeouts: 2}, b.Counts())
+}
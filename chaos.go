@@ -0,0 +1,85 @@
+package easybreaker
+
+import (
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// ErrChaos is the synthetic failure injected by WithChaos.
+var ErrChaos = errors.New("circuit: chaos failure")
+
+type chaos struct {
+	enabled     int32
+	failureRate float64
+	openRate    float64
+	openFor     int64
+	until       int64 // the end of the forced open period
+}
+
+// Chaos injects the faults of a resilience test, e.g. in staging, so the
+// fallbacks are verified before a real outage. The requests of the Execute
+// family fail with ErrChaos without being run at failureRate, they are
+// counted as failures. The admissions force the breaker open for openFor at
+// openRate, it rejects like ForceOpen meanwhile. The rates are in [0, 1].
+// SetChaos turns the faults off and on at runtime.
+func WithChaos(failureRate, openRate float64, openFor time.Duration) OptionCall {
+	return func(b *Breaker) error {
+		if failureRate < 0 || failureRate > 1 || openRate < 0 || openRate > 1 {
+			return errors.New("circuit: chaos rates must be in [0, 1]")
+		}
+		if failureRate == 0 && openRate == 0 {
+			return errors.New("circuit: chaos must inject a fault")
+		}
+		if openRate > 0 && openFor <= 0 {
+			return errors.New("circuit: chaos open period must be positive")
+		}
+		b.chaos = &chaos{
+			enabled:     1,
+			failureRate: failureRate,
+			openRate:    openRate,
+			openFor:     openFor.Nanoseconds(),
+		}
+		return nil
+	}
+}
+
+// SetChaos turns the faults of WithChaos on or off,
+// it ends the forced open period of the chaos if any.
+func (b *Breaker) SetChaos(enabled bool) {
+	if b.chaos == nil {
+		return
+	}
+	if enabled {
+		atomic.StoreInt32(&b.chaos.enabled, 1)
+		return
+	}
+	atomic.StoreInt32(&b.chaos.enabled, 0)
+	atomic.StoreInt64(&b.chaos.until, 0)
+}
+
+// chaosOpen reports whether the chaos forces the breaker open.
+func (b *Breaker) chaosOpen() bool {
+	return b.chaos != nil && b.now().UnixNano() < atomic.LoadInt64(&b.chaos.until)
+}
+
+// chaosAdmit forces the breaker open at the open rate on an admission.
+func (b *Breaker) chaosAdmit() {
+	c := b.chaos
+	if c == nil || c.openRate == 0 || atomic.LoadInt32(&c.enabled) == 0 {
+		return
+	}
+	now := b.now().UnixNano()
+	until := atomic.LoadInt64(&c.until)
+	if now < until || rand.Float64() >= c.openRate {
+		return
+	}
+	atomic.CompareAndSwapInt64(&c.until, until, now+c.openFor)
+}
+
+// chaosFailed reports whether the request fails with ErrChaos instead of being run.
+func (b *Breaker) chaosFailed() bool {
+	c := b.chaos
+	return c != nil && c.failureRate > 0 && atomic.LoadInt32(&c.enabled) == 1 && rand.Float64() < c.failureRate
+}
@@ -0,0 +1,55 @@
+package easybreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_Chaos(t *testing.T) {
+	ts := time.Unix(1520100000, 0)
+	b, err := New(
+		time.Minute, time.Minute,
+		WithFailureThreshold(1),
+		WithChaos(1, 0, 0),
+		WithNow(func() time.Time { return ts }),
+	)
+	assert.NoError(t, err)
+
+	// the request is not run and counted as failed
+	ran := false
+	assert.Equal(t, ErrChaos, b.Execute(func() error {
+		ran = true
+		return nil
+	}))
+	assert.False(t, ran)
+	assert.Equal(t, StateOpen, b.State())
+
+	b, err = New(
+		time.Minute, time.Minute,
+		WithChaos(0, 1, 10*time.Second),
+		WithNow(func() time.Time { return ts }),
+	)
+	assert.NoError(t, err)
+
+	// the admission forces the breaker open for the period
+	assert.Equal(t, ErrBreakerOpen, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateOpen, b.State())
+	ts = ts.Add(10 * time.Second)
+	assert.Equal(t, StateClosed, b.State())
+
+	b.SetChaos(false)
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, Counts{Total: 1, Successes: 1}, b.Counts())
+
+	for _, fn := range []OptionCall{
+		WithChaos(0, 0, time.Second),
+		WithChaos(1.5, 0, time.Second),
+		WithChaos(0, 0.5, 0),
+	} {
+		_, err = New(time.Minute, time.Minute, fn)
+		assert.Error(t, err)
+	}
+}
@@ -1,7 +1,10 @@
 package easybreaker
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -9,7 +12,7 @@ import (
 type ToState func(uint32, uint32) bool
 
 func defaultToOpen(total uint32, failures uint32) bool {
-	return total > 0 && float64(failures)/float64(total) >= 0.05
+	return total > 0 && float64(failures)/float64(total) >= defaultFailureThreshold
 }
 
 func defaultToClosed(total uint32, failures uint32) bool {
@@ -21,14 +24,25 @@ const (
 	halfOpen = int32(1) // a limited number of requests are allowed to pass
 	open     = int32(2) // the request is failed immediately and ErrBreakerOpen returned
 
-	defaultAtLeastReq = 100
+	defaultAtLeastReq       = 100
+	defaultFailureThreshold = 0.05
 )
 
-var ErrBreakerOpen = errors.New("circuit: breaker open")
+var (
+	ErrBreakerOpen = errors.New("circuit: breaker open")
+
+	// ErrDeadlineTooShort is returned by ExecuteCtx when the context
+	// expires sooner than the minimum remaining deadline.
+	ErrDeadlineTooShort = errors.New("circuit: deadline too short")
+)
 
 type Breaker struct {
-	state int32 // current state
-	until int64 // until timestamp of the interval (in closed state) or cooldown (in open state) period
+	name    string
+	impacts []string // the features protected by the breaker
+	tagsMu  sync.Mutex
+	tags    atomic.Value // map[string]string, replaced on SetTag and DeleteTag
+
+	*shared // the state, the counts and the timers, see WithSharedMemory
 
 	interval    int64 // the cyclic period of the closed state
 	cooldown    int64 // the period of the open state
@@ -36,11 +50,78 @@ type Breaker struct {
 
 	toOpenState   ToState // called on failure being in the closed state
 	toClosedState ToState // called after atLeastReqs being in the half-open state
-
-	total    uint32 // requests in total during the interval
-	failures uint32 // requests returned an error during the interval
-
-	now func() time.Time
+	stateFuncs    bool    // the state functions are set by WithStateFunc
+	threshold     float64 // the failure ratio of WithFailureThreshold, 0 if unset
+	maxFailures   uint32  // failures of the interval opening the breaker whatever toOpenState says
+	timeoutToOpen func(Counts) bool
+	taxonomy      Taxonomy
+	errorFilter   func(error) bool
+	breakdown     *sync.Map   // the failures by Category, *uint64
+	acc           recorder    // the Accumulator of WithAccumulator
+	isProbe       func() bool // selects the probes of the half-open state
+	probeRate     *probeRate  // the percentage of WithHalfOpenPercent
+	guard         func(from, to State, counts Counts) bool
+	passNonProbes bool
+	velocity      *velocity
+	spike         *spike
+	grace         *grace
+	slowCall      *slowCall
+	pressure      *pressure // the warnings of WithWarningThreshold
+	sliding       window    // the outcomes of WithSlidingWindow or WithCountWindow
+
+	inFlight   uint32           // requests accepted and not finished yet
+	lastWindow atomic.Value     // the last finished Window
+	summary    *intervalSummary // the callback of WithIntervalSummary
+
+	maintenance Schedule // the automatic overrides
+	audit       audit    // the manual control actions
+
+	resetPolicy    ResetPolicy
+	seedFromProbes bool // the successful probes are counted in the first closed interval
+
+	minRemainingDeadline time.Duration
+	ignoreDeadlines      bool // the expired deadlines of ExecuteCtx are not failures
+
+	latency   *histogram // the latencies of the sampled requests of Execute
+	deadlines *deadlines // the budgets and the spends of the requests of ExecuteCtx
+	hang      *hang      // the requests of Execute in flight and their age
+	sampling  uint32     // 1 of sampling requests is measured
+	sampled   uint32
+
+	load       int64          // the last load observed by ObserveLoad
+	loadToOpen func(int) bool // called on the observed load being in the closed state
+
+	cancelOnTrip bool
+	cancelMu     sync.Mutex
+	cancelSeq    uint64
+	cancels      map[uint64]context.CancelFunc // the contexts of ExecuteCtx to cancel on trip
+
+	drainBeforeProbe bool
+	drainWaiting     int32 // the number of WaitDrained callers
+	drainMu          sync.Mutex
+	drainWaiters     []chan struct{}
+
+	clock Clock
+	now   func() time.Time // clock.Now
+
+	chaos    *chaos  // the faults of WithChaos
+	strict   bool    // the defaults are refused, see WithStrict
+	explicit setting // the settings set by the options, see WithStrict
+
+	startup *startupProbe // the probe of WithStartupProbe, run by New
+
+	onReject    func(Rejection)
+	onChange    func(from, to State)
+	openError   bool               // the rejections return *OpenError
+	openClasses map[string]bool    // the operation classes admitted while open
+	budgets     map[string]*budget // the failure budgets of the operation classes
+	tripReason  atomic.Value       // the last TripReason
+
+	sinkMu      sync.Mutex
+	sinkSeq     uint64
+	sinks       atomic.Value // []sink, replaced on Subscribe
+	minSeverity int32        // the lowest Severity subscribed by the sinks, checked before building the events
+	prolonged   int64        // the nanoseconds of WithProlongedOpen, 0 if disabled
 }
 
 type OptionCall func(*Breaker) error
@@ -50,13 +131,66 @@ type OptionCall func(*Breaker) error
 func WithLeastReqs(atLeastReqs uint32) OptionCall {
 	return func(b *Breaker) error {
 		if atLeastReqs == 0 {
-			return errors.New("circuit: interval must be set")
+			return errors.New("circuit: atLeastReqs must be positive")
 		}
 		b.atLeastReqs = atLeastReqs
 		return nil
 	}
 }
 
+// Name is used to identify the circuit breaker in logs.
+func WithName(name string) OptionCall {
+	return func(b *Breaker) error {
+		b.name = name
+		if name != "" {
+			b.set(settingName)
+		}
+		return nil
+	}
+}
+
+// Now is the time source of the circuit breaker, time.Now by default.
+// It lets tests and simulations drive the breaker deterministically,
+// the timers of a custom time source are set with WithClock.
+func WithNow(now func() time.Time) OptionCall {
+	return func(b *Breaker) error {
+		if now == nil {
+			return errors.New("circuit: now must be defined")
+		}
+		b.clock = nowClock(now)
+		b.now = now
+		return nil
+	}
+}
+
+// MaxFailures opens the breaker once more than n requests failed during the
+// interval, whatever the ratio, for the high volume dependencies where even
+// a small ratio means a flood of errors.
+func WithMaxFailures(n uint32) OptionCall {
+	return func(b *Breaker) error {
+		if n == 0 {
+			return errors.New("circuit: max failures must be positive")
+		}
+		b.maxFailures = n
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
+// FailureThreshold is the failure ratio of the interval opening the breaker,
+// in (0, 1], 5% by default. It's the default toOpen function, New fails if
+// WithStateFunc or WithAccumulator is set too.
+func WithFailureThreshold(ratio float64) OptionCall {
+	return func(b *Breaker) error {
+		if !(ratio > 0 && ratio <= 1) {
+			return errors.New("circuit: failure threshold must be in (0, 1]")
+		}
+		b.threshold = ratio
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
 // ToOpen is called whenever a request fails in the closed state.
 // If it returns true, the circuit breaker will be placed into the open state.
 //
@@ -73,14 +207,8 @@ func WithStateFunc(toOpen, toClosed ToState) OptionCall {
 		}
 		b.toOpenState = toOpen
 		b.toClosedState = toClosed
-		return nil
-	}
-}
-
-// for test
-func withTime(ts int64) OptionCall {
-	return func(b *Breaker) error {
-		b.now = now(ts)
+		b.stateFuncs = true
+		b.set(settingStrategy)
 		return nil
 	}
 }
@@ -89,32 +217,57 @@ func withTime(ts int64) OptionCall {
 //
 // Cooldown is the period of the open state,
 // after which the state of the circuit breaker becomes the half-open.
+//
+// All the invalid settings are reported at once, joined with errors.Join.
 func New(interval time.Duration, cooldown time.Duration, fns ...OptionCall) (*Breaker, error) {
+	var errs []error
 	if interval.Nanoseconds() == 0 {
-		return nil, errors.New("circuit: interval must be set")
+		errs = append(errs, errors.New("circuit: interval must be set"))
 	}
 	if cooldown.Nanoseconds() == 0 {
-		return nil, errors.New("circuit: cooldown must be set")
+		errs = append(errs, errors.New("circuit: cooldown must be set"))
 	}
 
 	b := &Breaker{
-		interval: interval.Nanoseconds(),
-		cooldown: cooldown.Nanoseconds(),
-		state:    closed,
-		now:      time.Now,
+		shared:      &shared{},
+		interval:    interval.Nanoseconds(),
+		cooldown:    cooldown.Nanoseconds(),
+		clock:       SystemClock,
+		now:         time.Now,
+		minSeverity: int32(severityNone),
+		resetPolicy: DefaultResetPolicy,
 	}
 
-	var err error
 	for _, fn := range fns {
-		err = fn(b)
-		if err != nil {
-			return nil, err
+		if err := fn(b); err != nil {
+			errs = append(errs, err)
 		}
 	}
+	if b.acc != nil && b.stateFuncs {
+		errs = append(errs, errors.New("circuit: accumulator and state functions are exclusive"))
+	}
+	if b.threshold > 0 && b.stateFuncs {
+		errs = append(errs, errors.New("circuit: failure threshold and state functions are exclusive"))
+	}
+	if b.threshold > 0 && b.acc != nil {
+		errs = append(errs, errors.New("circuit: failure threshold and accumulator are exclusive"))
+	}
+	if b.strict {
+		errs = append(errs, b.unconfigured()...)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
 
 	if b.atLeastReqs == 0 {
 		b.atLeastReqs = defaultAtLeastReq
 	}
+	if b.threshold > 0 {
+		ratio := b.threshold
+		b.toOpenState = func(total uint32, failures uint32) bool {
+			return total > 0 && float64(failures)/float64(total) >= ratio
+		}
+	}
 	if b.toOpenState == nil {
 		b.toOpenState = defaultToOpen
 	}
@@ -122,28 +275,140 @@ func New(interval time.Duration, cooldown time.Duration, fns ...OptionCall) (*Br
 		b.toClosedState = defaultToClosed
 	}
 
-	b.until = b.now().UnixNano() + interval.Nanoseconds()
+	if err := b.init(); err != nil {
+		return nil, err
+	}
+	if b.hang != nil {
+		b.hang.base = b.windowStart
+	}
+	if b.startup != nil {
+		b.probeStartup()
+	}
 
 	return b, nil
 }
 
-func (b *Breaker) Execute(req func() error) error {
-	if !b.ready() {
-		return ErrBreakerOpen
+// Execute runs req unless the breaker is open, it returns ErrBreakerOpen
+// then. The options override the settings of the breaker for this call.
+func (b *Breaker) Execute(req func() error, opts ...CallOption) error {
+	if len(opts) > 0 {
+		var c call
+		for _, opt := range opts {
+			opt(&c)
+		}
+		if c.timeout > 0 {
+			req = b.withTimeout(req, c.timeout)
+		}
+		if c.weight > 1 {
+			return b.ExecuteWeighted(c.weight, req)
+		}
+	}
+
+	counted, err := b.allow(true)
+	if err != nil {
+		return err
 	}
 
-	atomic.AddUint32(&b.total, 1)
-	err := req()
+	start := b.begin()
+	start.uncounted = !counted
+	if b.chaosFailed() {
+		err = ErrChaos
+	} else {
+		err = req()
+	}
+	b.finish(start, err)
+	return err
+}
 
+// ExecuteN is Execute making up to attempts attempts of an idempotent
+// request within a single admission, until one succeeds. The request is
+// counted once by its final outcome, so the retries don't multiply
+// the failures. A non-positive attempts makes a single attempt.
+func (b *Breaker) ExecuteN(attempts int, req func(attempt int) error) error {
+	counted, err := b.allow(true)
 	if err != nil {
-		atomic.AddUint32(&b.failures, 1)
-		b.onFailure()
+		return err
+	}
+
+	start := b.begin()
+	start.uncounted = !counted
+	if b.chaosFailed() {
+		err = ErrChaos
+	} else {
+		for attempt := 0; attempt < attempts || attempt == 0; attempt++ {
+			if err = req(attempt); err == nil {
+				break
+			}
+		}
 	}
+	b.finish(start, err)
+	return err
+}
 
+// Allow is the first step of Execute for the requests which can't be wrapped
+// in a function, it returns ErrBreakerOpen when the request is not accepted.
+// An accepted request must be reported with Done once it's finished.
+func (b *Breaker) Allow() error {
+	_, err := b.allow(false)
 	return err
 }
 
+// admit counts the request if it's ready, rejects it otherwise.
+func (b *Breaker) admit(ready bool) error {
+	if !ready {
+		b.rejected()
+		if b.listening(SeverityDebug) {
+			b.emit(Event{Type: EventRejected, Severity: SeverityDebug})
+		}
+		return b.errOpen()
+	}
+
+	atomic.AddUint64(&b.counts, totalUnit)
+	atomic.AddUint32(&b.inFlight, 1)
+	if b.listening(SeverityDebug) {
+		b.emit(Event{Type: EventAdmitted, Severity: SeverityDebug})
+	}
+	return nil
+}
+
+// Done reports the result of a request accepted by Allow.
+func (b *Breaker) Done(err error) {
+	b.done(1, err)
+}
+
+// done reports the result of a request counted n times, see ExecuteWeighted.
+func (b *Breaker) done(n uint32, err error) {
+	b.release()
+	if !b.failed(err) {
+		if b.acc != nil {
+			b.acc.Record(b.now(), nil)
+		}
+		atomic.AddUint32(&b.successes, n)
+		b.slide(n, false)
+		return
+	}
+
+	category := b.classify(err)
+	if category == CategoryIgnored {
+		b.ignore(n)
+		return
+	}
+	if b.acc != nil {
+		b.acc.Record(b.now(), err)
+	}
+	if category == CategoryTimeout {
+		atomic.AddUint32(&b.timeouts, n)
+	}
+	atomic.AddUint64(&b.counts, uint64(n)*failureUnit)
+	b.slide(n, true)
+	b.onError(err, category)
+}
+
 func (b *Breaker) ready() bool {
+	if forced := b.override(); forced != forcedNone {
+		return forced == forcedClosed
+	}
+
 	until := atomic.LoadInt64(&b.until)
 	state := atomic.LoadInt32(&b.state)
 	now := b.now().UnixNano()
@@ -155,8 +420,10 @@ func (b *Breaker) ready() bool {
 
 		// interval period elapsed
 		if atomic.CompareAndSwapInt64(&b.until, until, now+b.interval) {
-			atomic.StoreUint32(&b.failures, 0)
-			atomic.StoreUint32(&b.total, 0)
+			counts := b.reset(b.resetPolicy&ResetOnRollover != 0)
+			b.grace.clear()
+			b.spike.observe(counts)
+			b.emit(Event{Type: EventRollover, Severity: SeverityInfo, From: StateClosed, To: StateClosed, Counts: counts})
 		}
 		return true
 	}
@@ -165,19 +432,22 @@ func (b *Breaker) ready() bool {
 		if now < until {
 			return false
 		}
+		if b.drainBeforeProbe && atomic.LoadUint32(&b.inFlight) > 0 {
+			return false
+		}
+		if !b.guarded(open, halfOpen) {
+			return false
+		}
 
 		if atomic.CompareAndSwapInt64(&b.until, until, now+b.interval) {
-			atomic.StoreUint32(&b.failures, 0)
-			atomic.StoreUint32(&b.total, 0)
-			atomic.StoreInt32(&b.state, halfOpen)
+			b.transit(open, halfOpen)
 			return true
 		}
 		return false
 	}
 
 	// in halfOpen state
-	total := atomic.LoadUint32(&b.total)
-	failures := atomic.LoadUint32(&b.failures)
+	total, failures := unpackCounts(atomic.LoadUint64(&b.counts))
 	atLeastReqs := atomic.LoadUint32(&b.atLeastReqs)
 
 	if total < atLeastReqs {
@@ -185,39 +455,276 @@ func (b *Breaker) ready() bool {
 	}
 
 	// try to close circuit breaker
-	if b.toClosedState(total, failures) {
-		if atomic.CompareAndSwapInt64(&b.until, until, now+b.interval) {
-			atomic.StoreUint32(&b.failures, 0)
-			atomic.StoreUint32(&b.total, 0)
-			atomic.StoreInt32(&b.state, closed)
+	if b.toClosedState(total, failures) && !b.overloaded() {
+		if b.guarded(halfOpen, closed) && atomic.CompareAndSwapInt64(&b.until, until, now+b.interval) {
+			b.transit(halfOpen, closed)
 		}
 		return true
 	}
 
 	// toCloseState failed and beyond atLeastReq, back to the open state
+	if !b.guarded(halfOpen, open) {
+		return true
+	}
 	if atomic.CompareAndSwapInt64(&b.until, until, now+b.cooldown) {
-		atomic.StoreUint32(&b.failures, 0)
-		atomic.StoreUint32(&b.total, 0)
-		atomic.StoreInt32(&b.state, open)
+		reason := TripReason{Strategy: StrategyProbes, Value: failureRatio(total, failures)}
+		if b.overloaded() {
+			reason = TripReason{Strategy: StrategyLoad, Value: float64(b.Load())}
+		}
+		b.setTripReason(reason, total, failures)
+		b.transit(halfOpen, open)
 	}
 	return false
 }
 
+// onFailure trips the breaker on a failure whose error is unknown.
 func (b *Breaker) onFailure() {
+	b.onError(nil, "")
+}
+
+func (b *Breaker) onError(err error, category Category) {
 	until := atomic.LoadInt64(&b.until)
-	if atomic.LoadInt32(&b.state) != closed {
+	if atomic.LoadInt32(&b.state) != closed || b.override() != forcedNone {
 		return
 	}
 
-	total := atomic.LoadUint32(&b.total)
-	failures := atomic.LoadUint32(&b.failures)
+	now := b.now().UnixNano()
+	total, failures := b.sample(now)
 
-	if b.toOpenState(total, failures) {
-		now := b.now().UnixNano()
+	reason, ok := b.shouldOpen(total, failures, now)
+	if !ok {
+		b.grace.clear()
+	} else if !b.grace.elapsed(now) {
+		return
+	}
+	if ok && b.guarded(closed, open) {
 		if atomic.CompareAndSwapInt64(&b.until, until, now+b.cooldown) {
-			atomic.StoreUint32(&b.failures, 0)
-			atomic.StoreUint32(&b.total, 0)
-			atomic.StoreInt32(&b.state, open)
+			reason.Err = err
+			reason.Category = category
+			b.setTripReason(reason, total, failures)
+			b.transit(closed, open)
+		}
+		return
+	}
+	b.warn(total, failures)
+}
+
+// trip opens the breaker if it's closed, it reports whether it did.
+func (b *Breaker) trip(reason TripReason) bool {
+	until := atomic.LoadInt64(&b.until)
+	if atomic.LoadInt32(&b.state) != closed || !b.guarded(closed, open) {
+		return false
+	}
+	if !atomic.CompareAndSwapInt64(&b.until, until, b.now().UnixNano()+b.cooldown) {
+		return false
+	}
+	total, failures := unpackCounts(atomic.LoadUint64(&b.counts))
+	b.setTripReason(reason, total, failures)
+	b.transit(closed, open)
+	return true
+}
+
+// shouldOpen reports whether the closed breaker must trip, and why.
+// The failure at now is recorded by the failure velocity unless an earlier strategy fires.
+func (b *Breaker) shouldOpen(total uint32, failures uint32, now int64) (TripReason, bool) {
+	if b.maxFailures > 0 && failures > b.maxFailures {
+		return TripReason{Strategy: StrategyMaxFailures, Threshold: float64(b.maxFailures), Value: float64(failures)}, true
+	}
+	if b.toOpenState(total, failures) {
+		if b.acc != nil {
+			return TripReason{Strategy: StrategyAccumulator}, true
+		}
+		return TripReason{Strategy: StrategyStateFunc, Threshold: b.threshold, Value: failureRatio(total, failures)}, true
+	}
+	if b.timeoutToOpen != nil {
+		timeouts := atomic.LoadUint32(&b.timeouts)
+		if b.timeoutToOpen(Counts{Total: total, Failures: failures, Timeouts: timeouts}) {
+			return TripReason{Strategy: StrategyTimeouts, Value: failureRatio(total, timeouts)}, true
 		}
 	}
+	if rate, ok := b.velocity.exceeded(now); ok {
+		return TripReason{Strategy: StrategyVelocity, Threshold: b.velocity.limit, Value: rate}, true
+	}
+	if rate, threshold, ok := b.spike.exceeded(total, failures); ok {
+		return TripReason{Strategy: StrategySpike, Threshold: threshold, Value: rate}, true
+	}
+	return TripReason{}, false
+}
+
+// transit moves the breaker from a state to another one,
+// the caller must own the transition by swapping until.
+func (b *Breaker) transit(from, to int32) {
+	zero := b.resetsOn(to)
+	counts := b.reset(zero)
+	counts.InFlight = atomic.LoadUint32(&b.inFlight)
+	if to == closed && zero && b.seedFromProbes {
+		atomic.AddUint64(&b.counts, packCounts(counts.Successes, 0))
+		atomic.AddUint32(&b.successes, counts.Successes)
+	}
+	atomic.StoreInt32(&b.state, to)
+	if b.acc != nil {
+		b.acc.Reset()
+	}
+	if b.sliding != nil {
+		b.sliding.reset()
+	}
+	b.grace.clear()
+	if to == halfOpen {
+		b.probeRate.reset()
+	}
+	if to == closed {
+		b.velocity.reset()
+		atomic.StoreInt64(&b.openedAt, 0)
+	}
+	if from == closed && to == open {
+		atomic.StoreInt64(&b.openedAt, b.now().UnixNano())
+		atomic.AddUint64(&b.trips, 1)
+	}
+	if to == open && b.cancelOnTrip {
+		b.cancelInFlight()
+	}
+
+	severity := SeverityInfo
+	if to == open {
+		severity = SeverityWarn
+		if from == halfOpen && b.openedFor(b.prolonged) {
+			// the probes keep failing past the threshold of WithProlongedOpen
+			severity = SeverityError
+		}
+	}
+	if b.onChange != nil {
+		b.onChange(State(from), State(to))
+	}
+	e := Event{Type: EventStateChange, Severity: severity, From: State(from), To: State(to), Counts: counts}
+	if to == open {
+		if reason, ok := b.TripReason(); ok {
+			e.Reason = &reason
+		}
+	}
+	b.emit(e)
+}
+
+// reset starts a new window, returning the counts of the finished one.
+// The counts are swapped at once, so every request is counted
+// in a single window, or carried into the new window if zero is false.
+func (b *Breaker) reset(zero bool) Counts {
+	var counts Counts
+	if zero {
+		counts = b.zero()
+	} else {
+		counts = Counts{
+			Timeouts:  atomic.LoadUint32(&b.timeouts),
+			Successes: atomic.LoadUint32(&b.successes),
+			Ignored:   atomic.LoadUint32(&b.ignored),
+			Slow:      atomic.LoadUint32(&b.slow),
+		}
+		counts.Total, counts.Failures = unpackCounts(atomic.LoadUint64(&b.counts))
+	}
+	now := b.now()
+
+	w := Window{
+		Epoch:  atomic.AddUint64(&b.epoch, 1) - 1,
+		State:  State(atomic.LoadInt32(&b.state)),
+		Start:  time.Unix(0, atomic.SwapInt64(&b.windowStart, now.UnixNano())),
+		End:    now,
+		Counts: counts,
+	}
+	b.lastWindow.Store(w)
+	if b.summary != nil {
+		b.summarize(w)
+	}
+	return w.Counts
+}
+
+// zero clears the counts of the interval and returns them.
+func (b *Breaker) zero() Counts {
+	counts := Counts{
+		Timeouts:  atomic.SwapUint32(&b.timeouts, 0),
+		Successes: atomic.SwapUint32(&b.successes, 0),
+		Ignored:   atomic.SwapUint32(&b.ignored, 0),
+		Slow:      atomic.SwapUint32(&b.slow, 0),
+	}
+	counts.Total, counts.Failures = unpackCounts(atomic.SwapUint64(&b.counts, 0))
+	for _, c := range b.budgets {
+		atomic.StoreUint64(&c.counts, 0)
+	}
+	return counts
+}
+
+// State returns the current state of the circuit breaker.
+func (b *Breaker) State() State {
+	switch b.override() {
+	case forcedOpen:
+		return StateOpen
+	case forcedClosed:
+		return StateClosed
+	}
+	return State(atomic.LoadInt32(&b.state))
+}
+
+// Counts returns the requests counted in the current interval
+// and the requests in flight.
+func (b *Breaker) Counts() Counts {
+	counts := b.current()
+	counts.Classes = b.classCounts()
+	return counts
+}
+
+// current snapshots the counts of the interval without the classes.
+func (b *Breaker) current() Counts {
+	total, failures := unpackCounts(atomic.LoadUint64(&b.counts))
+	return Counts{
+		Total:     total,
+		Failures:  failures,
+		Successes: atomic.LoadUint32(&b.successes),
+		Timeouts:  atomic.LoadUint32(&b.timeouts),
+		Ignored:   atomic.LoadUint32(&b.ignored),
+		Slow:      atomic.LoadUint32(&b.slow),
+		InFlight:  atomic.LoadUint32(&b.inFlight),
+	}
+}
+
+// Trips returns the number of times the breaker tripped from the closed state.
+func (b *Breaker) Trips() uint64 {
+	return atomic.LoadUint64(&b.trips)
+}
+
+// DegradationLevel returns the health of the dependency from 0, healthy,
+// to 1, the breaker is open. In the closed state it's the failure ratio
+// of the interval, in the half-open state it's at least 0.5, so the
+// applications can disable the optional features progressively.
+func (b *Breaker) DegradationLevel() float64 {
+	state := atomic.LoadInt32(&b.state)
+	if state == open {
+		return 1
+	}
+
+	var ratio float64
+	counts := b.Counts()
+	if counts.Total > 0 {
+		ratio = float64(counts.Failures) / float64(counts.Total)
+		if ratio > 1 {
+			ratio = 1
+		}
+	}
+
+	if state == halfOpen {
+		return 0.5 + ratio/2
+	}
+	return ratio
+}
+
+// String returns a one-line description of the circuit breaker, e.g.
+// breaker{name="api" state=closed total=10 failures=1 inflight=2 until=2018-03-03T18:01:00Z}
+func (b *Breaker) String() string {
+	counts := b.Counts()
+	return fmt.Sprintf(
+		"breaker{name=%q state=%s total=%d failures=%d inflight=%d until=%s}",
+		b.name,
+		b.State(),
+		counts.Total,
+		counts.Failures,
+		counts.InFlight,
+		time.Unix(0, atomic.LoadInt64(&b.until)).UTC().Format(time.RFC3339Nano),
+	)
 }
@@ -2,6 +2,8 @@ package easybreaker
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -13,6 +15,13 @@ func now(sec int64) func() time.Time {
 	return func() time.Time { return time.Unix(sec, 0) }
 }
 
+func withTime(ts int64) OptionCall {
+	return func(b *Breaker) error {
+		b.now = now(ts)
+		return nil
+	}
+}
+
 func TestNew(t *testing.T) {
 	to := func(uint32, uint32) bool { return false }
 	_, err := New(
@@ -36,6 +45,18 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, closed, b.state)
 }
 
+func TestNew_JoinedErrors(t *testing.T) {
+	_, err := New(
+		0, time.Minute,
+		WithLeastReqs(0),
+		WithMaxFailures(0),
+		WithName("api"),
+	)
+	assert.EqualError(t, err, "circuit: interval must be set\n"+
+		"circuit: atLeastReqs must be positive\n"+
+		"circuit: max failures must be positive")
+}
+
 func TestBreaker_OnFailure(t *testing.T) {
 	toOpen := func(total uint32, failures uint32) bool {
 		return total > 1 && failures > 1
@@ -50,13 +71,11 @@ func TestBreaker_OnFailure(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, closed, b.state)
 
-	b.total = 1
-	b.failures = 1
+	b.counts = packCounts(1, 1)
 	b.onFailure()
 	assert.Equal(t, closed, b.state)
 
-	b.total = 2
-	b.failures = 2
+	b.counts = packCounts(2, 2)
 	b.onFailure()
 	assert.Equal(t, open, b.state)
 }
@@ -73,26 +92,26 @@ func TestBreaker_Execute_WhenClosed(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1520100060000000000), b.until)
-	assert.Equal(t, uint32(0), b.total)
-	assert.Equal(t, uint32(0), b.failures)
+	assert.Equal(t, uint32(0), b.Counts().Total)
+	assert.Equal(t, uint32(0), b.Counts().Failures)
 
 	err = b.Execute(func() error { return nil })
 	assert.NoError(t, err)
-	assert.Equal(t, uint32(1), b.total)
-	assert.Equal(t, uint32(0), b.failures)
+	assert.Equal(t, uint32(1), b.Counts().Total)
+	assert.Equal(t, uint32(0), b.Counts().Failures)
 
 	err = b.Execute(func() error { return nil })
 	assert.NoError(t, err)
-	assert.Equal(t, uint32(2), b.total)
-	assert.Equal(t, uint32(0), b.failures)
+	assert.Equal(t, uint32(2), b.Counts().Total)
+	assert.Equal(t, uint32(0), b.Counts().Failures)
 
 	// passed interval period, 61 sec
 	b.now = now(1520100061)
 	err = b.Execute(func() error { return nil })
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1520100121000000000), b.until)
-	assert.Equal(t, uint32(1), b.total)
-	assert.Equal(t, uint32(0), b.failures)
+	assert.Equal(t, uint32(1), b.Counts().Total)
+	assert.Equal(t, uint32(0), b.Counts().Failures)
 }
 
 func TestBreaker_Execute_WhenOpen(t *testing.T) {
@@ -164,15 +183,15 @@ func TestBreaker_Execute_Requests(t *testing.T) {
 	wg.Add(20)
 	for i := 0; i < 20; i++ {
 		go func() {
-			err = b.Execute(func() error { return nil })
+			err := b.Execute(func() error { return nil })
 			assert.NoError(t, err)
 			wg.Done()
 		}()
 	}
 
 	wg.Wait()
-	assert.Equal(t, uint32(20), b.total)
-	assert.Equal(t, uint32(0), b.failures)
+	assert.Equal(t, uint32(20), b.Counts().Total)
+	assert.Equal(t, uint32(0), b.Counts().Failures)
 	assert.Equal(t, int64(1520100060000000000), b.until)
 }
 
@@ -195,15 +214,15 @@ func TestBreaker_Execute_Failures(t *testing.T) {
 	wg.Add(20)
 	for i := 0; i < 20; i++ {
 		go func() {
-			err = b.Execute(func() error { return errors.New("failed") })
+			err := b.Execute(func() error { return errors.New("failed") })
 			assert.NotNil(t, err)
 			wg.Done()
 		}()
 	}
 
 	wg.Wait()
-	assert.True(t, b.total < 20)
-	assert.True(t, b.failures < 20)
+	assert.True(t, b.Counts().Total < 20)
+	assert.True(t, b.Counts().Failures < 20)
 	assert.Equal(t, open, b.state)
 	assert.Equal(t, int64(1520100121000000000), b.until)
 }
@@ -227,15 +246,200 @@ func TestBreaker_Execute_AtLeastReqs(t *testing.T) {
 	wg.Add(20)
 	for i := 0; i < 20; i++ {
 		go func() {
-			err = b.Execute(func() error { return nil })
+			err := b.Execute(func() error { return nil })
 			assert.Nil(t, err)
 			wg.Done()
 		}()
 	}
 
 	wg.Wait()
-	assert.True(t, b.total <= 10)
-	assert.Equal(t, uint32(0), b.failures)
+	assert.True(t, b.Counts().Total <= 10)
+	assert.Equal(t, uint32(0), b.Counts().Failures)
 	assert.Equal(t, closed, b.state)
 	assert.Equal(t, int64(1520100061000000000), b.until)
 }
+
+func TestBreaker_String(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithName("api"),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, `breaker{name="api" state=closed total=0 failures=0 inflight=0 until=2018-03-03T18:01:00Z}`, b.String())
+
+	b.state = open
+	b.counts = packCounts(10, 3)
+	assert.Equal(t, `breaker{name="api" state=open total=10 failures=3 inflight=0 until=2018-03-03T18:01:00Z}`, fmt.Sprintf("%v", b))
+}
+
+func TestBreaker_StateCounts(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithNow(now(1520100000)),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 1 },
+			func(uint32, uint32) bool { return false },
+		),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, StateClosed, b.State())
+
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, Counts{Total: 2, Failures: 1, Successes: 1}, b.Counts())
+
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, Counts{}, b.Counts())
+}
+
+func TestBreaker_AllowDone(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Allow())
+	b.Done(nil)
+	assert.Equal(t, Counts{Total: 1, Successes: 1}, b.Counts())
+
+	assert.NoError(t, b.Allow())
+	b.Done(errors.New("failed"))
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, ErrBreakerOpen, b.Allow())
+}
+
+func TestBreaker_DegradationLevel(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithLeastReqs(10),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 1 },
+			func(uint32, uint32) bool { return false },
+		),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), b.DegradationLevel())
+
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, 0.25, b.DegradationLevel())
+
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, float64(1), b.DegradationLevel())
+
+	b.now = now(1520100121)
+	b.Execute(func() error { return nil })
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.Equal(t, 0.5, b.DegradationLevel())
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, 0.75, b.DegradationLevel())
+}
+
+func TestBreaker_InFlight(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Allow())
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, Counts{Total: 2, InFlight: 2}, b.Counts())
+
+	b.Execute(func() error {
+		assert.Equal(t, uint32(3), b.Counts().InFlight)
+		return nil
+	})
+
+	b.Done(nil)
+	b.Done(errors.New("failed"))
+	assert.Equal(t, uint32(0), b.Counts().InFlight)
+}
+
+func TestBreaker_MaxFailures(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithMaxFailures(2),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return float64(failures)/float64(total) >= 0.5 },
+			defaultToClosed,
+		),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.counts = packCounts(100, 0)
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateClosed, b.State())
+
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+
+	_, err = New(time.Minute, time.Minute, WithMaxFailures(0))
+	assert.Error(t, err)
+}
+
+func TestBreaker_FailureThreshold(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, WithFailureThreshold(0.2), withTime(1520100000))
+	assert.NoError(t, err)
+
+	b.counts = packCounts(8, 0)
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateClosed, b.State())
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+	reason, _ := b.TripReason()
+	assert.Equal(t, StrategyStateFunc, reason.Strategy)
+	assert.Equal(t, 0.2, reason.Threshold)
+
+	// the ratio and the other toOpen functions would override each other
+	_, err = New(time.Minute, time.Minute, WithStateFunc(defaultToOpen, defaultToClosed), WithFailureThreshold(0.2))
+	assert.EqualError(t, err, "circuit: failure threshold and state functions are exclusive")
+	_, err = New(time.Minute, time.Minute, WithAccumulator[float64](&ewma{}), WithFailureThreshold(0.2))
+	assert.EqualError(t, err, "circuit: failure threshold and accumulator are exclusive")
+
+	for _, ratio := range []float64{0, -0.1, 1.1, math.NaN()} {
+		_, err = New(time.Minute, time.Minute, WithFailureThreshold(ratio))
+		assert.Error(t, err, ratio)
+	}
+	_, err = New(time.Minute, time.Minute, WithFailureThreshold(1))
+	assert.NoError(t, err)
+}
+
+func TestBreaker_ExecuteN(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithFailureThreshold(0.5))
+	assert.NoError(t, err)
+
+	var attempts []int
+	err = b.ExecuteN(3, func(attempt int) error {
+		attempts = append(attempts, attempt)
+		if attempt < 2 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, attempts)
+	assert.Equal(t, Counts{Total: 1, Successes: 1}, b.Counts())
+
+	failed := errors.New("failed")
+	calls := 0
+	b.counts = packCounts(2, 0)
+	err = b.ExecuteN(0, func(int) error { calls++; return failed })
+	assert.Equal(t, failed, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, Counts{Total: 3, Failures: 1, Successes: 1}, b.Counts())
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Equal(t, failed, b.ExecuteN(5, func(int) error { return failed }))
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, ErrBreakerOpen, b.ExecuteN(5, func(int) error { return nil }))
+}
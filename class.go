@@ -0,0 +1,158 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// the common operation classes, any name can be used
+const (
+	ClassRead  = "read"
+	ClassWrite = "write"
+)
+
+// PartialOpen keeps admitting the requests of the given operation classes,
+// e.g. the reads, while the breaker is open, only the other classes,
+// e.g. the writes, are rejected, as many dependencies fail asymmetrically.
+// The class of a request is declared with AllowClass or ExecuteClass,
+// the requests of Allow and Execute have no class.
+// The admitted requests are counted as usual, ForceOpen rejects all of them.
+func WithPartialOpen(classes ...string) OptionCall {
+	return func(b *Breaker) error {
+		if len(classes) == 0 {
+			return errors.New("circuit: partial open classes must be defined")
+		}
+		b.openClasses = make(map[string]bool, len(classes))
+		for _, class := range classes {
+			if class == "" {
+				return errors.New("circuit: partial open class must be named")
+			}
+			b.openClasses[class] = true
+		}
+		return nil
+	}
+}
+
+// ClassBudget counts the requests of an operation class on their own and
+// trips the class alone when toOpen, called on its failures, returns true,
+// e.g. the writes are rejected while the reads are still admitted.
+// The class is rejected for the cooldown of the breaker, then admitted
+// again with fresh counts. Its counts are reset with the windows of the
+// breaker and reported by Counts.
+func WithClassBudget(class string, toOpen ToState) OptionCall {
+	return func(b *Breaker) error {
+		if class == "" {
+			return errors.New("circuit: budget class must be named")
+		}
+		if toOpen == nil {
+			return errors.New("circuit: budget toOpen must be defined")
+		}
+		if b.budgets == nil {
+			b.budgets = make(map[string]*budget)
+		}
+		b.budgets[class] = &budget{toOpen: toOpen}
+		return nil
+	}
+}
+
+// budget is the failure budget of an operation class.
+type budget struct {
+	counts uint64 // packed as the counts of the breaker
+	until  int64  // the end of the cooldown of the tripped class, 0 while closed
+	toOpen ToState
+}
+
+// AllowClass is Allow for a request of an operation class,
+// the accepted request must be reported with DoneClass.
+func (b *Breaker) AllowClass(class string) error {
+	_, err := b.allowClass(false, class)
+	return err
+}
+
+// DoneClass reports the result of a request accepted by AllowClass.
+func (b *Breaker) DoneClass(class string, err error) {
+	b.Done(err)
+	b.classDone(class, err)
+}
+
+// ExecuteClass is Execute for a request of an operation class.
+func (b *Breaker) ExecuteClass(class string, req func() error) error {
+	counted, err := b.allowClass(true, class)
+	if err != nil {
+		return err
+	}
+
+	start := b.begin()
+	start.uncounted = !counted
+	if b.chaosFailed() {
+		err = ErrChaos
+	} else {
+		err = req()
+	}
+	b.finish(start, err)
+	if counted {
+		b.classDone(class, err)
+	}
+	return err
+}
+
+// ClassState returns the state of the budget of an operation class,
+// the classes without a budget follow the breaker.
+func (b *Breaker) ClassState(class string) State {
+	if c := b.budgets[class]; c != nil && atomic.LoadInt64(&c.until) > b.now().UnixNano() {
+		return StateOpen
+	}
+	return b.State()
+}
+
+func (b *Breaker) classDone(class string, err error) {
+	c := b.budgets[class]
+	if c == nil || !b.failed(err) {
+		return
+	}
+	if b.ignores(err) {
+		uncount(&c.counts, 1)
+		return
+	}
+
+	total, failures := unpackCounts(atomic.AddUint64(&c.counts, failureUnit))
+	if atomic.LoadInt64(&c.until) != 0 || !c.toOpen(total, failures) {
+		return
+	}
+	if atomic.CompareAndSwapInt64(&c.until, 0, b.now().UnixNano()+b.cooldown) {
+		atomic.StoreUint64(&c.counts, 0)
+	}
+}
+
+// classCounts returns the counts of the budgets, nil without any.
+func (b *Breaker) classCounts() map[string]Counts {
+	if b.budgets == nil {
+		return nil
+	}
+	counts := make(map[string]Counts, len(b.budgets))
+	for class, c := range b.budgets {
+		total, failures := unpackCounts(atomic.LoadUint64(&c.counts))
+		counts[class] = Counts{Total: total, Failures: failures}
+	}
+	return counts
+}
+
+// ready reports whether the class is admitted, closing it after the cooldown.
+func (c *budget) ready(now int64) bool {
+	until := atomic.LoadInt64(&c.until)
+	if until == 0 {
+		return true
+	}
+	if now < until {
+		return false
+	}
+	if atomic.CompareAndSwapInt64(&c.until, until, 0) {
+		atomic.StoreUint64(&c.counts, 0)
+	}
+	return true
+}
+
+// passThrough reports whether the class is admitted while the breaker is open.
+func (b *Breaker) passThrough(class string) bool {
+	return b.openClasses[class] && b.override() == forcedNone
+}
@@ -0,0 +1,99 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_PartialOpen(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithPartialOpen(ClassRead), withTime(1520100000))
+	assert.NoError(t, err)
+
+	failed := errors.New("failed")
+	assert.Equal(t, failed, b.ExecuteClass(ClassWrite, func() error { return failed }))
+	assert.Equal(t, StateOpen, b.State())
+
+	assert.NoError(t, b.ExecuteClass(ClassRead, func() error { return nil }))
+	assert.Equal(t, ErrBreakerOpen, b.ExecuteClass(ClassWrite, func() error { return nil }))
+	assert.Equal(t, ErrBreakerOpen, b.Execute(func() error { return nil }))
+	assert.Equal(t, Counts{Total: 1, Successes: 1}, b.Counts())
+
+	b.ForceOpen()
+	assert.Equal(t, ErrBreakerOpen, b.AllowClass(ClassRead))
+	b.Release()
+	assert.NoError(t, b.AllowClass(ClassRead))
+	b.Done(nil)
+
+	_, err = New(time.Minute, time.Minute, WithPartialOpen())
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithPartialOpen(""))
+	assert.Error(t, err)
+}
+
+func TestBreaker_Class_HalfOpen(t *testing.T) {
+	probe := false
+	b, err := New(
+		time.Minute, time.Minute,
+		WithLeastReqs(10),
+		WithHalfOpenPercent(50),
+		WithProbeSelector(func() bool { return probe }, false),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.ExecuteClass(ClassWrite, func() error { return errors.New("failed") })
+	b.now = now(1520100060)
+	probe = true
+	assert.NoError(t, b.ExecuteClass(ClassWrite, func() error { return nil }))
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	// the class requests are limited like the others
+	var admitted int
+	for i := 0; i < 4; i++ {
+		if b.AllowClass(ClassWrite) == nil {
+			b.DoneClass(ClassWrite, nil)
+			admitted++
+		}
+	}
+	assert.Equal(t, 2, admitted)
+	probe = false
+	assert.Equal(t, ErrBreakerOpen, b.ExecuteClass(ClassRead, func() error { return nil }))
+}
+
+func TestBreaker_ClassBudget(t *testing.T) {
+	b, err := New(
+		time.Minute, time.Minute,
+		WithStateFunc(func(total, failures uint32) bool { return false }, defaultToClosed),
+		WithClassBudget(ClassWrite, func(total, failures uint32) bool { return failures >= 2 }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	failed := errors.New("failed")
+	b.ExecuteClass(ClassWrite, func() error { return failed })
+	b.ExecuteClass(ClassRead, func() error { return failed })
+	assert.Equal(t, Counts{Total: 2, Failures: 2, Classes: map[string]Counts{ClassWrite: {Total: 1, Failures: 1}}}, b.Counts())
+
+	assert.NoError(t, b.AllowClass(ClassWrite))
+	b.DoneClass(ClassWrite, failed)
+	assert.Equal(t, StateOpen, b.ClassState(ClassWrite))
+	assert.Equal(t, StateClosed, b.ClassState(ClassRead))
+
+	// the writes alone are rejected
+	assert.Equal(t, ErrBreakerOpen, b.ExecuteClass(ClassWrite, func() error { return nil }))
+	assert.NoError(t, b.ExecuteClass(ClassRead, func() error { return nil }))
+	assert.Equal(t, Counts{Total: 4, Failures: 3, Successes: 1, Classes: map[string]Counts{ClassWrite: {}}}, b.Counts())
+
+	// admitted again after the cooldown
+	b.now = now(1520100030)
+	assert.Equal(t, ErrBreakerOpen, b.AllowClass(ClassWrite))
+	b.now = now(1520100060)
+	assert.NoError(t, b.ExecuteClass(ClassWrite, func() error { return nil }))
+	assert.Equal(t, StateClosed, b.ClassState(ClassWrite))
+
+	_, err = New(time.Minute, time.Minute, WithClassBudget("", defaultToOpen), WithClassBudget(ClassWrite, nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the source of the time and the timers of the breaker and
+// its integrations, a simulated clock drives the breakers deterministically.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the timer of a Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// SystemClock is the real time clock.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// nowClock is the clock of WithNow, its timers are real
+type nowClock func() time.Time
+
+func (c nowClock) Now() time.Time {
+	return c()
+}
+
+func (c nowClock) NewTimer(d time.Duration) Timer {
+	return SystemClock.NewTimer(d)
+}
+
+// Clock is the source of the time and the timers, SystemClock by default.
+func WithClock(clock Clock) OptionCall {
+	return func(b *Breaker) error {
+		if clock == nil {
+			return errors.New("circuit: clock must be defined")
+		}
+		b.clock = clock
+		b.now = clock.Now
+		return nil
+	}
+}
+
+// Clock returns the clock of the breaker, the integrations
+// use it for their timers.
+func (b *Breaker) Clock() Clock {
+	return b.clock
+}
+
+// clockContext is a context expiring on a timer of the breaker clock.
+type clockContext struct {
+	context.Context // canceled once expired
+	deadline        time.Time
+	expired         int32
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockContext) Err() error {
+	if atomic.LoadInt32(&c.expired) == 1 {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
+
+// withTimeoutCtx is context.WithTimeout on the clock of the breaker,
+// the deadline is read from b.now and the context expires on a timer
+// of the clock.
+func (b *Breaker) withTimeoutCtx(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	c := &clockContext{Context: ctx, deadline: b.now().Add(d)}
+	t := b.clock.NewTimer(d)
+	go func() {
+		select {
+		case <-t.C():
+			atomic.StoreInt32(&c.expired, 1)
+			cancel()
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}()
+	return c, cancel
+}
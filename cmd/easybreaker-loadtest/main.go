@@ -0,0 +1,189 @@
+// Command easybreaker-loadtest drives a mix of successful, failed and slow
+// requests at a target rate through a circuit breaker and prints the trip and
+// recovery timeline along with the overhead of the breaker.
+//
+//	easybreaker-loadtest -qps 1000 -phases 10s:0,10s:0.3,20s:0 -latency 5ms
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+var errInjected = errors.New("loadtest: injected failure")
+
+// maxQPS keeps the tick of the ticker at one nanosecond at least.
+const maxQPS = int(time.Second)
+
+// checkQPS validates the target requests per second.
+func checkQPS(qps int) error {
+	if qps < 1 || qps > maxQPS {
+		return fmt.Errorf("loadtest: qps must be in [1, %d]", maxQPS)
+	}
+	return nil
+}
+
+// phase runs the requests with the failure rate for the duration.
+type phase struct {
+	duration    time.Duration
+	failureRate float64
+}
+
+// parsePhases parses a comma separated list of duration:failureRate pairs.
+func parsePhases(s string) ([]phase, error) {
+	var phases []phase
+	for _, item := range strings.Split(s, ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("loadtest: invalid phase %q, expected duration:failureRate", item)
+		}
+		d, err := time.ParseDuration(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("loadtest: invalid phase %q: %v", item, err)
+		}
+		rate, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("loadtest: invalid phase %q, failure rate must be in [0, 1]", item)
+		}
+		phases = append(phases, phase{duration: d, failureRate: rate})
+	}
+	return phases, nil
+}
+
+type stats struct {
+	admitted uint64
+	rejected uint64
+	failures uint64
+	overhead int64 // nanoseconds spent in the breaker, excluding the request
+}
+
+func main() {
+	var (
+		qps       = flag.Int("qps", 1000, "target requests per second")
+		phasesArg = flag.String("phases", "10s:0,10s:0.5,20s:0", "comma separated duration:failureRate phases")
+		latency   = flag.Duration("latency", 0, "latency of every request")
+		jitter    = flag.Duration("jitter", 0, "random latency added to every request")
+		interval  = flag.Duration("interval", 10*time.Second, "breaker interval")
+		cooldown  = flag.Duration("cooldown", 5*time.Second, "breaker cooldown")
+		leastReqs = flag.Uint("least-reqs", 100, "breaker requests to consider in the half-open state")
+		threshold = flag.Float64("threshold", 0.05, "failure ratio opening the breaker")
+	)
+	flag.Parse()
+
+	phases, err := parsePhases(*phasesArg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := checkQPS(*qps); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ratio := *threshold
+	b, err := easybreaker.New(
+		*interval, *cooldown,
+		easybreaker.WithName("loadtest"),
+		easybreaker.WithLeastReqs(uint32(*leastReqs)),
+		easybreaker.WithStateFunc(
+			func(total uint32, failures uint32) bool {
+				return total > 0 && float64(failures)/float64(total) >= ratio
+			},
+			func(total uint32, failures uint32) bool { return failures == 0 },
+		),
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var (
+		st    stats
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		last  = b.State()
+		start = time.Now()
+	)
+
+	// record prints the transition observed after a request
+	record := func() {
+		state := b.State()
+		mu.Lock()
+		if state != last {
+			fmt.Printf("%10s  %-9s -> %s\n", time.Since(start).Truncate(time.Millisecond), last, state)
+			last = state
+		}
+		mu.Unlock()
+	}
+
+	fmt.Printf("%10s  %s\n", "0s", b)
+
+	tick := time.Second / time.Duration(*qps)
+	for _, p := range phases {
+		fmt.Printf("%10s  phase %s with failure rate %.2f\n", time.Since(start).Truncate(time.Millisecond), p.duration, p.failureRate)
+
+		ticker := time.NewTicker(tick)
+		deadline := time.Now().Add(p.duration)
+		for now := range ticker.C {
+			if now.After(deadline) {
+				break
+			}
+
+			wg.Add(1)
+			go func(rate float64) {
+				defer wg.Done()
+
+				var spent time.Duration
+				begin := time.Now()
+				err := b.Execute(func() error {
+					reqStart := time.Now()
+					defer func() { spent = time.Since(reqStart) }()
+
+					d := *latency
+					if *jitter > 0 {
+						d += time.Duration(rand.Int63n(int64(*jitter)))
+					}
+					if d > 0 {
+						time.Sleep(d)
+					}
+					if rand.Float64() < rate {
+						return errInjected
+					}
+					return nil
+				})
+				atomic.AddInt64(&st.overhead, int64(time.Since(begin)-spent))
+
+				switch {
+				case errors.Is(err, easybreaker.ErrBreakerOpen):
+					atomic.AddUint64(&st.rejected, 1)
+				case err == nil:
+					atomic.AddUint64(&st.admitted, 1)
+				default:
+					atomic.AddUint64(&st.admitted, 1)
+					atomic.AddUint64(&st.failures, 1)
+				}
+				record()
+			}(p.failureRate)
+		}
+		ticker.Stop()
+	}
+	wg.Wait()
+
+	total := st.admitted + st.rejected
+	fmt.Printf("%10s  %s\n\n", time.Since(start).Truncate(time.Millisecond), b)
+	fmt.Printf("requests: %d, admitted: %d, rejected: %d, failures: %d\n", total, st.admitted, st.rejected, st.failures)
+	if total > 0 {
+		fmt.Printf("breaker overhead: %s per request\n", time.Duration(st.overhead/int64(total)))
+	}
+}
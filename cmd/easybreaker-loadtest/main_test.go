@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePhases(t *testing.T) {
+	phases, err := parsePhases("10s:0, 1m:0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, []phase{
+		{duration: 10 * time.Second, failureRate: 0},
+		{duration: time.Minute, failureRate: 0.5},
+	}, phases)
+
+	_, err = parsePhases("10s")
+	assert.Error(t, err)
+
+	_, err = parsePhases("10s:2")
+	assert.Error(t, err)
+}
+
+func TestCheckQPS(t *testing.T) {
+	assert.NoError(t, checkQPS(1))
+	assert.NoError(t, checkQPS(maxQPS))
+	assert.Error(t, checkQPS(0))
+	assert.Error(t, checkQPS(-1))
+	assert.Error(t, checkQPS(maxQPS+1))
+}
@@ -0,0 +1,264 @@
+// Package gobreaker exposes the API of github.com/sony/gobreaker backed by
+// easybreaker, so the code written against gobreaker can migrate by changing
+// the import path only.
+//
+// The differences from gobreaker:
+//
+//   - Interval 0 means a year instead of never clearing the counts,
+//     easybreaker always requires an interval;
+//   - in the half-open state MaxRequests requests are accepted and the breaker
+//     closes if none of them failed, a failure doesn't open it immediately;
+//   - ErrTooManyRequests is never returned;
+//   - ConsecutiveSuccesses and ConsecutiveFailures are tracked by the adapter
+//     and cleared on the state changes only;
+//   - OnStateChange is called by the request observing the change.
+package gobreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+const (
+	defaultInterval = 365 * 24 * time.Hour
+	defaultTimeout  = 60 * time.Second
+)
+
+var (
+	// ErrOpenState is returned when the breaker is open.
+	ErrOpenState = easybreaker.ErrBreakerOpen
+
+	// ErrTooManyRequests is never returned, it's kept for compatibility.
+	ErrTooManyRequests = errors.New("too many requests")
+
+	// errUnsuccessful reports an unsuccessful request to the breaker
+	errUnsuccessful = errors.New("gobreaker: unsuccessful request")
+)
+
+// State is a state of CircuitBreaker.
+type State int
+
+const (
+	StateClosed   = State(easybreaker.StateClosed)
+	StateHalfOpen = State(easybreaker.StateHalfOpen)
+	StateOpen     = State(easybreaker.StateOpen)
+)
+
+func (s State) String() string {
+	return easybreaker.State(s).String()
+}
+
+// Counts holds the numbers of requests and their successes/failures.
+type Counts struct {
+	Requests             uint32
+	TotalSuccesses       uint32
+	TotalFailures        uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
+}
+
+// Settings configures CircuitBreaker, see gobreaker.Settings.
+type Settings struct {
+	Name          string
+	MaxRequests   uint32
+	Interval      time.Duration
+	Timeout       time.Duration
+	ReadyToTrip   func(counts Counts) bool
+	OnStateChange func(name string, from State, to State)
+	IsSuccessful  func(err error) bool
+}
+
+// CircuitBreaker is a state machine to prevent sending requests that are likely to fail.
+type CircuitBreaker struct {
+	name          string
+	breaker       *easybreaker.Breaker
+	readyToTrip   func(counts Counts) bool
+	onStateChange func(name string, from State, to State)
+	isSuccessful  func(err error) bool
+
+	consecutiveSuccesses uint32
+	consecutiveFailures  uint32
+	state                int32 // the last state reported to onStateChange
+}
+
+// TwoStepCircuitBreaker is like CircuitBreaker but instead of surrounding a function
+// with the breaker, it only checks whether a request can proceed and
+// expects the caller to report the outcome in a separate step using a callback.
+type TwoStepCircuitBreaker struct {
+	cb *CircuitBreaker
+}
+
+func defaultReadyToTrip(counts Counts) bool {
+	return counts.ConsecutiveFailures > 5
+}
+
+func defaultIsSuccessful(err error) bool {
+	return err == nil
+}
+
+// NewCircuitBreaker returns a new CircuitBreaker configured with the given Settings.
+// It panics if the breaker can't be created, gobreaker doesn't return an error.
+func NewCircuitBreaker(st Settings) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		name:          st.Name,
+		readyToTrip:   st.ReadyToTrip,
+		onStateChange: st.OnStateChange,
+		isSuccessful:  st.IsSuccessful,
+	}
+	if cb.readyToTrip == nil {
+		cb.readyToTrip = defaultReadyToTrip
+	}
+	if cb.isSuccessful == nil {
+		cb.isSuccessful = defaultIsSuccessful
+	}
+
+	maxRequests := st.MaxRequests
+	if maxRequests == 0 {
+		maxRequests = 1
+	}
+	interval := st.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	timeout := st.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	b, err := easybreaker.New(
+		interval, timeout,
+		easybreaker.WithName(st.Name),
+		easybreaker.WithLeastReqs(maxRequests),
+		easybreaker.WithStateFunc(cb.toOpen, cb.toClosed),
+	)
+	if err != nil {
+		panic(err)
+	}
+	cb.breaker = b
+
+	return cb
+}
+
+// NewTwoStepCircuitBreaker returns a new TwoStepCircuitBreaker configured with the given Settings.
+func NewTwoStepCircuitBreaker(st Settings) *TwoStepCircuitBreaker {
+	return &TwoStepCircuitBreaker{cb: NewCircuitBreaker(st)}
+}
+
+func (cb *CircuitBreaker) toOpen(total uint32, failures uint32) bool {
+	return cb.readyToTrip(cb.counts(total, failures, cb.breaker.Counts().Successes))
+}
+
+func (cb *CircuitBreaker) toClosed(total uint32, failures uint32) bool {
+	return failures == 0
+}
+
+func (cb *CircuitBreaker) counts(total uint32, failures uint32, successes uint32) Counts {
+	return Counts{
+		Requests:             total,
+		TotalSuccesses:       successes,
+		TotalFailures:        failures,
+		ConsecutiveSuccesses: atomic.LoadUint32(&cb.consecutiveSuccesses),
+		ConsecutiveFailures:  atomic.LoadUint32(&cb.consecutiveFailures),
+	}
+}
+
+// Name returns the name of the CircuitBreaker.
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// State returns the current state of the CircuitBreaker.
+func (cb *CircuitBreaker) State() State {
+	return State(cb.breaker.State())
+}
+
+// Counts returns internal counters.
+func (cb *CircuitBreaker) Counts() Counts {
+	counts := cb.breaker.Counts()
+	return cb.counts(counts.Total, counts.Failures, counts.Successes)
+}
+
+// Execute runs the given request if the CircuitBreaker accepts it.
+// Execute returns an error instantly if the CircuitBreaker rejects the request.
+// Otherwise, Execute returns the result of the request.
+// If a panic occurs in the request, the CircuitBreaker handles it as an error
+// and causes the same panic again.
+func (cb *CircuitBreaker) Execute(req func() (interface{}, error)) (interface{}, error) {
+	done, err := cb.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			done(false)
+			panic(e)
+		}
+	}()
+
+	result, err := req()
+	done(cb.isSuccessful(err))
+	return result, err
+}
+
+// Name returns the name of the TwoStepCircuitBreaker.
+func (tscb *TwoStepCircuitBreaker) Name() string {
+	return tscb.cb.Name()
+}
+
+// State returns the current state of the TwoStepCircuitBreaker.
+func (tscb *TwoStepCircuitBreaker) State() State {
+	return tscb.cb.State()
+}
+
+// Counts returns internal counters.
+func (tscb *TwoStepCircuitBreaker) Counts() Counts {
+	return tscb.cb.Counts()
+}
+
+// Allow checks if a new request can proceed. It returns a callback that should be used to
+// register the success or failure in a separate step. If the circuit breaker doesn't allow
+// requests, it returns an error.
+func (tscb *TwoStepCircuitBreaker) Allow() (done func(success bool), err error) {
+	return tscb.cb.allow()
+}
+
+func (cb *CircuitBreaker) allow() (func(success bool), error) {
+	if err := cb.breaker.Allow(); err != nil {
+		cb.notify()
+		return nil, err
+	}
+	cb.notify()
+
+	return func(success bool) {
+		if success {
+			atomic.AddUint32(&cb.consecutiveSuccesses, 1)
+			atomic.StoreUint32(&cb.consecutiveFailures, 0)
+			cb.breaker.Done(nil)
+		} else {
+			atomic.AddUint32(&cb.consecutiveFailures, 1)
+			atomic.StoreUint32(&cb.consecutiveSuccesses, 0)
+			cb.breaker.Done(errUnsuccessful)
+		}
+		cb.notify()
+	}, nil
+}
+
+// notify clears the consecutive counters and calls onStateChange
+// if the state has changed since the last call.
+func (cb *CircuitBreaker) notify() {
+	last := atomic.LoadInt32(&cb.state)
+	state := int32(cb.breaker.State())
+	if state == last || !atomic.CompareAndSwapInt32(&cb.state, last, state) {
+		return
+	}
+
+	atomic.StoreUint32(&cb.consecutiveSuccesses, 0)
+	atomic.StoreUint32(&cb.consecutiveFailures, 0)
+	if cb.onStateChange != nil {
+		cb.onStateChange(cb.name, State(last), State(state))
+	}
+}
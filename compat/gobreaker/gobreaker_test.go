@@ -0,0 +1,102 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errFailed = errors.New("failed")
+
+func fail() (interface{}, error) { return nil, errFailed }
+
+func succeed() (interface{}, error) { return "ok", nil }
+
+func TestCircuitBreaker_Execute(t *testing.T) {
+	var changes []State
+	cb := NewCircuitBreaker(Settings{
+		Name:        "api",
+		MaxRequests: 2,
+		Timeout:     10 * time.Millisecond,
+		OnStateChange: func(name string, from State, to State) {
+			assert.Equal(t, "api", name)
+			changes = append(changes, to)
+		},
+	})
+	assert.Equal(t, "api", cb.Name())
+	assert.Equal(t, StateClosed, cb.State())
+
+	result, err := cb.Execute(succeed)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", result)
+
+	// the default ReadyToTrip trips after more than 5 consecutive failures
+	for i := 0; i < 5; i++ {
+		cb.Execute(fail)
+	}
+	assert.Equal(t, Counts{Requests: 6, TotalSuccesses: 1, TotalFailures: 5, ConsecutiveFailures: 5}, cb.Counts())
+	assert.Equal(t, StateClosed, cb.State())
+
+	_, err = cb.Execute(fail)
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, StateOpen, cb.State())
+
+	_, err = cb.Execute(succeed)
+	assert.Equal(t, ErrOpenState, err)
+
+	time.Sleep(20 * time.Millisecond)
+	cb.Execute(succeed)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	cb.Execute(succeed)
+	cb.Execute(succeed)
+	assert.Equal(t, StateClosed, cb.State())
+
+	assert.Equal(t, []State{StateOpen, StateHalfOpen, StateClosed}, changes)
+}
+
+func TestCircuitBreaker_IsSuccessful(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		ReadyToTrip:  func(counts Counts) bool { return counts.TotalFailures > 0 },
+		IsSuccessful: func(err error) bool { return err == nil || err == errFailed },
+	})
+
+	_, err := cb.Execute(fail)
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, uint32(1), cb.Counts().TotalSuccesses)
+}
+
+func TestCircuitBreaker_Panic(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		ReadyToTrip: func(counts Counts) bool { return counts.TotalFailures > 0 },
+	})
+
+	assert.Panics(t, func() {
+		cb.Execute(func() (interface{}, error) { panic("oops") })
+	})
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestTwoStepCircuitBreaker_Allow(t *testing.T) {
+	tscb := NewTwoStepCircuitBreaker(Settings{
+		Name:        "api",
+		ReadyToTrip: func(counts Counts) bool { return counts.ConsecutiveFailures > 1 },
+	})
+	assert.Equal(t, "api", tscb.Name())
+
+	done, err := tscb.Allow()
+	assert.NoError(t, err)
+	done(false)
+	assert.Equal(t, StateClosed, tscb.State())
+
+	done, err = tscb.Allow()
+	assert.NoError(t, err)
+	done(false)
+	assert.Equal(t, StateOpen, tscb.State())
+
+	_, err = tscb.Allow()
+	assert.Equal(t, ErrOpenState, err)
+	assert.Equal(t, Counts{}, tscb.Counts())
+}
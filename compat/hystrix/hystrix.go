@@ -0,0 +1,222 @@
+// Package hystrix offers the named commands of github.com/afex/hystrix-go
+// on top of the easybreaker registry, the commands are configured centrally
+// with ConfigureCommand and run with Do or Go.
+//
+// A command is backed by a breaker with a 10 seconds interval, like the rolling
+// window of hystrix-go, opening once RequestVolumeThreshold requests are seen and
+// ErrorPercentThreshold percent of them failed. After SleepWindow a single request
+// is let through and the breaker closes if it succeeds.
+package hystrix
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+const (
+	interval = 10 * time.Second
+
+	DefaultTimeout               = 1000
+	DefaultMaxConcurrent         = 10
+	DefaultVolumeThreshold       = 20
+	DefaultSleepWindow           = 5000
+	DefaultErrorPercentThreshold = 50
+)
+
+var (
+	// ErrCircuitOpen is returned when the breaker of the command is open.
+	ErrCircuitOpen = easybreaker.ErrBreakerOpen
+	// ErrMaxConcurrency is returned when too many requests of the command are running.
+	ErrMaxConcurrency = errors.New("hystrix: max concurrency")
+	// ErrTimeout is returned when the request didn't finish within the timeout.
+	ErrTimeout = errors.New("hystrix: timeout")
+)
+
+type runFunc func() error
+type fallbackFunc func(error) error
+
+// CommandConfig configures a command, the zero values are replaced by the defaults.
+type CommandConfig struct {
+	Timeout                int // milliseconds
+	MaxConcurrentRequests  int
+	RequestVolumeThreshold int
+	SleepWindow            int // milliseconds
+	ErrorPercentThreshold  int
+}
+
+type command struct {
+	breaker *easybreaker.Breaker
+	timeout time.Duration
+	tickets chan struct{}
+}
+
+var (
+	registry = easybreaker.NewRegistry(interval, DefaultSleepWindow*time.Millisecond)
+
+	mu       sync.RWMutex
+	commands = make(map[string]*command)
+)
+
+// Configure applies the settings of a set of commands.
+func Configure(cmds map[string]CommandConfig) error {
+	for name, config := range cmds {
+		if err := ConfigureCommand(name, config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfigureCommand applies the settings of the named command,
+// it replaces the breaker of the command.
+func ConfigureCommand(name string, config CommandConfig) error {
+	cmd, err := newCommand(name, config)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	commands[name] = cmd
+	mu.Unlock()
+	return nil
+}
+
+func newCommand(name string, config CommandConfig) (*command, error) {
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxConcurrentRequests == 0 {
+		config.MaxConcurrentRequests = DefaultMaxConcurrent
+	}
+	if config.RequestVolumeThreshold == 0 {
+		config.RequestVolumeThreshold = DefaultVolumeThreshold
+	}
+	if config.SleepWindow == 0 {
+		config.SleepWindow = DefaultSleepWindow
+	}
+	if config.ErrorPercentThreshold == 0 {
+		config.ErrorPercentThreshold = DefaultErrorPercentThreshold
+	}
+
+	volume := uint32(config.RequestVolumeThreshold)
+	percent := uint32(config.ErrorPercentThreshold)
+	toOpen := func(total uint32, failures uint32) bool {
+		return total >= volume && failures*100 >= percent*total
+	}
+	toClosed := func(total uint32, failures uint32) bool {
+		return failures == 0
+	}
+
+	b, err := registry.Configure(
+		name, interval, time.Duration(config.SleepWindow)*time.Millisecond,
+		easybreaker.WithLeastReqs(1),
+		easybreaker.WithStateFunc(toOpen, toClosed),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &command{
+		breaker: b,
+		timeout: time.Duration(config.Timeout) * time.Millisecond,
+		tickets: make(chan struct{}, config.MaxConcurrentRequests),
+	}, nil
+}
+
+func getCommand(name string) (*command, error) {
+	mu.RLock()
+	cmd, ok := commands[name]
+	mu.RUnlock()
+	if ok {
+		return cmd, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cmd, ok = commands[name]; ok {
+		return cmd, nil
+	}
+	cmd, err := newCommand(name, CommandConfig{})
+	if err != nil {
+		return nil, err
+	}
+	commands[name] = cmd
+	return cmd, nil
+}
+
+// GetBreaker returns the breaker of the named command.
+func GetBreaker(name string) (*easybreaker.Breaker, error) {
+	cmd, err := getCommand(name)
+	if err != nil {
+		return nil, err
+	}
+	return cmd.breaker, nil
+}
+
+// Do runs run and waits for it to finish, fallback is called with the error
+// when run fails, times out or is rejected by the breaker.
+func Do(name string, run runFunc, fallback fallbackFunc) error {
+	return <-Go(name, run, fallback)
+}
+
+// Go runs run in the background and returns a channel receiving its error,
+// or the error of fallback. The channel receives nil on success.
+func Go(name string, run runFunc, fallback fallbackFunc) chan error {
+	errs := make(chan error, 1)
+
+	cmd, err := getCommand(name)
+	if err != nil {
+		errs <- err
+		return errs
+	}
+
+	select {
+	case cmd.tickets <- struct{}{}:
+	default:
+		errs <- doFallback(ErrMaxConcurrency, fallback)
+		return errs
+	}
+
+	if err := cmd.breaker.Allow(); err != nil {
+		<-cmd.tickets
+		errs <- doFallback(err, fallback)
+		return errs
+	}
+
+	go func() {
+		done := make(chan error, 1)
+		go func() {
+			done <- run()
+		}()
+
+		timer := cmd.breaker.Clock().NewTimer(cmd.timeout)
+		defer timer.Stop()
+
+		var err error
+		select {
+		case err = <-done:
+		case <-timer.C():
+			err = ErrTimeout
+		}
+		cmd.breaker.Done(err)
+		<-cmd.tickets
+
+		if err != nil {
+			err = doFallback(err, fallback)
+		}
+		errs <- err
+	}()
+
+	return errs
+}
+
+func doFallback(err error, fallback fallbackFunc) error {
+	if fallback == nil {
+		return err
+	}
+	return fallback(err)
+}
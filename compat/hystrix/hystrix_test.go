@@ -0,0 +1,85 @@
+package hystrix
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFailed = errors.New("failed")
+
+func TestDo(t *testing.T) {
+	assert.NoError(t, Do("do", func() error { return nil }, nil))
+	assert.Equal(t, errFailed, Do("do", func() error { return errFailed }, nil))
+
+	err := Do("do", func() error { return errFailed }, func(err error) error {
+		assert.Equal(t, errFailed, err)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestDo_CircuitOpen(t *testing.T) {
+	err := ConfigureCommand("open", CommandConfig{
+		RequestVolumeThreshold: 4,
+		ErrorPercentThreshold:  50,
+		SleepWindow:            10,
+	})
+	assert.NoError(t, err)
+
+	Do("open", func() error { return nil }, nil)
+	Do("open", func() error { return nil }, nil)
+	Do("open", func() error { return errFailed }, nil)
+	b, err := GetBreaker("open")
+	assert.NoError(t, err)
+	assert.Equal(t, easybreaker.StateClosed, b.State())
+
+	Do("open", func() error { return errFailed }, nil)
+	assert.Equal(t, easybreaker.StateOpen, b.State())
+
+	var fallbackErr error
+	Do("open", func() error { return nil }, func(err error) error {
+		fallbackErr = err
+		return nil
+	})
+	assert.Equal(t, ErrCircuitOpen, fallbackErr)
+
+	// a single request is let through after the sleep window
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, Do("open", func() error { return nil }, nil))
+	assert.NoError(t, Do("open", func() error { return nil }, nil))
+	assert.Equal(t, easybreaker.StateClosed, b.State())
+}
+
+func TestGo_Timeout(t *testing.T) {
+	err := ConfigureCommand("timeout", CommandConfig{Timeout: 10})
+	assert.NoError(t, err)
+
+	errs := Go("timeout", func() error {
+		time.Sleep(time.Second)
+		return nil
+	}, nil)
+	assert.Equal(t, ErrTimeout, <-errs)
+
+	b, err := GetBreaker("timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, easybreaker.Counts{Total: 1, Failures: 1}, b.Counts())
+}
+
+func TestGo_MaxConcurrency(t *testing.T) {
+	err := ConfigureCommand("concurrency", CommandConfig{MaxConcurrentRequests: 1})
+	assert.NoError(t, err)
+
+	release := make(chan struct{})
+	errs := Go("concurrency", func() error {
+		<-release
+		return nil
+	}, nil)
+
+	assert.Equal(t, ErrMaxConcurrency, Do("concurrency", func() error { return nil }, nil))
+	close(release)
+	assert.NoError(t, <-errs)
+}
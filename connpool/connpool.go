@@ -0,0 +1,84 @@
+// Package connpool gates a connection pool with a breaker: acquiring
+// a connection is rejected while the breaker is open, the failed dials and
+// handshakes count as failures and the idle connections to the endpoint
+// are drained once the breaker trips.
+//
+//	pool, err := connpool.New[*grpc.ClientConn](endpointPool, breaker)
+//	conn, err := pool.Get(ctx)
+//	defer pool.Put(conn)
+package connpool
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Pool is the connection pool being decorated.
+type Pool[C any] interface {
+	// Get returns an idle connection or dials a new one,
+	// the dial and handshake errors are returned.
+	Get(ctx context.Context) (C, error)
+	// Put returns a connection to the pool.
+	Put(c C)
+	// Drain closes the idle connections.
+	Drain()
+}
+
+// Gated is the pool gated by the breaker.
+type Gated[C any] struct {
+	pool        Pool[C]
+	breaker     *easybreaker.Breaker
+	unsubscribe func()
+}
+
+// New returns the pool gated by b, the pool is drained when b opens.
+func New[C any](pool Pool[C], b *easybreaker.Breaker) (*Gated[C], error) {
+	if pool == nil {
+		return nil, errors.New("connpool: pool must be set")
+	}
+	if b == nil {
+		return nil, errors.New("connpool: breaker must be set")
+	}
+
+	g := &Gated[C]{pool: pool, breaker: b}
+	unsubscribe, err := b.Subscribe(easybreaker.SeverityWarn, g.onTrip)
+	if err != nil {
+		return nil, err
+	}
+	g.unsubscribe = unsubscribe
+	return g, nil
+}
+
+func (g *Gated[C]) onTrip(e easybreaker.Event) {
+	if e.Type == easybreaker.EventStateChange && e.To == easybreaker.StateOpen {
+		g.pool.Drain()
+	}
+}
+
+// Get acquires a connection, it returns easybreaker.ErrBreakerOpen
+// while the breaker is open.
+func (g *Gated[C]) Get(ctx context.Context) (C, error) {
+	if err := g.breaker.Allow(); err != nil {
+		var zero C
+		return zero, err
+	}
+	c, err := g.pool.Get(ctx)
+	g.breaker.Done(err)
+	return c, err
+}
+
+// Put returns a connection to the pool, the pool is drained instead
+// if the breaker opened meanwhile.
+func (g *Gated[C]) Put(c C) {
+	g.pool.Put(c)
+	if g.breaker.State() == easybreaker.StateOpen {
+		g.pool.Drain()
+	}
+}
+
+// Close detaches the pool from the breaker, the pool itself is not closed.
+func (g *Gated[C]) Close() {
+	g.unsubscribe()
+}
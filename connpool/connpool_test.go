@@ -0,0 +1,86 @@
+package connpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+type conn struct {
+	closed bool
+}
+
+type pool struct {
+	idle    []*conn
+	dialErr error
+	dials   int
+	drains  int
+}
+
+// Get always dials, the idle connections are only drained
+func (p *pool) Get(ctx context.Context) (*conn, error) {
+	p.dials++
+	if p.dialErr != nil {
+		return nil, p.dialErr
+	}
+	return &conn{}, nil
+}
+
+func (p *pool) Put(c *conn) {
+	p.idle = append(p.idle, c)
+}
+
+func (p *pool) Drain() {
+	p.drains++
+	for _, c := range p.idle {
+		c.closed = true
+	}
+	p.idle = nil
+}
+
+func TestGated(t *testing.T) {
+	b, err := easybreaker.New(
+		time.Minute, 2*time.Minute,
+		easybreaker.WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures >= 2 },
+			func(uint32, uint32) bool { return true },
+		),
+	)
+	assert.NoError(t, err)
+
+	p := &pool{}
+	g, err := New[*conn](p, b)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	c1, err := g.Get(ctx)
+	assert.NoError(t, err)
+	c2, _ := g.Get(ctx)
+	g.Put(c1)
+
+	// the handshakes fail
+	p.dialErr = errors.New("tls: handshake failure")
+	g.Get(ctx)
+	_, err = g.Get(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 1, p.drains)
+	assert.True(t, c1.closed)
+
+	_, err = g.Get(ctx)
+	assert.Equal(t, easybreaker.ErrBreakerOpen, err)
+	assert.Equal(t, 4, p.dials)
+
+	// the connection in use is drained once returned
+	g.Put(c2)
+	assert.True(t, c2.closed)
+
+	g.Close()
+	_, err = New[*conn](nil, b)
+	assert.Error(t, err)
+	_, err = New[*conn](p, nil)
+	assert.Error(t, err)
+}
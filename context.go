@@ -0,0 +1,126 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MinRemainingDeadline rejects the requests of ExecuteCtx with ErrDeadlineTooShort,
+// without counting them, when their context expires in less than d,
+// as they can't possibly succeed.
+func WithMinRemainingDeadline(d time.Duration) OptionCall {
+	return func(b *Breaker) error {
+		if d <= 0 {
+			return errors.New("circuit: min remaining deadline must be positive")
+		}
+		b.minRemainingDeadline = d
+		return nil
+	}
+}
+
+// CancelOnTrip cancels the contexts given to the requests of ExecuteCtx
+// the moment the breaker opens, so the doomed requests stop instead of
+// finishing after the trip.
+func WithCancelOnTrip() OptionCall {
+	return func(b *Breaker) error {
+		b.cancelOnTrip = true
+		b.cancels = make(map[uint64]context.CancelFunc)
+		return nil
+	}
+}
+
+// DeadlineFailures sets whether the requests of ExecuteCtx failing with
+// context.DeadlineExceeded once the deadline of their context expired are
+// counted as failures, the default. Otherwise they are ignored like the
+// errors of CategoryIgnored, for the callers whose deadlines are too short
+// to say anything about the health of the dependency.
+func WithDeadlineFailures(count bool) OptionCall {
+	return func(b *Breaker) error {
+		b.ignoreDeadlines = !count
+		return nil
+	}
+}
+
+// ExecuteCtx is Execute passing ctx to the request, it returns
+// context.Canceled if ctx is already canceled and context.DeadlineExceeded
+// if its deadline is already over, without counting the request.
+// The deadlines are compared with the clock of the breaker.
+func (b *Breaker) ExecuteCtx(ctx context.Context, req func(ctx context.Context) error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ctx.Err()
+	}
+	now := b.now()
+	budget, hasDeadline := remaining(ctx, now)
+	if hasDeadline && budget <= 0 {
+		return context.DeadlineExceeded
+	}
+	if hasDeadline && budget < b.minRemainingDeadline {
+		return ErrDeadlineTooShort
+	}
+
+	counted, err := b.allow(true)
+	if err != nil {
+		return err
+	}
+
+	if b.cancelOnTrip {
+		var release func()
+		ctx, release = b.trackCancel(ctx)
+		defer release()
+	}
+
+	start := b.begin()
+	start.uncounted = !counted
+	if b.chaosFailed() {
+		err = ErrChaos
+	} else {
+		err = req(ctx)
+	}
+	if b.deadlines != nil {
+		b.deadlines.observe(budget, hasDeadline, b.now().Sub(now))
+	}
+	if b.ignoreDeadlines && errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		start.ignored = true
+	}
+	b.finish(start, err)
+	return err
+}
+
+// remaining returns the time left before the deadline of ctx at now,
+// false if ctx has no deadline.
+func remaining(ctx context.Context, now time.Time) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return deadline.Sub(now), true
+}
+
+// trackCancel returns the context canceled on trip
+// and the function to stop tracking it.
+func (b *Breaker) trackCancel(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	b.cancelMu.Lock()
+	b.cancelSeq++
+	id := b.cancelSeq
+	b.cancels[id] = cancel
+	b.cancelMu.Unlock()
+
+	return ctx, func() {
+		b.cancelMu.Lock()
+		delete(b.cancels, id)
+		b.cancelMu.Unlock()
+		cancel()
+	}
+}
+
+func (b *Breaker) cancelInFlight() {
+	b.cancelMu.Lock()
+	for id, cancel := range b.cancels {
+		cancel()
+		delete(b.cancels, id)
+	}
+	b.cancelMu.Unlock()
+}
@@ -0,0 +1,136 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_ExecuteCtx(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	err = b.ExecuteCtx(ctx, func(ctx context.Context) error {
+		assert.Equal(t, "value", ctx.Value(key{}))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	failed := errors.New("failed")
+	err = b.ExecuteCtx(ctx, func(ctx context.Context) error { return failed })
+	assert.Equal(t, failed, err)
+	assert.Equal(t, StateOpen, b.State())
+
+	err = b.ExecuteCtx(ctx, func(ctx context.Context) error { return nil })
+	assert.Equal(t, ErrBreakerOpen, err)
+}
+
+func TestBreaker_CancelOnTrip(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithCancelOnTrip(),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	started := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := b.ExecuteCtx(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.Equal(t, context.Canceled, err)
+	}()
+
+	<-started
+	b.ExecuteCtx(context.Background(), func(ctx context.Context) error { return errors.New("failed") })
+	wg.Wait()
+
+	assert.Equal(t, StateOpen, b.State())
+	assert.Empty(t, b.cancels)
+}
+
+func TestBreaker_MinRemainingDeadline(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, WithMinRemainingDeadline(time.Second), withTime(1520100000))
+	assert.NoError(t, err)
+
+	// the deadlines are relative to the clock of the breaker
+	ts := time.Unix(1520100000, 0)
+	ctx, cancel := context.WithDeadline(context.Background(), ts.Add(100*time.Millisecond))
+	defer cancel()
+	executed := false
+	err = b.ExecuteCtx(ctx, func(ctx context.Context) error { executed = true; return nil })
+	assert.Equal(t, ErrDeadlineTooShort, err)
+	assert.False(t, executed)
+	assert.Equal(t, Counts{}, b.Counts())
+
+	ctx, cancel = context.WithDeadline(context.Background(), ts.Add(-time.Second))
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, b.ExecuteCtx(ctx, func(ctx context.Context) error { return nil }))
+	assert.Equal(t, Counts{}, b.Counts())
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assert.NoError(t, b.ExecuteCtx(ctx, func(ctx context.Context) error { return nil }))
+	assert.NoError(t, b.ExecuteCtx(context.Background(), func(ctx context.Context) error { return nil }))
+	assert.Equal(t, uint32(2), b.Counts().Total)
+
+	_, err = New(time.Minute, time.Minute, WithMinRemainingDeadline(0))
+	assert.Error(t, err)
+}
+
+func TestBreaker_ExecuteCtxDone(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran := false
+	err = b.ExecuteCtx(ctx, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, ran)
+	assert.Equal(t, Counts{}, b.Counts())
+}
+
+func TestBreaker_DeadlineFailures(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, WithDeadlineFailures(false), withTime(1520100000))
+	assert.NoError(t, err)
+
+	expire := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, b.ExecuteCtx(ctx, expire))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, Counts{Ignored: 1}, b.Counts())
+
+	// the timeouts of the request itself are still failures
+	b.ExecuteCtx(context.Background(), func(ctx context.Context) error { return context.DeadlineExceeded })
+	assert.Equal(t, StateOpen, b.State())
+
+	b, err = New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	b.ExecuteCtx(ctx, expire)
+	assert.Equal(t, StateOpen, b.State())
+}
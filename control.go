@@ -0,0 +1,190 @@
+package easybreaker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// the overrides of the state machine set by the operators
+const (
+	forcedNone int32 = iota
+	forcedOpen
+	forcedClosed
+)
+
+// auditSize is the number of the actions kept by History
+const auditSize = 64
+
+// ActionKind is a manual control action.
+type ActionKind string
+
+const (
+	ActionForceOpen ActionKind = "force-open" // the requests are rejected until Release
+	ActionDisable   ActionKind = "disable"    // the requests are accepted until Release
+	ActionRelease   ActionKind = "release"    // the breaker is back to its state machine
+	ActionTrip      ActionKind = "trip"       // the breaker was opened for a cooldown
+	ActionReset     ActionKind = "reset"      // the breaker was closed and its counts cleared
+)
+
+// Action is an entry of the audit trail of the manual control actions.
+type Action struct {
+	Time   time.Time  `json:"time"`
+	Kind   ActionKind `json:"kind"`
+	Who    string     `json:"who,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// ControlOption describes a manual control action in the audit trail.
+type ControlOption func(*Action)
+
+// By records who invoked the action.
+func By(who string) ControlOption {
+	return func(a *Action) {
+		a.Who = who
+	}
+}
+
+// Because records why the action was invoked.
+func Because(reason string) ControlOption {
+	return func(a *Action) {
+		a.Reason = reason
+	}
+}
+
+type audit struct {
+	mu      sync.Mutex
+	actions []Action // a ring of auditSize actions
+	next    int
+}
+
+func (a *audit) record(action Action) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.actions) < auditSize {
+		a.actions = append(a.actions, action)
+		return
+	}
+	a.actions[a.next] = action
+	a.next = (a.next + 1) % auditSize
+}
+
+// ForceOpen rejects the requests until Release, whatever the counts,
+// e.g. while the dependency is known to be down.
+func (b *Breaker) ForceOpen(opts ...ControlOption) {
+	b.control(ActionForceOpen, forcedOpen, opts)
+}
+
+// Disable accepts the requests until Release and never trips,
+// e.g. while the breaker is suspected to misbehave.
+func (b *Breaker) Disable(opts ...ControlOption) {
+	b.control(ActionDisable, forcedClosed, opts)
+}
+
+// Release hands the breaker back to its state machine
+// after ForceOpen or Disable.
+func (b *Breaker) Release(opts ...ControlOption) {
+	b.control(ActionRelease, forcedNone, opts)
+}
+
+// Trip opens the breaker for d, or the cooldown if d is not positive,
+// then it probes the dependency like after any trip, e.g. to remediate
+// an incident without waiting for the strategies. The transition guard
+// is bypassed, the overrides of ForceOpen and Disable still apply.
+func (b *Breaker) Trip(d time.Duration, opts ...ControlOption) {
+	if d <= 0 {
+		d = time.Duration(b.cooldown)
+	}
+	for {
+		until := atomic.LoadInt64(&b.until)
+		state := atomic.LoadInt32(&b.state)
+		if !atomic.CompareAndSwapInt64(&b.until, until, b.now().Add(d).UnixNano()) {
+			continue
+		}
+		if state != open {
+			total, failures := unpackCounts(atomic.LoadUint64(&b.counts))
+			b.setTripReason(TripReason{Strategy: StrategyManual}, total, failures)
+			b.transit(state, open)
+		}
+		break
+	}
+	b.recordAction(ActionTrip, opts)
+}
+
+// Reset closes the breaker and clears its counts, starting a new interval,
+// e.g. once a maintenance is over. The transition guard is bypassed,
+// the overrides of ForceOpen and Disable still apply.
+func (b *Breaker) Reset(opts ...ControlOption) {
+	for {
+		until := atomic.LoadInt64(&b.until)
+		state := atomic.LoadInt32(&b.state)
+		if !atomic.CompareAndSwapInt64(&b.until, until, b.now().UnixNano()+b.interval) {
+			continue
+		}
+		// a single window ends, the one of the transition if any
+		switch {
+		case state == closed:
+			b.reset(true)
+		case b.resetsOn(closed):
+			b.transit(state, closed)
+		default:
+			b.transit(state, closed)
+			b.zero()
+		}
+		b.velocity.reset()
+		if b.sliding != nil {
+			b.sliding.reset()
+		}
+		if b.acc != nil {
+			b.acc.Reset()
+		}
+		break
+	}
+	b.recordAction(ActionReset, opts)
+}
+
+func (b *Breaker) control(kind ActionKind, forced int32, opts []ControlOption) {
+	atomic.StoreInt32(&b.forced, forced)
+	if forced == forcedOpen {
+		atomic.CompareAndSwapInt64(&b.openedAt, 0, b.now().UnixNano())
+		total, failures := unpackCounts(atomic.LoadUint64(&b.counts))
+		b.setTripReason(TripReason{Strategy: StrategyForced}, total, failures)
+	} else if forced == forcedClosed || atomic.LoadInt32(&b.state) == closed {
+		atomic.StoreInt64(&b.openedAt, 0)
+	}
+	b.recordAction(kind, opts)
+}
+
+// OpenSince returns the time the breaker left the closed state,
+// false while it's closed. The probes of the half-open state
+// don't reset it until the breaker closes.
+func (b *Breaker) OpenSince() (time.Time, bool) {
+	if b.State() == StateClosed {
+		return time.Time{}, false
+	}
+	openedAt := atomic.LoadInt64(&b.openedAt)
+	if openedAt == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, openedAt), true
+}
+
+func (b *Breaker) recordAction(kind ActionKind, opts []ControlOption) {
+	action := Action{Time: b.now(), Kind: kind}
+	for _, opt := range opts {
+		opt(&action)
+	}
+	b.audit.record(action)
+}
+
+// History returns the audit trail of the manual control actions,
+// the oldest first, the last 64 actions are kept.
+func (b *Breaker) History() []Action {
+	b.audit.mu.Lock()
+	defer b.audit.mu.Unlock()
+
+	actions := make([]Action, 0, len(b.audit.actions))
+	actions = append(actions, b.audit.actions[b.audit.next:]...)
+	return append(actions, b.audit.actions[:b.audit.next]...)
+}
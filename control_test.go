@@ -0,0 +1,96 @@
+package easybreaker
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_ForceOpen(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+
+	b.ForceOpen(By("alice"), Because("INC-42"))
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, ErrBreakerOpen, b.Execute(func() error { return nil }))
+
+	b.Disable()
+	assert.Equal(t, StateClosed, b.State())
+	for i := 0; i < 10; i++ {
+		b.Execute(func() error { return errors.New("failed") })
+	}
+	assert.Equal(t, StateClosed, b.State())
+	assert.True(t, b.Debug().Disabled)
+
+	// the state machine takes over on the next failure
+	b.Release()
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+
+	assert.Equal(t, []Action{
+		{Time: time.Unix(1520100000, 0), Kind: ActionForceOpen, Who: "alice", Reason: "INC-42"},
+		{Time: time.Unix(1520100000, 0), Kind: ActionDisable},
+		{Time: time.Unix(1520100000, 0), Kind: ActionRelease},
+	}, b.History())
+}
+
+func TestBreaker_History(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	assert.Empty(t, b.History())
+
+	for i := 0; i < auditSize+10; i++ {
+		b.Release(Because(fmt.Sprint(i)))
+	}
+	history := b.History()
+	assert.Len(t, history, auditSize)
+	assert.Equal(t, "10", history[0].Reason)
+	assert.Equal(t, fmt.Sprint(auditSize+9), history[auditSize-1].Reason)
+}
+
+func TestBreaker_TripReset(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, WithLeastReqs(1), withTime(1520100000))
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return nil })
+	b.Trip(10*time.Minute, By("alice"), Because("INC-42"))
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, uint64(1), b.Trips())
+	reason, _ := b.TripReason()
+	assert.Equal(t, StrategyManual, reason.Strategy)
+
+	// the cooldown is the given duration
+	b.now = now(1520100121)
+	assert.Equal(t, ErrBreakerOpen, b.Execute(func() error { return nil }))
+
+	epoch := atomic.LoadUint64(&b.epoch)
+	b.Reset(By("bob"))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, Counts{}, b.Counts())
+	// a single window ends with the reset
+	assert.Equal(t, epoch+1, atomic.LoadUint64(&b.epoch))
+	assert.NoError(t, b.Execute(func() error { return nil }))
+
+	// the default cooldown
+	b.Trip(0)
+	b.now = now(1520100242)
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	// a reset while closed clears the counts
+	b.Reset()
+	b.Execute(func() error { return nil })
+	b.Reset()
+	assert.Equal(t, Counts{}, b.Counts())
+
+	var kinds []ActionKind
+	for _, a := range b.History() {
+		kinds = append(kinds, a.Kind)
+	}
+	assert.Equal(t, []ActionKind{ActionTrip, ActionReset, ActionTrip, ActionReset, ActionReset}, kinds)
+	assert.Equal(t, "alice", b.History()[0].Who)
+}
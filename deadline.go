@@ -0,0 +1,92 @@
+package easybreaker
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DeadlineUsageBuckets is the number of the buckets of DeadlineStats.Usage,
+// the bucket i counts the requests which spent [10*i, 10*i+10) percent of
+// their budget, the last one the requests which overran it.
+const DeadlineUsageBuckets = 11
+
+type deadlines struct {
+	budget     histogram
+	spend      histogram
+	usage      [DeadlineUsageBuckets]uint64
+	noDeadline uint64
+}
+
+// DeadlineStats is the distribution of the budgets of the requests of
+// ExecuteCtx, the remaining deadlines of their contexts at the admission,
+// against their spend, their durations.
+type DeadlineStats struct {
+	Budget     Histogram // the remaining deadlines at the admission
+	Spend      Histogram // the durations of the requests with a deadline
+	Usage      [DeadlineUsageBuckets]uint64
+	NoDeadline uint64 // the requests whose context has no deadline
+}
+
+// DeadlineHistogram measures the budget of the requests of ExecuteCtx, the
+// remaining deadline of their context at the admission, and their spend, to
+// tune the timeouts and to detect the deadline starvation, e.g. the budgets
+// shrunk by the retries of the callers upstream.
+func WithDeadlineHistogram() OptionCall {
+	return func(b *Breaker) error {
+		b.deadlines = &deadlines{}
+		return nil
+	}
+}
+
+// Deadlines returns the budgets and the spends of the requests of ExecuteCtx
+// since the breaker was created, false if WithDeadlineHistogram is not set.
+func (b *Breaker) Deadlines() (DeadlineStats, bool) {
+	d := b.deadlines
+	if d == nil {
+		return DeadlineStats{}, false
+	}
+	s := DeadlineStats{
+		Budget:     d.budget.snapshot(),
+		Spend:      d.spend.snapshot(),
+		NoDeadline: atomic.LoadUint64(&d.noDeadline),
+	}
+	for i := range d.usage {
+		s.Usage[i] = atomic.LoadUint64(&d.usage[i])
+	}
+	return s, true
+}
+
+// Overruns returns the ratio of the requests with a deadline which
+// spent their whole budget.
+func (s DeadlineStats) Overruns() float64 {
+	var count uint64
+	for _, n := range s.Usage {
+		count += n
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(s.Usage[DeadlineUsageBuckets-1]) / float64(count)
+}
+
+// observe measures a request whose context had the budget left at the
+// admission, if it had a deadline, and which took spend, both measured
+// with the clock of the breaker.
+func (d *deadlines) observe(budget time.Duration, hasDeadline bool, spend time.Duration) {
+	if !hasDeadline {
+		atomic.AddUint64(&d.noDeadline, 1)
+		return
+	}
+
+	if budget < 0 {
+		budget = 0
+	}
+	d.budget.observe(budget)
+	d.spend.observe(spend)
+
+	i := DeadlineUsageBuckets - 1
+	if spend < budget {
+		i = int(spend * 10 / budget)
+	}
+	atomic.AddUint64(&d.usage[i], 1)
+}
@@ -0,0 +1,51 @@
+package easybreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_Deadlines(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	_, ok := b.Deadlines()
+	assert.False(t, ok)
+
+	ts := time.Unix(1520100000, 0)
+	b, err = New(
+		time.Minute, time.Minute,
+		WithDeadlineHistogram(),
+		WithNow(func() time.Time { return ts }),
+	)
+	assert.NoError(t, err)
+
+	run := func(budget, spend time.Duration) {
+		ctx := context.Background()
+		if budget > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, ts.Add(budget))
+			defer cancel()
+		}
+		b.ExecuteCtx(ctx, func(ctx context.Context) error {
+			ts = ts.Add(spend)
+			return nil
+		})
+	}
+	run(100*time.Millisecond, 5*time.Millisecond)
+	run(100*time.Millisecond, 55*time.Millisecond)
+	run(time.Millisecond, 2*time.Millisecond)
+	run(0, time.Millisecond)
+
+	s, ok := b.Deadlines()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), s.NoDeadline)
+	assert.Equal(t, uint64(3), s.Budget.Count)
+	assert.Equal(t, 201*time.Millisecond, s.Budget.Sum)
+	assert.Equal(t, 62*time.Millisecond, s.Spend.Sum)
+	assert.Equal(t, [DeadlineUsageBuckets]uint64{0: 1, 5: 1, 10: 1}, s.Usage)
+	assert.InDelta(t, 1.0/3, s.Overruns(), 1e-9)
+	assert.Equal(t, 0.0, DeadlineStats{}.Overruns())
+}
@@ -0,0 +1,261 @@
+package easybreaker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DebugInfo is a dump of the internal state of a breaker for the bug reports,
+// the fields are read one by one and may be slightly inconsistent
+// while the breaker is in use. The settings of every option are dumped,
+// the zero values are the options not set.
+type DebugInfo struct {
+	Name    string
+	State   State
+	Tags    map[string]string
+	Impacts []string
+
+	// the configuration
+	Interval             time.Duration
+	Cooldown             time.Duration
+	AtLeastReqs          uint32
+	MaxFailures          uint32
+	FailureThreshold     float64
+	MinRemainingDeadline time.Duration
+	ResetPolicy          ResetPolicy
+	StatsSampling        uint32
+	SlidingWindow        int // the buckets of WithSlidingWindow
+	CountWindow          int // the size of WithCountWindow
+	TripGraceFailures    uint32
+	TripGracePeriod      time.Duration
+	SlowCallThreshold    time.Duration
+	SlowCallRatio        float64
+	WarningThreshold     float64
+	HangMaxInFlight      int64
+	HangMaxAge           time.Duration
+	HalfOpenPercent      float64
+	ProlongedOpen        time.Duration
+	ChaosFailureRate     float64
+	ChaosOpenRate        float64
+	ChaosOpenFor         time.Duration
+	PartialOpen          []string // the classes admitted while open, sorted
+	ClassBudgets         []string // the classes with a budget, sorted
+
+	// the mode flags
+	ForcedOpen        bool
+	Disabled          bool
+	StateFuncs        bool
+	Accumulator       bool
+	TimeoutFunc       bool
+	FailureVelocity   bool
+	SpikeDetection    bool
+	LatencyHistogram  bool
+	DeadlineHistogram bool
+	LoadFunc          bool
+	Taxonomy          bool
+	ErrorFilter       bool
+	ProbeSelector     bool
+	PassNonProbes     bool
+	TransitionGuard   bool
+	Maintenance       bool
+	Chaos             bool // the faults of WithChaos are injected
+	StartupProbe      bool
+	OpenError         bool
+	Strict            bool
+	CancelOnTrip      bool
+	DrainBeforeProbe  bool
+	SeedFromProbes    bool
+
+	// the raw counters
+	RawCounts   uint64 // total in the high 32 bits, failures in the low 32 bits
+	Counts      Counts
+	Epoch       uint64
+	Load        int
+	Sinks       int
+	MinSeverity Severity
+
+	// the timers
+	WindowStart time.Time
+	Until       time.Time // the end of the interval or the cooldown
+	Now         time.Time
+}
+
+// Debug returns the internal state of the breaker.
+func (b *Breaker) Debug() DebugInfo {
+	raw := atomic.LoadUint64(&b.counts)
+
+	d := DebugInfo{
+		Name:                 b.name,
+		Tags:                 b.Tags(),
+		Impacts:              b.Impacts(),
+		State:                b.State(),
+		Interval:             time.Duration(b.interval),
+		Cooldown:             time.Duration(b.cooldown),
+		AtLeastReqs:          b.atLeastReqs,
+		MaxFailures:          b.maxFailures,
+		FailureThreshold:     b.threshold,
+		MinRemainingDeadline: b.minRemainingDeadline,
+		ResetPolicy:          b.resetPolicy,
+		StatsSampling:        b.sampling,
+		ProlongedOpen:        time.Duration(b.prolonged),
+		PartialOpen:          classes(b.openClasses),
+		ForcedOpen:           atomic.LoadInt32(&b.forced) == forcedOpen,
+		Disabled:             atomic.LoadInt32(&b.forced) == forcedClosed,
+		StateFuncs:           b.stateFuncs,
+		Accumulator:          b.acc != nil,
+		TimeoutFunc:          b.timeoutToOpen != nil,
+		FailureVelocity:      b.velocity != nil,
+		SpikeDetection:       b.spike != nil,
+		LatencyHistogram:     b.latency != nil,
+		DeadlineHistogram:    b.deadlines != nil,
+		LoadFunc:             b.loadToOpen != nil,
+		Taxonomy:             b.taxonomy != nil,
+		ErrorFilter:          b.errorFilter != nil,
+		ProbeSelector:        b.isProbe != nil,
+		PassNonProbes:        b.passNonProbes,
+		TransitionGuard:      b.guard != nil,
+		Maintenance:          b.maintenance != nil,
+		StartupProbe:         b.startup != nil,
+		OpenError:            b.openError,
+		Strict:               b.strict,
+		CancelOnTrip:         b.cancelOnTrip,
+		DrainBeforeProbe:     b.drainBeforeProbe,
+		SeedFromProbes:       b.seedFromProbes,
+		RawCounts:            raw,
+		Counts:               b.current(),
+		Epoch:                atomic.LoadUint64(&b.epoch),
+		Load:                 b.Load(),
+		Sinks:                len(b.loadSinks()),
+		MinSeverity:          Severity(atomic.LoadInt32(&b.minSeverity)),
+		WindowStart:          time.Unix(0, atomic.LoadInt64(&b.windowStart)),
+		Until:                time.Unix(0, atomic.LoadInt64(&b.until)),
+		Now:                  b.now(),
+	}
+
+	switch w := b.sliding.(type) {
+	case *bucketRing:
+		d.SlidingWindow = len(w.buckets)
+	case *countRing:
+		d.CountWindow = len(w.slots)
+	}
+	if b.grace != nil {
+		d.TripGraceFailures, d.TripGracePeriod = b.grace.failures, time.Duration(b.grace.period)
+	}
+	if b.slowCall != nil {
+		d.SlowCallThreshold, d.SlowCallRatio = b.slowCall.threshold, b.slowCall.ratio
+	}
+	if b.pressure != nil {
+		d.WarningThreshold = b.pressure.ratio
+	}
+	if b.hang != nil {
+		d.HangMaxInFlight, d.HangMaxAge = b.hang.maxInFlight, b.hang.maxAge
+	}
+	if b.probeRate != nil {
+		d.HalfOpenPercent = b.probeRate.percent
+	}
+	if c := b.chaos; c != nil {
+		d.ChaosFailureRate, d.ChaosOpenRate, d.ChaosOpenFor = c.failureRate, c.openRate, time.Duration(c.openFor)
+		d.Chaos = atomic.LoadInt32(&c.enabled) != 0
+	}
+	if b.budgets != nil {
+		budgets := make(map[string]bool, len(b.budgets))
+		for class := range b.budgets {
+			budgets[class] = true
+		}
+		d.ClassBudgets = classes(budgets)
+	}
+	return d
+}
+
+// classes returns the sorted classes of the set.
+func classes(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(set))
+	for class := range set {
+		names = append(names, class)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DebugString returns the internal state of the breaker, a field per line.
+func (b *Breaker) DebugString() string {
+	return b.Debug().String()
+}
+
+func (d DebugInfo) String() string {
+	var sb strings.Builder
+	line := func(key string, value interface{}) {
+		fmt.Fprintf(&sb, "%-22s %v\n", key+":", value)
+	}
+	format := func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	line("name", fmt.Sprintf("%q", d.Name))
+	line("state", d.State)
+	line("tags", d.Tags)
+	line("impacts", d.Impacts)
+	line("interval", d.Interval)
+	line("cooldown", d.Cooldown)
+	line("at least reqs", d.AtLeastReqs)
+	line("max failures", d.MaxFailures)
+	line("failure threshold", d.FailureThreshold)
+	line("min remaining deadline", d.MinRemainingDeadline)
+	line("reset policy", fmt.Sprintf("%03b", d.ResetPolicy))
+	line("stats sampling", d.StatsSampling)
+	line("sliding window", d.SlidingWindow)
+	line("count window", d.CountWindow)
+	line("trip grace", fmt.Sprintf("%d failures, %s", d.TripGraceFailures, d.TripGracePeriod))
+	line("slow call", fmt.Sprintf("%s, ratio %g", d.SlowCallThreshold, d.SlowCallRatio))
+	line("warning threshold", d.WarningThreshold)
+	line("hang detection", fmt.Sprintf("%d in flight, %s", d.HangMaxInFlight, d.HangMaxAge))
+	line("half-open percent", d.HalfOpenPercent)
+	line("prolonged open", d.ProlongedOpen)
+	line("chaos", fmt.Sprintf("%t, failure rate %g, open rate %g for %s", d.Chaos, d.ChaosFailureRate, d.ChaosOpenRate, d.ChaosOpenFor))
+	line("partial open", d.PartialOpen)
+	line("class budgets", d.ClassBudgets)
+	line("forced open", d.ForcedOpen)
+	line("disabled", d.Disabled)
+	line("state funcs", d.StateFuncs)
+	line("accumulator", d.Accumulator)
+	line("timeout func", d.TimeoutFunc)
+	line("failure velocity", d.FailureVelocity)
+	line("spike detection", d.SpikeDetection)
+	line("latency histogram", d.LatencyHistogram)
+	line("deadline histogram", d.DeadlineHistogram)
+	line("load func", d.LoadFunc)
+	line("taxonomy", d.Taxonomy)
+	line("error filter", d.ErrorFilter)
+	line("probe selector", d.ProbeSelector)
+	line("pass non-probes", d.PassNonProbes)
+	line("transition guard", d.TransitionGuard)
+	line("maintenance", d.Maintenance)
+	line("startup probe", d.StartupProbe)
+	line("open error", d.OpenError)
+	line("strict", d.Strict)
+	line("cancel on trip", d.CancelOnTrip)
+	line("drain before probe", d.DrainBeforeProbe)
+	line("seed from probes", d.SeedFromProbes)
+	line("raw counts", fmt.Sprintf("%#016x", d.RawCounts))
+	line("total", d.Counts.Total)
+	line("failures", d.Counts.Failures)
+	line("successes", d.Counts.Successes)
+	line("timeouts", d.Counts.Timeouts)
+	line("ignored", d.Counts.Ignored)
+	line("slow", d.Counts.Slow)
+	line("in flight", d.Counts.InFlight)
+	line("epoch", d.Epoch)
+	line("load", d.Load)
+	line("sinks", d.Sinks)
+	line("min severity", d.MinSeverity)
+	line("window start", format(d.WindowStart))
+	line("until", format(d.Until))
+	line("now", format(d.Now))
+	return sb.String()
+}
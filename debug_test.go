@@ -0,0 +1,70 @@
+package easybreaker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_Debug(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithName("api"),
+		WithMaxFailures(10),
+		WithStateFunc(
+			func(uint32, uint32) bool { return false },
+			func(uint32, uint32) bool { return true },
+		),
+		WithDrainBeforeProbe(),
+		WithSink(SeverityWarn, func(Event) {}),
+		WithCountWindow(10),
+		WithTripGrace(3, 5*time.Second),
+		WithHalfOpenPercent(50),
+		WithPartialOpen(ClassRead),
+		WithClassBudget(ClassWrite, func(uint32, uint32) bool { return false }),
+		WithImpacts("checkout"),
+		WithProlongedOpen(time.Hour),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return errors.New("failed") })
+
+	d := b.Debug()
+	assert.Equal(t, "api", d.Name)
+	assert.Equal(t, StateClosed, d.State)
+	assert.Equal(t, time.Minute, d.Interval)
+	assert.Equal(t, 2*time.Minute, d.Cooldown)
+	assert.Equal(t, uint32(10), d.MaxFailures)
+	assert.Equal(t, DefaultResetPolicy, d.ResetPolicy)
+	assert.True(t, d.DrainBeforeProbe)
+	assert.True(t, d.StateFuncs)
+	assert.False(t, d.Accumulator)
+	assert.Equal(t, 10, d.CountWindow)
+	assert.Equal(t, 0, d.SlidingWindow)
+	assert.Equal(t, uint32(3), d.TripGraceFailures)
+	assert.Equal(t, 5*time.Second, d.TripGracePeriod)
+	assert.Equal(t, float64(50), d.HalfOpenPercent)
+	assert.Equal(t, []string{ClassRead}, d.PartialOpen)
+	assert.Equal(t, []string{ClassWrite}, d.ClassBudgets)
+	assert.Equal(t, []string{"checkout"}, d.Impacts)
+	assert.Equal(t, time.Hour, d.ProlongedOpen)
+	assert.False(t, d.CancelOnTrip)
+	assert.Equal(t, packCounts(2, 1), d.RawCounts)
+	assert.Equal(t, Counts{Total: 2, Failures: 1, Successes: 1}, d.Counts)
+	assert.Equal(t, 1, d.Sinks)
+	assert.Equal(t, SeverityWarn, d.MinSeverity)
+	assert.Equal(t, time.Unix(1520100060, 0), d.Until)
+
+	s := b.DebugString()
+	assert.True(t, strings.Contains(s, `name:                  "api"`))
+	assert.True(t, strings.Contains(s, "raw counts:            0x0000000200000001\n"))
+	assert.True(t, strings.Contains(s, "timeouts:              0\n"))
+	assert.True(t, strings.Contains(s, "until:                 2018-03-03T18:01:00Z\n"))
+	assert.True(t, strings.Contains(s, "trip grace:            3 failures, 5s\n"))
+	assert.True(t, strings.Contains(s, "class budgets:         [write]\n"))
+}
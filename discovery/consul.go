@@ -0,0 +1,94 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Consul is a Source following the healthy instances of a service
+// with the blocking queries of the Consul HTTP API.
+type Consul struct {
+	addr    string
+	service string
+	client  *http.Client
+	index   uint64
+}
+
+// NewConsul returns a source of the instances of service registered
+// in the Consul agent at addr, e.g. http://127.0.0.1:8500.
+func NewConsul(addr string, service string) *Consul {
+	return &Consul{
+		addr:    strings.TrimSuffix(addr, "/"),
+		service: service,
+		client:  http.DefaultClient,
+	}
+}
+
+type consulEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		ID      string
+		Address string
+		Port    int
+	}
+}
+
+// Next implements Source.
+func (c *Consul) Next(ctx context.Context) ([]Instance, error) {
+	query := url.Values{"passing": {"1"}}
+	if c.index > 0 {
+		query.Set("index", strconv.FormatUint(c.index, 10))
+		query.Set("wait", "5m")
+	}
+	u := fmt.Sprintf("%s/v1/health/service/%s?%s", c.addr, url.PathEscape(c.service), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery: consul responded %s", resp.Status)
+	}
+
+	var entries []consulEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	index, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: invalid consul index: %v", err)
+	}
+	// the index going backwards resets the blocking queries
+	if index < c.index {
+		index = 0
+	}
+	c.index = index
+
+	instances := make([]Instance, 0, len(entries))
+	for _, e := range entries {
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		instances = append(instances, Instance{
+			ID:      e.Service.ID,
+			Address: net.JoinHostPort(host, strconv.Itoa(e.Service.Port)),
+		})
+	}
+	return instances, nil
+}
@@ -0,0 +1,46 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsul(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/health/service/payments", r.URL.Path)
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("X-Consul-Index", "42")
+		w.Write([]byte(`[
+			{"Node": {"Address": "10.0.0.1"}, "Service": {"ID": "payments-1", "Address": "", "Port": 8080}},
+			{"Node": {"Address": "10.0.0.2"}, "Service": {"ID": "payments-2", "Address": "192.168.0.2", "Port": 8080}}
+		]`))
+	}))
+	defer srv.Close()
+
+	c := NewConsul(srv.URL+"/", "payments")
+	instances, err := c.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Instance{
+		{ID: "payments-1", Address: "10.0.0.1:8080"},
+		{ID: "payments-2", Address: "192.168.0.2:8080"},
+	}, instances)
+
+	// the following queries block on the index
+	c.Next(context.Background())
+	assert.Equal(t, []string{"passing=1", "index=42&passing=1&wait=5m"}, queries)
+}
+
+func TestConsul_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := NewConsul(srv.URL, "payments").Next(context.Background())
+	assert.EqualError(t, err, "discovery: consul responded 500 Internal Server Error")
+}
@@ -0,0 +1,132 @@
+// Package discovery maintains a breaker per instance of a service found by
+// a discovery source, the breakers are created and removed as the instances
+// come and go:
+//
+//	w, err := discovery.New(discovery.NewConsul("http://127.0.0.1:8500", "payments"), time.Minute, 10*time.Second)
+//	go w.Run(ctx)
+//	b, ok := w.Breaker("10.0.0.1:8080")
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// retry is the delay before calling the source again after an error
+const retry = time.Second
+
+// Instance is an instance of a service.
+type Instance struct {
+	ID      string
+	Address string // host:port, the name of the breaker of the instance
+}
+
+// Source is a discovery source.
+type Source interface {
+	// Next blocks until the instances changed since the previous call,
+	// or ctx is done, and returns all the current instances.
+	// The first call returns immediately.
+	Next(ctx context.Context) ([]Instance, error)
+}
+
+// Watcher maintains the breakers of the instances of a source.
+type Watcher struct {
+	source   Source
+	registry *easybreaker.Registry
+
+	mu        sync.RWMutex
+	instances []Instance
+	err       error // the last error of the source
+}
+
+// New returns a watcher creating the breakers with the given settings.
+func New(source Source, interval time.Duration, cooldown time.Duration, fns ...easybreaker.OptionCall) (*Watcher, error) {
+	if source == nil {
+		return nil, errors.New("discovery: source must be set")
+	}
+	// validate the settings once
+	if _, err := easybreaker.New(interval, cooldown, fns...); err != nil {
+		return nil, err
+	}
+	return &Watcher{source: source, registry: easybreaker.NewRegistry(interval, cooldown, fns...)}, nil
+}
+
+// Run follows the source until ctx is done, the errors of the source
+// are retried and reported by Err.
+func (w *Watcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		instances, err := w.source.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			w.mu.Lock()
+			w.err = err
+			w.mu.Unlock()
+
+			timer := time.NewTimer(retry)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		w.update(instances)
+	}
+}
+
+// update creates the breakers of the new instances and removes the ones
+// of the gone instances.
+func (w *Watcher) update(instances []Instance) {
+	current := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		current[instance.Address] = true
+		w.registry.Get(instance.Address)
+	}
+	for _, addr := range w.registry.Names() {
+		if !current[addr] {
+			w.registry.Remove(addr)
+		}
+	}
+
+	sorted := append([]Instance(nil), instances...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Address < sorted[j].Address })
+
+	w.mu.Lock()
+	w.instances = sorted
+	w.err = nil
+	w.mu.Unlock()
+}
+
+// Instances returns the current instances sorted by address.
+func (w *Watcher) Instances() []Instance {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return append([]Instance(nil), w.instances...)
+}
+
+// Breaker returns the breaker of the instance with the address,
+// false if the instance is unknown.
+func (w *Watcher) Breaker(addr string) (*easybreaker.Breaker, bool) {
+	return w.registry.Lookup(addr)
+}
+
+// Registry returns the registry of the breakers of the instances,
+// e.g. for the admin handler.
+func (w *Watcher) Registry() *easybreaker.Registry {
+	return w.registry
+}
+
+// Err returns the last error of the source, nil once it recovered.
+func (w *Watcher) Err() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.err
+}
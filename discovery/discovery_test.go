@@ -0,0 +1,59 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type source struct {
+	updates chan []Instance
+	errs    chan error
+}
+
+func (s *source) Next(ctx context.Context) ([]Instance, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-s.errs:
+		return nil, err
+	case instances := <-s.updates:
+		return instances, nil
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	src := &source{updates: make(chan []Instance), errs: make(chan error)}
+	w, err := New(src, time.Minute, time.Minute)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	src.updates <- []Instance{{ID: "b", Address: "10.0.0.2:80"}, {ID: "a", Address: "10.0.0.1:80"}}
+	src.errs <- errors.New("consul down")
+	src.updates <- []Instance{{ID: "b", Address: "10.0.0.2:80"}, {ID: "c", Address: "10.0.0.3:80"}}
+	cancel()
+	<-done
+
+	assert.Equal(t, []Instance{{ID: "b", Address: "10.0.0.2:80"}, {ID: "c", Address: "10.0.0.3:80"}}, w.Instances())
+	assert.Equal(t, []string{"10.0.0.2:80", "10.0.0.3:80"}, w.Registry().Names())
+	_, ok := w.Breaker("10.0.0.1:80")
+	assert.False(t, ok)
+	b, ok := w.Breaker("10.0.0.3:80")
+	assert.True(t, ok)
+	assert.NoError(t, b.Allow())
+	assert.NoError(t, w.Err())
+
+	_, err = New(nil, time.Minute, time.Minute)
+	assert.Error(t, err)
+	_, err = New(src, 0, time.Minute)
+	assert.Error(t, err)
+}
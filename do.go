@@ -0,0 +1,32 @@
+package easybreaker
+
+import (
+	"context"
+)
+
+// Do is Execute for a request returning a value, e.g. a client call:
+//
+//	user, err := easybreaker.Do(b, func() (*User, error) { return client.GetUser(id) })
+//
+// The value is the zero value when the breaker rejects the request,
+// the value returned by req otherwise, even along with an error.
+func Do[T any](b *Breaker, req func() (T, error)) (T, error) {
+	var value T
+	err := b.Execute(func() error {
+		var err error
+		value, err = req()
+		return err
+	})
+	return value, err
+}
+
+// DoCtx is ExecuteCtx for a request returning a value.
+func DoCtx[T any](ctx context.Context, b *Breaker, req func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	err := b.ExecuteCtx(ctx, func(ctx context.Context) error {
+		var err error
+		value, err = req(ctx)
+		return err
+	})
+	return value, err
+}
@@ -0,0 +1,28 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithFailureThreshold(1), withTime(1520100000))
+	assert.NoError(t, err)
+
+	n, err := Do(b, func() (int, error) { return 42, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 42, n)
+
+	s, err := DoCtx(context.Background(), b, func(ctx context.Context) (string, error) { return "partial", errors.New("failed") })
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, "partial", s)
+
+	b.ForceOpen()
+	n, err = Do(b, func() (int, error) { return 42, nil })
+	assert.Equal(t, ErrBreakerOpen, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,64 @@
+package easybreaker
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// DrainBeforeProbe keeps the breaker open after the cooldown period until
+// the requests accepted before the trip are finished, so the stragglers
+// don't count against the probes of the half-open state.
+// The requests must be bounded by timeouts, the breaker stays open otherwise.
+func WithDrainBeforeProbe() OptionCall {
+	return func(b *Breaker) error {
+		b.drainBeforeProbe = true
+		return nil
+	}
+}
+
+// WaitDrained blocks until no request is in flight or ctx is done,
+// e.g. to let the requests finish on shutdown once the breaker tripped.
+func (b *Breaker) WaitDrained(ctx context.Context) error {
+	b.drainMu.Lock()
+	// announce the waiter before checking the requests,
+	// so the last finishing request can't miss it
+	atomic.AddInt32(&b.drainWaiting, 1)
+	if atomic.LoadUint32(&b.inFlight) == 0 {
+		atomic.AddInt32(&b.drainWaiting, -1)
+		b.drainMu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	b.drainWaiters = append(b.drainWaiters, ch)
+	b.drainMu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		b.drainMu.Lock()
+		for i, waiter := range b.drainWaiters {
+			if waiter == ch {
+				b.drainWaiters = append(b.drainWaiters[:i], b.drainWaiters[i+1:]...)
+				atomic.AddInt32(&b.drainWaiting, -1)
+				break
+			}
+		}
+		b.drainMu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (b *Breaker) notifyDrained() {
+	b.drainMu.Lock()
+	defer b.drainMu.Unlock()
+
+	if atomic.LoadUint32(&b.inFlight) > 0 {
+		return
+	}
+	for _, ch := range b.drainWaiters {
+		close(ch)
+	}
+	atomic.AddInt32(&b.drainWaiting, -int32(len(b.drainWaiters)))
+	b.drainWaiters = nil
+}
@@ -0,0 +1,70 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_WaitDrained(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	assert.NoError(t, b.WaitDrained(context.Background()))
+
+	assert.NoError(t, b.Allow())
+	assert.NoError(t, b.Allow())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, b.WaitDrained(ctx))
+	assert.Empty(t, b.drainWaiters)
+
+	done := make(chan error)
+	go func() {
+		done <- b.WaitDrained(context.Background())
+	}()
+
+	b.Done(nil)
+	select {
+	case <-done:
+		t.Fatal("drained with a request in flight")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	b.Done(nil)
+	assert.NoError(t, <-done)
+	assert.Equal(t, int32(0), b.drainWaiting)
+}
+
+func TestBreaker_DrainBeforeProbe(t *testing.T) {
+	var trip Event
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithLeastReqs(1),
+		WithDrainBeforeProbe(),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return true },
+		),
+		WithSink(SeverityWarn, func(e Event) { trip = e }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	// a straggler is in flight when the breaker trips
+	assert.NoError(t, b.Allow())
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, uint32(1), trip.Counts.InFlight)
+
+	b.now = now(1520100121)
+	assert.Equal(t, ErrBreakerOpen, b.Execute(func() error { return nil }))
+
+	b.Done(errors.New("failed"))
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.Equal(t, Counts{Total: 1, Successes: 1}, b.Counts())
+}
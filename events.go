@@ -0,0 +1,219 @@
+package easybreaker
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Severity is the importance of an event.
+type Severity int32
+
+const (
+	SeverityDebug Severity = iota // the requests are admitted or rejected
+	SeverityInfo                  // the interval rolled over, the breaker is half-open or closed
+	SeverityWarn                  // the breaker tripped or is approaching the trip
+	SeverityError                 // the breaker stays open past the threshold of WithProlongedOpen
+
+	severityNone // no sinks
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// EventType is the kind of an event.
+type EventType int32
+
+const (
+	EventAdmitted    EventType = iota // a request is accepted
+	EventRejected                     // a request is rejected with ErrBreakerOpen
+	EventRollover                     // the interval of the closed state elapsed
+	EventStateChange                  // the breaker moved from a state to another one
+	EventWarning                      // the failures of the closed state reached the warning threshold
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdmitted:
+		return "admitted"
+	case EventRejected:
+		return "rejected"
+	case EventRollover:
+		return "rollover"
+	case EventStateChange:
+		return "state-change"
+	case EventWarning:
+		return "warning"
+	}
+	return "unknown"
+}
+
+// Event is emitted by the breaker to the sinks.
+type Event struct {
+	Name     string            // the name of the breaker
+	Tags     map[string]string // the tags of the breaker, must not be modified
+	Type     EventType
+	Severity Severity
+	Time     time.Time
+
+	// From and To are the states of a state change
+	From State
+	To   State
+
+	// Counts are the requests of the finished interval or state,
+	// of a rollover or a state change
+	Counts Counts
+
+	// Reason is why the breaker opened, of a state change to the open state
+	Reason *TripReason
+}
+
+func (e Event) String() string {
+	switch e.Type {
+	case EventStateChange:
+		return fmt.Sprintf("[%s] breaker %q %s -> %s, total=%d failures=%d", e.Severity, e.Name, e.From, e.To, e.Counts.Total, e.Counts.Failures)
+	case EventRollover:
+		return fmt.Sprintf("[%s] breaker %q rollover, total=%d failures=%d", e.Severity, e.Name, e.Counts.Total, e.Counts.Failures)
+	case EventWarning:
+		return fmt.Sprintf("[%s] breaker %q approaching the trip, total=%d failures=%d", e.Severity, e.Name, e.Counts.Total, e.Counts.Failures)
+	}
+	return fmt.Sprintf("[%s] breaker %q %s", e.Severity, e.Name, e.Type)
+}
+
+type sink struct {
+	id       uint64
+	severity Severity
+	fn       func(Event)
+}
+
+// Sink subscribes fn to the events with the given severity or above,
+// fn is called synchronously by the requests and must not block.
+// The debug events are emitted on every request, subscribe to them with care.
+func WithSink(severity Severity, fn func(Event)) OptionCall {
+	return func(b *Breaker) error {
+		_, err := b.Subscribe(severity, fn)
+		return err
+	}
+}
+
+// OnStateChange is called synchronously by the request moving the breaker
+// from a state to another one, e.g. to log, alert or export the transitions
+// without a sink, it must not block. The overrides of ForceOpen and Disable
+// are not transitions.
+func WithOnStateChange(fn func(from, to State)) OptionCall {
+	return func(b *Breaker) error {
+		if fn == nil {
+			return errors.New("circuit: onStateChange must be defined")
+		}
+		b.onChange = fn
+		return nil
+	}
+}
+
+// ProlongedOpen raises the severity of the breaker failing the probes to
+// SeverityError once it stays open for the duration, e.g. to page on an
+// outage instead of a blip. The trips, including the failed probes before
+// the duration, are warnings.
+func WithProlongedOpen(after time.Duration) OptionCall {
+	return func(b *Breaker) error {
+		if after <= 0 {
+			return errors.New("circuit: prolonged open must be positive")
+		}
+		b.prolonged = int64(after)
+		return nil
+	}
+}
+
+// openedFor reports whether the breaker left the closed state for the
+// nanoseconds at least, false if d is 0.
+func (b *Breaker) openedFor(d int64) bool {
+	if d == 0 {
+		return false
+	}
+	at := atomic.LoadInt64(&b.openedAt)
+	return at != 0 && b.now().UnixNano()-at >= d
+}
+
+// Subscribe attaches fn to the events with the given severity or above
+// at runtime, e.g. once an exporter is started, and returns the function
+// detaching it. The events are not even built while nobody listens.
+func (b *Breaker) Subscribe(severity Severity, fn func(Event)) (func(), error) {
+	if fn == nil {
+		return nil, errors.New("circuit: sink must be defined")
+	}
+	if severity < SeverityDebug || severity > SeverityError {
+		return nil, errors.New("circuit: invalid severity")
+	}
+
+	b.sinkMu.Lock()
+	defer b.sinkMu.Unlock()
+
+	b.sinkSeq++
+	id := b.sinkSeq
+	b.storeSinks(append(b.loadSinks(), sink{id: id, severity: severity, fn: fn}))
+
+	return func() {
+		b.sinkMu.Lock()
+		defer b.sinkMu.Unlock()
+
+		var sinks []sink
+		for _, s := range b.loadSinks() {
+			if s.id != id {
+				sinks = append(sinks, s)
+			}
+		}
+		b.storeSinks(sinks)
+	}, nil
+}
+
+func (b *Breaker) loadSinks() []sink {
+	sinks, _ := b.sinks.Load().([]sink)
+	return sinks
+}
+
+// storeSinks replaces the sinks, the caller must hold sinkMu.
+// The slice is copied so the emitting requests never see it change.
+func (b *Breaker) storeSinks(sinks []sink) {
+	min := severityNone
+	copied := make([]sink, len(sinks))
+	for i, s := range sinks {
+		copied[i] = s
+		if s.severity < min {
+			min = s.severity
+		}
+	}
+	b.sinks.Store(copied)
+	atomic.StoreInt32(&b.minSeverity, int32(min))
+}
+
+// listening reports whether a sink is subscribed to the severity.
+func (b *Breaker) listening(severity Severity) bool {
+	return severity >= Severity(atomic.LoadInt32(&b.minSeverity))
+}
+
+func (b *Breaker) emit(e Event) {
+	if !b.listening(e.Severity) {
+		return
+	}
+
+	e.Name = b.name
+	e.Tags = b.Tags()
+	e.Time = b.now()
+	for _, s := range b.loadSinks() {
+		if e.Severity >= s.severity {
+			s.fn(e)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_Events(t *testing.T) {
+	var warnings, all []Event
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithName("api"),
+		WithLeastReqs(1),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+		WithSink(SeverityWarn, func(e Event) { warnings = append(warnings, e) }),
+		WithSink(SeverityDebug, func(e Event) { all = append(all, e) }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return nil })
+	b.now = now(1520100061)
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return nil })
+
+	// the probe fails
+	b.now = now(1520100182)
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return nil })
+
+	var types []EventType
+	for _, e := range all {
+		types = append(types, e.Type)
+		assert.Equal(t, "api", e.Name)
+	}
+	assert.Equal(t, []EventType{
+		EventAdmitted,
+		EventRollover, EventAdmitted, EventStateChange, EventRejected,
+		EventStateChange, EventAdmitted, EventStateChange, EventRejected,
+	}, types)
+
+	assert.Equal(t, Event{
+		Name: "api", Type: EventRollover, Severity: SeverityInfo, Time: time.Unix(1520100061, 0),
+		From: StateClosed, To: StateClosed, Counts: Counts{Total: 1, Successes: 1},
+	}, all[1])
+
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, SeverityWarn, warnings[0].Severity)
+	assert.Equal(t, StateClosed, warnings[0].From)
+	assert.Equal(t, Counts{Total: 1, Failures: 1}, warnings[0].Counts)
+	assert.Equal(t, SeverityWarn, warnings[1].Severity)
+	assert.Equal(t, StateHalfOpen, warnings[1].From)
+	assert.Equal(t, `[warn] breaker "api" half-open -> open, total=1 failures=1`, warnings[1].String())
+}
+
+func TestWithProlongedOpen(t *testing.T) {
+	_, err := New(time.Minute, time.Minute, WithProlongedOpen(0))
+	assert.Error(t, err)
+
+	var errs []Event
+	b, err := New(
+		time.Minute, time.Minute,
+		WithLeastReqs(1),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+		WithProlongedOpen(2*time.Minute),
+		WithSink(SeverityError, func(e Event) { errs = append(errs, e) }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	// the first probe fails a minute after the trip
+	b.now = now(1520100060)
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Empty(t, errs)
+
+	// the breaker is open for two minutes
+	b.now = now(1520100120)
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Len(t, errs, 1)
+	assert.Equal(t, StateHalfOpen, errs[0].From)
+	assert.Equal(t, StateOpen, errs[0].To)
+}
+
+func TestWithSink(t *testing.T) {
+	_, err := New(time.Minute, time.Minute, WithSink(SeverityInfo, nil))
+	assert.Error(t, err)
+
+	_, err = New(time.Minute, time.Minute, WithSink(severityNone, func(Event) {}))
+	assert.Error(t, err)
+}
+
+func TestBreaker_Subscribe(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	assert.False(t, b.listening(SeverityError))
+
+	var debug, warnings int
+	unsubscribe, err := b.Subscribe(SeverityDebug, func(Event) { debug++ })
+	assert.NoError(t, err)
+	_, err = b.Subscribe(SeverityWarn, func(Event) { warnings++ })
+	assert.NoError(t, err)
+	assert.True(t, b.listening(SeverityDebug))
+
+	b.Execute(func() error { return nil })
+	assert.Equal(t, 1, debug)
+
+	unsubscribe()
+	assert.False(t, b.listening(SeverityInfo))
+	assert.True(t, b.listening(SeverityWarn))
+	b.Execute(func() error { return nil })
+	assert.Equal(t, 1, debug)
+	assert.Equal(t, 0, warnings)
+
+	_, err = b.Subscribe(SeverityDebug, nil)
+	assert.Error(t, err)
+	_, err = b.Subscribe(severityNone, func(Event) {})
+	assert.Error(t, err)
+}
+
+func TestBreaker_OnStateChange(t *testing.T) {
+	var changes [][2]State
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithLeastReqs(1),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(total uint32, failures uint32) bool { return failures == 0 },
+		),
+		WithOnStateChange(func(from, to State) { changes = append(changes, [2]State{from, to}) }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	b.ForceOpen()
+	b.Release()
+	b.now = now(1520100121)
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+
+	assert.Equal(t, [][2]State{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateClosed},
+	}, changes)
+
+	_, err = New(time.Minute, time.Minute, WithOnStateChange(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package easybreaker
+
+import "errors"
+
+// errNoFallback is returned by ExecuteWithFallback without a fallback
+var errNoFallback = errors.New("circuit: fallback must be defined")
+
+// FallbackError is returned along with the fallback value,
+// it wraps ErrBreakerOpen or the error of the request.
+type FallbackError struct {
+	Err error
+}
+
+func (e *FallbackError) Error() string {
+	return "circuit: fallback value used: " + e.Err.Error()
+}
+
+func (e *FallbackError) Unwrap() error {
+	return e.Err
+}
+
+// DoWithFallbackValue runs fn with the breaker and returns its value,
+// or fallback along with a *FallbackError when the breaker rejects
+// the request or fn fails. It suits the read paths where stale
+// or default data is acceptable:
+//
+//	rate, err := easybreaker.DoWithFallbackValue(b, fetchRate, cachedRate)
+//	var fe *easybreaker.FallbackError
+//	if errors.As(err, &fe) {
+//		log.Printf("serving cached rate: %v", fe.Err)
+//	}
+func DoWithFallbackValue[T any](b *Breaker, fn func() (T, error), fallback T) (T, error) {
+	value, err := Do(b, fn)
+	if err != nil {
+		return fallback, &FallbackError{Err: err}
+	}
+	return value, nil
+}
+
+// ExecuteWithFallback is Execute running fallback with the error when the
+// breaker rejects the request or req fails, the error of fallback is
+// returned, nil if it recovered. Nothing is run without a fallback,
+// an error is returned:
+//
+//	err := b.ExecuteWithFallback(sendEmail, func(err error) error {
+//		return queue.Push(email)
+//	})
+func (b *Breaker) ExecuteWithFallback(req func() error, fallback func(err error) error) error {
+	if fallback == nil {
+		return errNoFallback
+	}
+	if err := b.Execute(req); err != nil {
+		return fallback(err)
+	}
+	return nil
+}
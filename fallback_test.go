@@ -0,0 +1,73 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoWithFallbackValue(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	v, err := DoWithFallbackValue(b, func() (int, error) { return 1, nil }, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	failed := errors.New("failed")
+	v, err = DoWithFallbackValue(b, func() (int, error) { return 0, failed }, -1)
+	assert.Equal(t, -1, v)
+	var fe *FallbackError
+	assert.True(t, errors.As(err, &fe))
+	assert.True(t, errors.Is(err, failed))
+
+	v, err = DoWithFallbackValue(b, func() (int, error) { return 1, nil }, -1)
+	assert.Equal(t, -1, v)
+	assert.True(t, errors.Is(err, ErrBreakerOpen))
+	assert.EqualError(t, err, "circuit: fallback value used: circuit: breaker open")
+}
+
+func TestBreaker_ExecuteWithFallback(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	var errs []error
+	fallback := func(err error) error {
+		errs = append(errs, err)
+		return nil
+	}
+
+	assert.NoError(t, b.ExecuteWithFallback(func() error { return nil }, fallback))
+	assert.Empty(t, errs)
+
+	failed := errors.New("failed")
+	assert.NoError(t, b.ExecuteWithFallback(func() error { return failed }, fallback))
+	assert.NoError(t, b.ExecuteWithFallback(func() error { return nil }, fallback))
+	assert.Equal(t, []error{failed, ErrBreakerOpen}, errs)
+
+	// the fallback fails as well
+	queueFull := errors.New("queue full")
+	err = b.ExecuteWithFallback(func() error { return nil }, func(error) error { return queueFull })
+	assert.Equal(t, queueFull, err)
+
+	executed := false
+	err = b.ExecuteWithFallback(func() error { executed = true; return nil }, nil)
+	assert.EqualError(t, err, "circuit: fallback must be defined")
+	assert.False(t, executed)
+}
@@ -0,0 +1,142 @@
+// Package fanout runs the sub-calls of a fan-out, like errgroup.Group, each one
+// admitted through its own breaker of a registry. The sub-calls rejected by
+// an open breaker are skipped, the others still run, unless the breaker is
+// critical: the group then fails fast without waiting for the others.
+//
+//	g, ctx, err := fanout.New(ctx, registry, fanout.WithCritical("inventory"))
+//	g.Go("inventory", func(ctx context.Context) error { return fetchInventory(ctx) })
+//	g.Go("reviews", func(ctx context.Context) error { return fetchReviews(ctx) })
+//	if err := g.Wait(); err != nil {
+//		return err
+//	}
+//	if len(g.Skipped()) > 0 {
+//		// render without the reviews
+//	}
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Group is a fan-out of sub-calls.
+type Group struct {
+	registry *easybreaker.Registry
+	critical map[string]bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	err     error
+	skipped []string
+}
+
+type OptionCall func(*Group) error
+
+// Critical fails the group as soon as the breaker of one of the names
+// rejects its sub-call, canceling the context of the others.
+func WithCritical(names ...string) OptionCall {
+	return func(g *Group) error {
+		if len(names) == 0 {
+			return errors.New("fanout: critical names must be defined")
+		}
+		for _, name := range names {
+			g.critical[name] = true
+		}
+		return nil
+	}
+}
+
+// New returns a group of sub-calls admitted by the breakers of registry and
+// the context derived from ctx, canceled by the first failure or once Wait returns.
+func New(ctx context.Context, registry *easybreaker.Registry, fns ...OptionCall) (*Group, context.Context, error) {
+	if registry == nil {
+		return nil, nil, errors.New("fanout: registry must be set")
+	}
+
+	g := &Group{registry: registry, critical: make(map[string]bool)}
+	for _, fn := range fns {
+		if err := fn(g); err != nil {
+			return nil, nil, err
+		}
+	}
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	return g, g.ctx, nil
+}
+
+// Go runs fn in a goroutine with the breaker of the name. The first error
+// of the sub-calls, or the rejection of a critical one, fails the group.
+// The errors of the sub-calls canceled by the group are not counted
+// by their breakers.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		b, err := g.registry.Get(name)
+		if err != nil {
+			g.fail(err)
+			return
+		}
+		// the sub-calls failing once the group canceled them are ignored,
+		// their breakers only count the failures of their own
+		var failure error
+		err = b.ExecuteCtx(g.ctx, func(ctx context.Context) error {
+			failure = fn(ctx)
+			if failure != nil && g.canceled() {
+				return easybreaker.ErrIgnored
+			}
+			return failure
+		})
+		if errors.Is(err, easybreaker.ErrIgnored) {
+			err = failure
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, easybreaker.ErrBreakerOpen) && !g.critical[name]:
+			g.mu.Lock()
+			g.skipped = append(g.skipped, name)
+			g.mu.Unlock()
+		default:
+			g.fail(fmt.Errorf("fanout: %s: %w", name, err))
+		}
+	}()
+}
+
+// Wait waits for the sub-calls and returns the error failing the group, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// Skipped returns the names of the sub-calls rejected by their open breakers.
+func (g *Group) Skipped() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.skipped...)
+}
+
+// canceled reports whether the group canceled its context after a failure.
+func (g *Group) canceled() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err != nil
+}
+
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	if g.err == nil {
+		g.err = err
+		g.cancel()
+	}
+	g.mu.Unlock()
+}
@@ -0,0 +1,57 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	registry := easybreaker.NewRegistry(time.Minute, time.Minute)
+	reviews, _ := registry.Get("reviews")
+	reviews.ForceOpen()
+
+	g, _, err := New(context.Background(), registry)
+	assert.NoError(t, err)
+	done := make(chan struct{}, 2)
+	g.Go("inventory", func(ctx context.Context) error { done <- struct{}{}; return nil })
+	g.Go("reviews", func(ctx context.Context) error { done <- struct{}{}; return nil })
+	assert.NoError(t, g.Wait())
+	assert.Len(t, done, 1)
+	assert.Equal(t, []string{"reviews"}, g.Skipped())
+
+	// a critical breaker fails fast, canceling the others
+	g, ctx, err := New(context.Background(), registry, WithCritical("reviews"))
+	assert.NoError(t, err)
+	started := make(chan struct{})
+	g.Go("inventory", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	g.Go("reviews", func(ctx context.Context) error { return nil })
+	err = g.Wait()
+	assert.True(t, errors.Is(err, easybreaker.ErrBreakerOpen))
+	assert.EqualError(t, err, "fanout: reviews: circuit: breaker open")
+	assert.Error(t, ctx.Err())
+	// the sub-calls canceled by the group are not failures of their breakers
+	inventory, _ := registry.Get("inventory")
+	assert.Equal(t, uint32(0), inventory.Counts().Failures)
+	assert.Equal(t, uint32(1), inventory.Counts().Ignored)
+
+	// the failures fail the group
+	failed := errors.New("failed")
+	g, _, _ = New(context.Background(), registry)
+	g.Go("search", func(ctx context.Context) error { return failed })
+	assert.True(t, errors.Is(g.Wait(), failed))
+
+	_, _, err = New(context.Background(), nil)
+	assert.Error(t, err)
+	_, _, err = New(context.Background(), registry, WithCritical())
+	assert.Error(t, err)
+}
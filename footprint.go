@@ -0,0 +1,88 @@
+package easybreaker
+
+import (
+	"context"
+	"unsafe"
+)
+
+// Footprint is the approximate memory used by breakers in bytes,
+// to budget and tune the options of the many keyed breakers.
+type Footprint struct {
+	Breakers  int // the breakers themselves, their names and their last window and trip reason
+	Histogram int // the latency histograms
+	History   int // the audit trails of the manual actions
+	Windows   int // the failure velocity, spike detection, sliding or count window, hang detection and class budgets
+	Sinks     int // the event sinks and the contexts tracked to cancel on trip
+	Total     int
+}
+
+func (f *Footprint) add(o Footprint) {
+	f.Breakers += o.Breakers
+	f.Histogram += o.Histogram
+	f.History += o.History
+	f.Windows += o.Windows
+	f.Sinks += o.Sinks
+	f.Total += o.Total
+}
+
+// Footprint returns the approximate memory used by the breaker, the memory
+// of the functions given in the options and their captured state excluded.
+func (b *Breaker) Footprint() Footprint {
+	f := Footprint{
+		Breakers: int(unsafe.Sizeof(*b)+unsafe.Sizeof(shared{})) + len(b.name) + int(unsafe.Sizeof(Window{})+unsafe.Sizeof(TripReason{})),
+	}
+
+	if b.latency != nil {
+		f.Histogram = int(unsafe.Sizeof(*b.latency))
+	}
+	if b.deadlines != nil {
+		f.Histogram += int(unsafe.Sizeof(*b.deadlines))
+	}
+
+	b.audit.mu.Lock()
+	f.History = cap(b.audit.actions) * int(unsafe.Sizeof(Action{}))
+	for _, a := range b.audit.actions {
+		f.History += len(a.Who) + len(a.Reason)
+	}
+	b.audit.mu.Unlock()
+
+	if b.velocity != nil {
+		f.Windows += int(unsafe.Sizeof(*b.velocity))
+	}
+	if b.sliding != nil {
+		f.Windows += b.sliding.bytes()
+	}
+	if b.spike != nil {
+		f.Windows += int(unsafe.Sizeof(*b.spike)) + cap(b.spike.rates)*8 + cap(b.spike.totals)*4
+	}
+	if b.hang != nil {
+		f.Windows += int(unsafe.Sizeof(*b.hang))
+	}
+	for class := range b.budgets {
+		f.Windows += len(class) + int(unsafe.Sizeof(budget{})+unsafe.Sizeof(class)+unsafe.Sizeof(b))
+	}
+
+	f.Sinks = len(b.loadSinks()) * int(unsafe.Sizeof(sink{}))
+	if b.cancelOnTrip {
+		b.cancelMu.Lock()
+		f.Sinks += len(b.cancels) * int(unsafe.Sizeof(uint64(0))+unsafe.Sizeof(context.CancelFunc(nil)))
+		b.cancelMu.Unlock()
+	}
+
+	f.Total = f.Breakers + f.Histogram + f.History + f.Windows + f.Sinks
+	return f
+}
+
+// Footprint returns the approximate memory used by the breakers of the registry.
+func (r *Registry) Footprint() Footprint {
+	var f Footprint
+	for _, e := range r.snapshot() {
+		bf := e.breaker.Footprint()
+		// the entry of the shard
+		entry := len(e.name) + int(unsafe.Sizeof(e.name)+unsafe.Sizeof(e.breaker))
+		bf.Breakers += entry
+		bf.Total += entry
+		f.add(bf)
+	}
+	return f
+}
@@ -0,0 +1,38 @@
+package easybreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_Footprint(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithName("api"))
+	assert.NoError(t, err)
+	base := b.Footprint()
+	assert.Equal(t, 0, base.Histogram)
+	assert.Equal(t, base.Breakers, base.Total)
+
+	b, err = New(time.Minute, time.Minute,
+		WithName("api"),
+		WithLatencyHistogram(),
+		WithFailureVelocity(10, time.Second),
+		WithSink(SeverityInfo, func(Event) {}),
+	)
+	assert.NoError(t, err)
+	b.ForceOpen(By("alice"), Because("maintenance"))
+
+	f := b.Footprint()
+	assert.True(t, f.Histogram > 0)
+	assert.True(t, f.History > 0)
+	assert.True(t, f.Windows > 0)
+	assert.True(t, f.Sinks > 0)
+	assert.Equal(t, f.Breakers+f.Histogram+f.History+f.Windows+f.Sinks, f.Total)
+
+	r := NewRegistry(time.Minute, time.Minute)
+	r.Get("a")
+	r.Get("b")
+	rf := r.Footprint()
+	assert.True(t, rf.Total > 2*base.Total)
+}
@@ -0,0 +1,44 @@
+package easybreaker
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// Gauges are the metrics of a registry, a single alert on Open+HalfOpen
+// covers any dependency being unavailable.
+type Gauges struct {
+	Breakers int
+	Open     int
+	HalfOpen int
+	Trips    uint64 // the trips of the breakers since the creation of the registry
+}
+
+// Gauges returns the current number of open and half-open breakers
+// and the cumulative number of trips, the removed breakers included.
+func (r *Registry) Gauges() Gauges {
+	var g Gauges
+	r.ForEach(func(name string, b *Breaker) bool {
+		g.Breakers++
+		switch b.State() {
+		case StateOpen:
+			g.Open++
+		case StateHalfOpen:
+			g.HalfOpen++
+		}
+		g.Trips += b.Trips()
+		return true
+	})
+
+	g.Trips += atomic.LoadUint64(&r.trips)
+	return g
+}
+
+// Var returns the gauges as an expvar variable, published by the application:
+//
+//	expvar.Publish("breakers", registry.Var())
+func (r *Registry) Var() expvar.Var {
+	return expvar.Func(func() interface{} {
+		return r.Gauges()
+	})
+}
@@ -0,0 +1,33 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Gauges(t *testing.T) {
+	ts := time.Unix(1520100000, 0)
+	r := NewRegistry(time.Minute, 2*time.Minute, WithNow(func() time.Time { return ts }))
+
+	api, _ := r.Get("api")
+	db, _ := r.Get("db")
+	r.Get("cache")
+
+	api.Execute(func() error { return errors.New("failed") })
+	db.Execute(func() error { return errors.New("failed") })
+	ts = ts.Add(3 * time.Minute)
+	db.Allow()
+
+	assert.Equal(t, Gauges{Breakers: 3, Open: 1, HalfOpen: 1, Trips: 2}, r.Gauges())
+	assert.Equal(t, uint64(1), api.Trips())
+
+	// the trips of the removed breakers are kept
+	r.Remove("api")
+	r.Configure("db", time.Minute, time.Minute)
+	assert.Equal(t, Gauges{Breakers: 2, Trips: 2}, r.Gauges())
+
+	assert.JSONEq(t, `{"Breakers": 2, "Open": 0, "HalfOpen": 0, "Trips": 2}`, r.Var().String())
+}
@@ -0,0 +1,57 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// grace delays the trip once the strategies fired
+type grace struct {
+	failures uint32
+	period   int64
+
+	since int64  // the time the strategies fired first, 0 if they didn't
+	seen  uint32 // the failures since then
+}
+
+// TripGrace delays the trip until the strategies fired for failures more
+// failures, or for period, after they fired first, filtering out the one-off
+// blips without loosening the thresholds. Either limit can be zero, the first
+// reached trips the breaker. The grace is over as soon as a failure doesn't
+// meet the strategies or a new window starts.
+func WithTripGrace(failures uint32, period time.Duration) OptionCall {
+	return func(b *Breaker) error {
+		if failures == 0 && period <= 0 {
+			return errors.New("circuit: trip grace must be set")
+		}
+		if period < 0 {
+			return errors.New("circuit: trip grace period must not be negative")
+		}
+		b.grace = &grace{failures: failures, period: int64(period)}
+		return nil
+	}
+}
+
+// elapsed reports whether the strategies fired long enough to trip.
+func (g *grace) elapsed(now int64) bool {
+	if g == nil {
+		return true
+	}
+	since := atomic.LoadInt64(&g.since)
+	if since == 0 {
+		if atomic.CompareAndSwapInt64(&g.since, 0, now) {
+			atomic.StoreUint32(&g.seen, 0)
+		}
+		return false
+	}
+	seen := atomic.AddUint32(&g.seen, 1)
+	return (g.failures > 0 && seen >= g.failures) || (g.period > 0 && now-since >= g.period)
+}
+
+// clear ends the grace.
+func (g *grace) clear() {
+	if g != nil && atomic.LoadInt64(&g.since) != 0 {
+		atomic.StoreInt64(&g.since, 0)
+	}
+}
@@ -0,0 +1,51 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_TripGrace(t *testing.T) {
+	failed := func() error { return errors.New("failed") }
+	ok := func() error { return nil }
+	never := WithStateFunc(func(uint32, uint32) bool { return false }, defaultToClosed)
+
+	b, err := New(time.Minute, time.Minute, never, WithMaxFailures(1), WithTripGrace(2, 0), withTime(1520100000))
+	assert.NoError(t, err)
+
+	// the blip is over with the window
+	b.Execute(failed)
+	b.Execute(failed)
+	b.Execute(failed)
+	assert.Equal(t, StateClosed, b.State())
+	b.now = now(1520100061)
+	b.Execute(ok)
+	b.Execute(failed)
+	b.Execute(failed)
+	b.Execute(failed)
+	assert.Equal(t, StateClosed, b.State())
+
+	// 2 more failures
+	b.Execute(failed)
+	assert.Equal(t, StateOpen, b.State())
+	reason, _ := b.TripReason()
+	assert.Equal(t, StrategyMaxFailures, reason.Strategy)
+
+	b, err = New(time.Minute, time.Minute, never, WithMaxFailures(1), WithTripGrace(0, 10*time.Second), withTime(1520100000))
+	assert.NoError(t, err)
+	b.Execute(failed)
+	b.Execute(failed)
+	b.Execute(failed)
+	assert.Equal(t, StateClosed, b.State())
+	b.now = now(1520100010)
+	b.Execute(failed)
+	assert.Equal(t, StateOpen, b.State())
+
+	_, err = New(time.Minute, time.Minute, WithTripGrace(0, 0))
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithTripGrace(1, -time.Second))
+	assert.Error(t, err)
+}
@@ -0,0 +1,219 @@
+package easybreaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Group holds a breaker per shard of a sharded backend,
+// e.g. the partitions of a database.
+type Group struct {
+	shards   []*Breaker
+	ratio    float64 // the ratio of the open shards tripping all of them, 0 if disabled
+	tripping int32   // a trip handler is checking the ratio
+	pending  int32   // trips not checked yet
+	outage   *outage // the rule of ForceOpenAbove
+}
+
+// outage force-opens the healthy shards during a dependency-wide outage
+type outage struct {
+	ratio  float64
+	window int64
+
+	mu     sync.Mutex
+	active bool
+	forced []bool // the shards forced open by the rule
+}
+
+// GroupStats is the aggregated state of the shards of a group.
+type GroupStats struct {
+	Shards   int
+	Closed   int
+	HalfOpen int
+	Open     int
+	Counts   Counts // the sum of the counts of the shards, by class as well
+}
+
+// NewGroup returns a group of n shards created with the given settings,
+// the breakers are named "shard-<i>".
+func NewGroup(n int, interval time.Duration, cooldown time.Duration, fns ...OptionCall) (*Group, error) {
+	if n <= 0 {
+		return nil, errors.New("circuit: shards must be positive")
+	}
+
+	g := &Group{shards: make([]*Breaker, n)}
+	for i := range g.shards {
+		opts := make([]OptionCall, 0, len(fns)+2)
+		opts = append(opts, WithName(fmt.Sprintf("shard-%d", i)))
+		opts = append(opts, fns...)
+		opts = append(opts, WithSink(SeverityWarn, g.onTrip))
+
+		b, err := New(interval, cooldown, opts...)
+		if err != nil {
+			return nil, err
+		}
+		g.shards[i] = b
+	}
+	return g, nil
+}
+
+// ForShard returns the breaker of the shard i.
+func (g *Group) ForShard(i int) *Breaker {
+	return g.shards[i]
+}
+
+// Len returns the number of the shards.
+func (g *Group) Len() int {
+	return len(g.shards)
+}
+
+// TripAllAbove trips all the shards once more than ratio of them are open,
+// e.g. 0.5, a cluster-level outage rather than a few bad shards.
+// Each shard recovers with its own probes after the cooldown.
+// It must be called before the group is used.
+func (g *Group) TripAllAbove(ratio float64) error {
+	if ratio <= 0 || ratio >= 1 {
+		return errors.New("circuit: ratio must be in (0, 1)")
+	}
+	g.ratio = ratio
+	return nil
+}
+
+// ForceOpenAbove force-opens the closed shards once more than ratio of them
+// opened within the window, e.g. 0.5 in 1m, a dependency-wide outage.
+// The open shards keep probing and the forced ones are released as soon as
+// ratio of them or less are still not closed. It must be called before
+// the group is used.
+func (g *Group) ForceOpenAbove(ratio float64, window time.Duration) error {
+	if ratio <= 0 || ratio >= 1 {
+		return errors.New("circuit: ratio must be in (0, 1)")
+	}
+	if window <= 0 {
+		return errors.New("circuit: window must be positive")
+	}
+	g.outage = &outage{ratio: ratio, window: int64(window), forced: make([]bool, len(g.shards))}
+	for _, b := range g.shards {
+		if _, err := b.Subscribe(SeverityInfo, g.onChange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Outage reports whether the shards are forced open by ForceOpenAbove.
+func (g *Group) Outage() bool {
+	if g.outage == nil {
+		return false
+	}
+	g.outage.mu.Lock()
+	defer g.outage.mu.Unlock()
+	return g.outage.active
+}
+
+func (g *Group) onChange(e Event) {
+	if e.Type != EventStateChange {
+		return
+	}
+	o := g.outage
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := e.Time.UnixNano()
+	opened, down := 0, 0
+	for i, b := range g.shards {
+		if o.forced[i] {
+			continue
+		}
+		if atomic.LoadInt32(&b.state) != closed {
+			down++
+		}
+		if at := atomic.LoadInt64(&b.openedAt); at != 0 && now-at <= o.window {
+			opened++
+		}
+	}
+
+	limit := o.ratio * float64(len(g.shards))
+	switch {
+	case !o.active && float64(opened) > limit:
+		o.active = true
+		reason := fmt.Sprintf("%d of %d shards opened", opened, len(g.shards))
+		for i, b := range g.shards {
+			if atomic.LoadInt32(&b.state) == closed && b.override() == forcedNone {
+				o.forced[i] = true
+				b.ForceOpen(By("group"), Because(reason))
+			}
+		}
+	case o.active && float64(down) <= limit:
+		o.active = false
+		for i, b := range g.shards {
+			if o.forced[i] {
+				o.forced[i] = false
+				b.Release(By("group"), Because("the shards recovered"))
+			}
+		}
+	}
+}
+
+func (g *Group) onTrip(e Event) {
+	if e.Type != EventStateChange || g.ratio == 0 {
+		return
+	}
+	atomic.AddInt32(&g.pending, 1)
+	g.tripAll()
+}
+
+// tripAll trips all the shards once the ratio of the open ones is exceeded.
+// A single handler checks at once, the trips of the shards, including the
+// ones of tripAll itself, are checked again by the running handler before
+// it returns, so the shard exceeding the ratio is never missed.
+func (g *Group) tripAll() {
+	for atomic.LoadInt32(&g.pending) > 0 && atomic.CompareAndSwapInt32(&g.tripping, 0, 1) {
+		atomic.StoreInt32(&g.pending, 0)
+		if stats := g.Stats(); float64(stats.Open) > g.ratio*float64(stats.Shards) {
+			reason := TripReason{Strategy: StrategyGroup, Threshold: g.ratio, Value: float64(stats.Open) / float64(stats.Shards)}
+			for _, b := range g.shards {
+				b.trip(reason)
+			}
+		}
+		atomic.StoreInt32(&g.tripping, 0)
+	}
+}
+
+// Stats returns the aggregated state of the shards.
+func (g *Group) Stats() GroupStats {
+	stats := GroupStats{Shards: len(g.shards)}
+	for _, b := range g.shards {
+		switch b.State() {
+		case StateClosed:
+			stats.Closed++
+		case StateHalfOpen:
+			stats.HalfOpen++
+		case StateOpen:
+			stats.Open++
+		}
+		stats.Counts.add(b.Counts())
+	}
+	return stats
+}
+
+// add adds the counts of o, by class as well.
+func (c *Counts) add(o Counts) {
+	c.Total += o.Total
+	c.Failures += o.Failures
+	c.Successes += o.Successes
+	c.Timeouts += o.Timeouts
+	c.Ignored += o.Ignored
+	c.Slow += o.Slow
+	c.InFlight += o.InFlight
+	for class, counts := range o.Classes {
+		if c.Classes == nil {
+			c.Classes = make(map[string]Counts, len(o.Classes))
+		}
+		sum := c.Classes[class]
+		sum.add(counts)
+		c.Classes[class] = sum
+	}
+}
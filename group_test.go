@@ -0,0 +1,135 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	g, err := NewGroup(4, time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, g.Len())
+	assert.Equal(t, "shard-2", g.ForShard(2).name)
+
+	g.ForShard(0).Execute(func() error { return nil })
+	g.ForShard(1).Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, GroupStats{Shards: 4, Closed: 3, Open: 1, Counts: Counts{Total: 1, Successes: 1}}, g.Stats())
+
+	// the timeouts and the classes are summed as well
+	g, err = NewGroup(2, time.Minute, 2*time.Minute,
+		WithClassBudget(ClassWrite, func(uint32, uint32) bool { return false }),
+		WithStateFunc(func(uint32, uint32) bool { return false }, defaultToClosed),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+	g.ForShard(0).Execute(func() error { return context.DeadlineExceeded })
+	g.ForShard(0).ExecuteClass(ClassWrite, func() error { return nil })
+	g.ForShard(1).ExecuteClass(ClassWrite, func() error { return errors.New("failed") })
+	assert.Equal(t, Counts{
+		Total: 3, Failures: 2, Successes: 1, Timeouts: 1,
+		Classes: map[string]Counts{ClassWrite: {Total: 2, Failures: 1}},
+	}, g.Stats().Counts)
+
+	_, err = NewGroup(0, time.Minute, time.Minute)
+	assert.Error(t, err)
+	_, err = NewGroup(1, 0, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestGroup_TripAllAbove(t *testing.T) {
+	g, err := NewGroup(4, time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	assert.NoError(t, g.TripAllAbove(0.5))
+
+	g.ForShard(0).Execute(func() error { return errors.New("failed") })
+	g.ForShard(1).Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, 2, g.Stats().Open)
+
+	// 3 of 4 shards are open, the cluster is down
+	g.ForShard(2).Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, 4, g.Stats().Open)
+	assert.Equal(t, uint64(1), g.ForShard(3).Trips())
+
+	assert.Error(t, g.TripAllAbove(1))
+	assert.Error(t, g.TripAllAbove(0))
+}
+
+func TestGroup_TripAllAbove_Concurrent(t *testing.T) {
+	g, err := NewGroup(3, time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	assert.NoError(t, g.TripAllAbove(0.5))
+
+	// the shards trip while the handler of another trip is checking
+	atomic.StoreInt32(&g.tripping, 1)
+	g.ForShard(0).Trip(0)
+	g.ForShard(1).Trip(0)
+	assert.Equal(t, StateClosed, g.ForShard(2).State())
+
+	// the running handler checks the trips it missed
+	atomic.StoreInt32(&g.tripping, 0)
+	g.tripAll()
+	assert.Equal(t, StateOpen, g.ForShard(2).State())
+
+	var wg sync.WaitGroup
+	g, err = NewGroup(8, time.Minute, 2*time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, g.TripAllAbove(0.5))
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(b *Breaker) {
+			defer wg.Done()
+			b.Trip(0)
+		}(g.ForShard(i))
+	}
+	wg.Wait()
+	assert.Equal(t, 8, g.Stats().Open)
+}
+
+func TestGroup_ForceOpenAbove(t *testing.T) {
+	g, err := NewGroup(4, time.Minute, 2*time.Minute, WithLeastReqs(1), withTime(1520100000))
+	assert.NoError(t, err)
+	assert.NoError(t, g.ForceOpenAbove(0.5, time.Minute))
+	at := func(ts int64) {
+		for i := 0; i < g.Len(); i++ {
+			g.ForShard(i).now = now(ts)
+		}
+	}
+	failed := func() error { return errors.New("failed") }
+
+	// the first trip is out of the window
+	g.ForShard(0).Execute(failed)
+	at(1520100061)
+	g.ForShard(1).Execute(failed)
+	g.ForShard(2).Execute(failed)
+	assert.False(t, g.Outage())
+	assert.Equal(t, 3, g.Stats().Open)
+
+	// the first shard recovers and trips again, 3 of 4 shards opened within the window
+	at(1520100121)
+	g.ForShard(0).Execute(func() error { return nil })
+	g.ForShard(0).Execute(failed)
+	assert.True(t, g.Outage())
+	assert.Equal(t, 4, g.Stats().Open)
+	assert.Equal(t, uint64(0), g.ForShard(3).Trips())
+	history := g.ForShard(3).History()
+	assert.Len(t, history, 1)
+	assert.Equal(t, Action{Time: time.Unix(1520100121, 0), Kind: ActionForceOpen, Who: "group", Reason: "3 of 4 shards opened"}, history[0])
+
+	// 2 of 4 shards are still open
+	at(1520100182)
+	g.ForShard(1).Execute(func() error { return nil })
+	g.ForShard(1).Execute(func() error { return nil })
+	assert.Equal(t, StateClosed, g.ForShard(1).State())
+	assert.False(t, g.Outage())
+	assert.Equal(t, StateClosed, g.ForShard(3).State())
+	assert.Equal(t, ActionRelease, g.ForShard(3).History()[1].Kind)
+
+	assert.Error(t, g.ForceOpenAbove(1, time.Minute))
+	assert.Error(t, g.ForceOpenAbove(0.5, 0))
+}
@@ -0,0 +1,82 @@
+package grpcadapter
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/rfyiamcool/easybreaker/grpcbreaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor runs the unary calls with the breakers of their
+// methods, the rejections fail with codes.Unavailable.
+func UnaryClientInterceptor(methods *grpcbreaker.Methods) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := methods.Invoke(ctx, method, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+		return rejected(err)
+	}
+}
+
+// StreamClientInterceptor runs the streams with the breakers of their
+// methods, the rejections fail with codes.Unavailable. A stream counts
+// once it ends: it succeeds with io.EOF, or with the response of a stream
+// of the client, and fails with the other errors. The streams abandoned
+// by their callers are ignored.
+func StreamClientInterceptor(methods *grpcbreaker.Methods) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		done, err := methods.Allow(method)
+		if err != nil {
+			return nil, rejected(err)
+		}
+
+		s, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			done(err)
+			return nil, err
+		}
+		// the context of the stream is done once the stream ends,
+		// the outcome seen by RecvMsg comes first
+		go func() {
+			<-s.Context().Done()
+			done(easybreaker.ErrIgnored)
+		}()
+		return &clientStream{ClientStream: s, done: done, serverStreams: desc.ServerStreams}, nil
+	}
+}
+
+// clientStream reports the outcome of a stream to its breaker.
+type clientStream struct {
+	grpc.ClientStream
+	done          func(error)
+	serverStreams bool
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.done(nil)
+	case err != nil:
+		s.done(err)
+	case !s.serverStreams:
+		// the single response of a stream of the client
+		s.done(nil)
+	}
+	return err
+}
+
+// rejected returns the rejections of the breakers as codes.Unavailable.
+func rejected(err error) error {
+	if errors.Is(err, easybreaker.ErrBreakerOpen) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package grpcadapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/rfyiamcool/easybreaker/grpcbreaker"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newMethods(t *testing.T) *grpcbreaker.Methods {
+	pool, err := easybreaker.NewPool(10, time.Minute, time.Minute)
+	assert.NoError(t, err)
+	methods, err := grpcbreaker.New(pool)
+	assert.NoError(t, err)
+	return methods
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	methods := newMethods(t)
+	interceptor := UnaryClientInterceptor(methods)
+	ctx := context.Background()
+
+	failed := status.Error(codes.Internal, "failed")
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return failed
+	}
+	assert.Equal(t, failed, interceptor(ctx, "/pkg.Service/Hot", nil, nil, nil, invoker))
+
+	err := interceptor(ctx, "/pkg.Service/Hot", nil, nil, nil, invoker)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	b, _ := methods.Breaker("/pkg.Service/Hot")
+	assert.Equal(t, uint32(0), b.Counts().InFlight)
+}
+
+type clientStreamStub struct {
+	grpc.ClientStream
+	ctx  context.Context
+	errs []error
+}
+
+func (s *clientStreamStub) Context() context.Context { return s.ctx }
+
+func (s *clientStreamStub) RecvMsg(interface{}) error {
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	methods := newMethods(t)
+	interceptor := StreamClientInterceptor(methods)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stub := &clientStreamStub{ctx: ctx, errs: []error{nil, nil, io.EOF}}
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return stub, nil
+	}
+	s, err := interceptor(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/pkg.Service/Watch", streamer)
+	assert.NoError(t, err)
+	b, _ := methods.Breaker("/pkg.Service/Watch")
+	assert.Equal(t, uint32(1), b.Counts().InFlight)
+	for s.RecvMsg(nil) == nil {
+	}
+	assert.Equal(t, easybreaker.Counts{Total: 1, Successes: 1}, b.Counts())
+
+	// a failed stream opens the breaker
+	stub.errs = []error{errors.New("reset")}
+	s, err = interceptor(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/pkg.Service/Watch", streamer)
+	assert.NoError(t, err)
+	assert.Error(t, s.RecvMsg(nil))
+	_, err = interceptor(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/pkg.Service/Watch", streamer)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// an abandoned stream is ignored once its context is done
+	stub.errs = nil
+	s, err = interceptor(ctx, &grpc.StreamDesc{}, nil, "/pkg.Service/Upload", streamer)
+	assert.NoError(t, err)
+	cancel()
+	upload, _ := methods.Breaker("/pkg.Service/Upload")
+	assert.Eventually(t, func() bool { return upload.Counts().InFlight == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint32(1), upload.Counts().Ignored)
+}
@@ -0,0 +1,87 @@
+// Package grpcadapter plugs the breakers into gRPC: the balancer of the
+// outlier ejection and the client interceptors of the per-method breakers.
+// It's a module of its own, so the easybreaker module doesn't depend on grpc.
+//
+//	ejector, err := outlier.New(10*time.Second, 30*time.Second)
+//	grpcadapter.RegisterBalancer("outlier_round_robin", ejector)
+//	conn, err := grpc.Dial(target,
+//		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"outlier_round_robin":{}}]}`),
+//	)
+package grpcadapter
+
+import (
+	"sort"
+
+	"github.com/rfyiamcool/easybreaker/outlier"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Picker is a balancer.Picker picking the ready SubConns in round robin,
+// skipping the addresses ejected by the breakers of an outlier.Ejector.
+// The outcomes of the RPCs are fed back into the breakers.
+type Picker struct {
+	ejector  *outlier.Ejector
+	addrs    []string
+	subConns map[string]balancer.SubConn
+}
+
+// NewPicker returns the picker of the ready SubConns by address.
+func NewPicker(ejector *outlier.Ejector, subConns map[string]balancer.SubConn) *Picker {
+	addrs := make([]string, 0, len(subConns))
+	for addr := range subConns {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return &Picker{ejector: ejector, addrs: addrs, subConns: subConns}
+}
+
+// Pick implements balancer.Picker, it fails with codes.Unavailable when
+// every address is ejected.
+func (p *Picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	if len(p.addrs) == 0 {
+		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
+	}
+	addr, done, err := p.ejector.Pick(p.addrs)
+	if err != nil {
+		return balancer.PickResult{}, status.Error(codes.Unavailable, err.Error())
+	}
+	return balancer.PickResult{
+		SubConn: p.subConns[addr],
+		Done:    func(info balancer.DoneInfo) { done(info.Err) },
+	}, nil
+}
+
+// pickerBuilder builds the pickers of the ready SubConns on every update
+// and drops the breakers of the addresses gone.
+type pickerBuilder struct {
+	ejector *outlier.Ejector
+}
+
+// NewPickerBuilder returns the base.PickerBuilder of the pickers of ejector.
+func NewPickerBuilder(ejector *outlier.Ejector) base.PickerBuilder {
+	return pickerBuilder{ejector: ejector}
+}
+
+func (b pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	subConns := make(map[string]balancer.SubConn, len(info.ReadySCs))
+	addrs := make([]string, 0, len(info.ReadySCs))
+	for sc, sci := range info.ReadySCs {
+		subConns[sci.Address.Addr] = sc
+		addrs = append(addrs, sci.Address.Addr)
+	}
+	b.ejector.Update(addrs)
+	if len(subConns) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	return NewPicker(b.ejector, subConns)
+}
+
+// RegisterBalancer registers the balancer picking with ejector under name,
+// it's selected by the service config. It must be called at the
+// initialization, like balancer.Register.
+func RegisterBalancer(name string, ejector *outlier.Ejector) {
+	balancer.Register(base.NewBalancerBuilder(name, NewPickerBuilder(ejector), base.Config{HealthCheck: true}))
+}
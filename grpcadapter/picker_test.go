@@ -0,0 +1,61 @@
+package grpcadapter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/rfyiamcool/easybreaker/outlier"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+type subConn struct {
+	balancer.SubConn
+	addr string
+}
+
+func TestPicker(t *testing.T) {
+	ejector, err := outlier.New(time.Minute, time.Minute, easybreaker.WithLeastReqs(1))
+	assert.NoError(t, err)
+
+	first, second := &subConn{addr: "10.0.0.1:443"}, &subConn{addr: "10.0.0.2:443"}
+	picker := NewPickerBuilder(ejector).Build(base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+		first:  {Address: resolver.Address{Addr: first.addr}},
+		second: {Address: resolver.Address{Addr: second.addr}},
+	}})
+
+	// the failing address is ejected
+	for i := 0; i < 4; i++ {
+		res, err := picker.Pick(balancer.PickInfo{})
+		assert.NoError(t, err)
+		if res.SubConn == second {
+			res.Done(balancer.DoneInfo{Err: errors.New("unavailable")})
+		} else {
+			res.Done(balancer.DoneInfo{})
+		}
+	}
+	assert.Equal(t, []string{second.addr}, ejector.Ejected())
+	for i := 0; i < 4; i++ {
+		res, err := picker.Pick(balancer.PickInfo{})
+		assert.NoError(t, err)
+		assert.True(t, res.SubConn == first)
+		res.Done(balancer.DoneInfo{})
+	}
+
+	b, _ := ejector.Breaker(first.addr)
+	b.ForceOpen()
+	_, err = picker.Pick(balancer.PickInfo{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// the breakers of the addresses gone are dropped
+	picker = NewPickerBuilder(ejector).Build(base.PickerBuildInfo{})
+	_, err = picker.Pick(balancer.PickInfo{})
+	assert.Equal(t, balancer.ErrNoSubConnAvailable, err)
+	assert.Empty(t, ejector.Ejected())
+}
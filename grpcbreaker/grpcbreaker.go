@@ -0,0 +1,117 @@
+// Package grpcbreaker keeps a breaker per full method name of a gRPC client,
+// so a failing hot RPC doesn't open the circuit for the healthy RPCs of the
+// same service. The breakers share the settings of an easybreaker.Pool,
+// which also evicts the least recently used methods.
+//
+// The package doesn't depend on grpc, the interceptors are in the
+// grpcadapter module, kept apart so this module doesn't depend on grpc:
+//
+//	methods, err := grpcbreaker.New(pool, grpcbreaker.WithIsFailure(func(err error) bool {
+//		switch status.Code(err) {
+//		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal:
+//			return true
+//		}
+//		return false
+//	}))
+//	conn, err := grpc.Dial(target,
+//		grpc.WithUnaryInterceptor(grpcadapter.UnaryClientInterceptor(methods)),
+//		grpc.WithStreamInterceptor(grpcadapter.StreamClientInterceptor(methods)),
+//	)
+package grpcbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// errPanicked reports a call which panicked to its breaker
+var errPanicked = errors.New("grpcbreaker: call panicked")
+
+// Methods holds the breakers of the methods.
+type Methods struct {
+	pool      *easybreaker.Pool
+	isFailure func(error) bool
+}
+
+type OptionCall func(*Methods) error
+
+// IsFailure reports whether the error of a call counts as a failure,
+// e.g. only the Unavailable and DeadlineExceeded codes, all the errors by default.
+// The calls whose error doesn't count are reported as successful.
+func WithIsFailure(fn func(error) bool) OptionCall {
+	return func(m *Methods) error {
+		if fn == nil {
+			return errors.New("grpcbreaker: isFailure must be defined")
+		}
+		m.isFailure = fn
+		return nil
+	}
+}
+
+// New returns the breakers of the methods taken from pool,
+// each breaker is named with the full method name, e.g. /pkg.Service/Method.
+func New(pool *easybreaker.Pool, fns ...OptionCall) (*Methods, error) {
+	if pool == nil {
+		return nil, errors.New("grpcbreaker: pool must be set")
+	}
+
+	m := &Methods{pool: pool}
+	for _, fn := range fns {
+		if err := fn(m); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Invoke runs the call of the method with its breaker, it returns
+// easybreaker.ErrBreakerOpen without calling when the breaker is open.
+// A call which panics counts as a failure.
+func (m *Methods) Invoke(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	done, err := m.Allow(method)
+	if err != nil {
+		return err
+	}
+	panicked := true
+	defer func() {
+		if panicked {
+			done(errPanicked)
+		}
+	}()
+
+	err = call(ctx)
+	panicked = false
+	done(err)
+	return err
+}
+
+// Allow is the first step of Invoke for the calls which can't be wrapped in
+// a function, e.g. the streams, done must be called with the outcome of the
+// call, e.g. in a defer, the next calls are ignored.
+func (m *Methods) Allow(method string) (func(error), error) {
+	b, err := m.pool.Get(method)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Allow(); err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			if err != nil && m.isFailure != nil && !errors.Is(err, easybreaker.ErrIgnored) && !m.isFailure(err) {
+				err = nil
+			}
+			b.Done(err)
+		})
+	}, nil
+}
+
+// Breaker returns the breaker of the method, e.g. to check its state.
+func (m *Methods) Breaker(method string) (*easybreaker.Breaker, error) {
+	return m.pool.Get(method)
+}
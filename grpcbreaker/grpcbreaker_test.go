@@ -0,0 +1,58 @@
+package grpcbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethods_Invoke(t *testing.T) {
+	pool, err := easybreaker.NewPool(2, time.Minute, time.Minute)
+	assert.NoError(t, err)
+	notFound := errors.New("not found")
+	m, err := New(pool, WithIsFailure(func(err error) bool { return err != notFound }))
+	assert.NoError(t, err)
+
+	failed := errors.New("unavailable")
+	ctx := context.Background()
+	assert.Equal(t, failed, m.Invoke(ctx, "/pkg.Service/Hot", func(context.Context) error { return failed }))
+	assert.Equal(t, easybreaker.ErrBreakerOpen, m.Invoke(ctx, "/pkg.Service/Hot", func(context.Context) error { return nil }))
+
+	// the other methods are unaffected, the ignored errors don't count
+	assert.Equal(t, notFound, m.Invoke(ctx, "/pkg.Service/Cold", func(context.Context) error { return notFound }))
+	assert.NoError(t, m.Invoke(ctx, "/pkg.Service/Cold", func(context.Context) error { return nil }))
+
+	b, err := m.Breaker("/pkg.Service/Cold")
+	assert.NoError(t, err)
+	assert.Equal(t, easybreaker.StateClosed, b.State())
+	assert.Equal(t, easybreaker.Counts{Total: 2, Successes: 2}, b.Counts())
+
+	// the least recently used method is evicted
+	assert.NoError(t, m.Invoke(ctx, "/pkg.Service/Other", func(context.Context) error { return nil }))
+	assert.Equal(t, uint64(1), pool.Evicted())
+
+	// a panic releases the call, counted as a failure
+	assert.Panics(t, func() {
+		m.Invoke(ctx, "/pkg.Service/Other", func(context.Context) error { panic("boom") })
+	})
+	b, _ = m.Breaker("/pkg.Service/Other")
+	assert.Equal(t, uint32(0), b.Counts().InFlight)
+	assert.Equal(t, easybreaker.StateOpen, b.State())
+
+	// the two steps of the streams
+	done, err := m.Allow("/pkg.Service/Stream")
+	assert.NoError(t, err)
+	done(notFound)
+	done(failed)
+	b, _ = m.Breaker("/pkg.Service/Stream")
+	assert.Equal(t, easybreaker.Counts{Total: 1, Successes: 1}, b.Counts())
+
+	_, err = New(nil)
+	assert.Error(t, err)
+	_, err = New(pool, WithIsFailure(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package easybreaker
+
+import (
+	"errors"
+)
+
+// TransitionGuard is called before every transition of the state machine with
+// the counts of the current state, the transition is vetoed if it returns
+// false, e.g. to refuse closing during a deploy freeze. A vetoed transition
+// is asked again on the next request or failure, meanwhile the breaker stays
+// in its state: open rejects, closed and half-open admit. The manual control
+// is not guarded.
+func WithTransitionGuard(guard func(from, to State, counts Counts) bool) OptionCall {
+	return func(b *Breaker) error {
+		if guard == nil {
+			return errors.New("circuit: transition guard must be defined")
+		}
+		b.guard = guard
+		return nil
+	}
+}
+
+// guarded reports whether the guard lets the transition happen.
+func (b *Breaker) guarded(from, to int32) bool {
+	if b.guard == nil {
+		return true
+	}
+	return b.guard(State(from), State(to), b.current())
+}
@@ -0,0 +1,56 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_TransitionGuard(t *testing.T) {
+	freeze := true
+	var asked []State
+	b, err := New(
+		time.Minute, time.Minute,
+		WithLeastReqs(1),
+		WithTransitionGuard(func(from, to State, counts Counts) bool {
+			asked = append(asked, to)
+			return !freeze || to != StateClosed
+		}),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+
+	b.now = now(1520100060)
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	// closing is vetoed during the freeze, the probes go on
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	freeze = false
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, []State{StateOpen, StateHalfOpen, StateClosed, StateClosed, StateClosed}, asked)
+
+	_, err = New(time.Minute, time.Minute, WithTransitionGuard(nil))
+	assert.Error(t, err)
+}
+
+func TestBreaker_TransitionGuardTrip(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithTransitionGuard(func(from, to State, counts Counts) bool {
+		return counts.Failures >= 2
+	}))
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateClosed, b.State())
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+}
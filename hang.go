@@ -0,0 +1,89 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// StrategyHang is the strategy of WithHangDetection,
+// the value is the average age of the requests in seconds.
+const StrategyHang = "hang"
+
+// HangDetection trips the breaker when more than maxInFlight requests of
+// Execute are in flight and their average age exceeds maxAge, a classic
+// sign of a hung dependency, even before the errors start returning.
+// It's checked whenever such a request starts in the closed state.
+func WithHangDetection(maxInFlight uint32, maxAge time.Duration) OptionCall {
+	return func(b *Breaker) error {
+		if maxInFlight == 0 {
+			return errors.New("circuit: hang max in flight must be positive")
+		}
+		if maxAge < time.Millisecond {
+			return errors.New("circuit: hang max age must be at least 1ms")
+		}
+		b.hang = &hang{maxInFlight: int64(maxInFlight), maxAge: maxAge}
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
+// hang tracks the requests in flight and the sum of their starts,
+// in milliseconds since base to hold a large number of requests.
+type hang struct {
+	maxInFlight int64
+	maxAge      time.Duration
+	base        int64
+
+	tracked int64
+	starts  int64
+}
+
+func (h *hang) enter(start int64) {
+	atomic.AddInt64(&h.starts, (start-h.base)/int64(time.Millisecond))
+	atomic.AddInt64(&h.tracked, 1)
+}
+
+func (h *hang) leave(start int64) {
+	atomic.AddInt64(&h.tracked, -1)
+	atomic.AddInt64(&h.starts, -(start-h.base)/int64(time.Millisecond))
+}
+
+// age returns the number of the tracked requests and their average age,
+// approximate while the requests start and finish.
+func (h *hang) age(now int64) (int64, time.Duration) {
+	tracked := atomic.LoadInt64(&h.tracked)
+	if tracked <= 0 {
+		return 0, 0
+	}
+	avg := atomic.LoadInt64(&h.starts) / tracked
+	age := time.Duration((now-h.base)/int64(time.Millisecond)-avg) * time.Millisecond
+	if age < 0 {
+		age = 0
+	}
+	return tracked, age
+}
+
+// enterHang tracks a request starting at now and trips the breaker
+// if the requests in flight hang.
+func (b *Breaker) enterHang(now int64) {
+	b.hang.enter(now)
+	if atomic.LoadInt32(&b.state) != closed || b.override() != forcedNone {
+		return
+	}
+
+	tracked, age := b.hang.age(now)
+	if tracked > b.hang.maxInFlight && age > b.hang.maxAge {
+		b.trip(TripReason{Strategy: StrategyHang, Threshold: b.hang.maxAge.Seconds(), Value: age.Seconds()})
+	}
+}
+
+// InFlightAge returns the number of the requests of Execute in flight and
+// their average age, tracked with WithHangDetection only.
+func (b *Breaker) InFlightAge() (uint32, time.Duration) {
+	if b.hang == nil {
+		return 0, 0
+	}
+	tracked, age := b.hang.age(b.now().UnixNano())
+	return uint32(tracked), age
+}
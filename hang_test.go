@@ -0,0 +1,41 @@
+package easybreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_HangDetection(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithHangDetection(2, 10*time.Second), withTime(1520100000))
+	assert.NoError(t, err)
+
+	first := b.begin()
+	second := b.begin()
+	b.now = now(1520100020)
+	n, age := b.InFlightAge()
+	assert.Equal(t, uint32(2), n)
+	assert.Equal(t, 20*time.Second, age)
+
+	// a request finishing resets the age
+	b.finish(second, nil)
+	second = b.begin()
+	assert.Equal(t, StateClosed, b.State())
+
+	// 3 requests aged 13s in average
+	b.now = now(1520100030)
+	b.begin()
+	assert.Equal(t, StateOpen, b.State())
+	reason, _ := b.TripReason()
+	assert.Equal(t, StrategyHang, reason.Strategy)
+	assert.InDelta(t, 13.33, reason.Value, 0.01)
+	assert.Equal(t, float64(10), reason.Threshold)
+
+	b.finish(first, nil)
+	n, _ = b.InFlightAge()
+	assert.Equal(t, uint32(2), n)
+
+	_, err = New(time.Minute, time.Minute, WithHangDetection(0, time.Second), WithHangDetection(1, 0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// HistogramBuckets is the number of the buckets of Histogram, the bucket i
+// counts the latencies up to 2^i microseconds, the last one the longer ones.
+const HistogramBuckets = 32
+
+type histogram struct {
+	buckets [HistogramBuckets]uint64
+	count   uint64
+	sum     int64
+}
+
+// Histogram is a snapshot of the latencies of the requests.
+type Histogram struct {
+	Buckets [HistogramBuckets]uint64
+	Count   uint64
+	Sum     time.Duration
+}
+
+// LatencyHistogram enables measuring the latencies of the requests of Execute
+// and ExecuteCtx. The requests of Allow and Done are not measured.
+func WithLatencyHistogram() OptionCall {
+	return func(b *Breaker) error {
+		b.latency = &histogram{}
+		if b.sampling == 0 {
+			b.sampling = 1
+		}
+		return nil
+	}
+}
+
+// StatsSampling measures only 1 of n requests, keeping the overhead of
+// the latency histogram bounded on the hot paths. All the outcomes
+// are still counted.
+func WithStatsSampling(n uint32) OptionCall {
+	return func(b *Breaker) error {
+		if n == 0 {
+			return errors.New("circuit: stats sampling must be positive")
+		}
+		b.sampling = n
+		return nil
+	}
+}
+
+// BucketBound returns the upper bound of the bucket i of Histogram.
+func BucketBound(i int) time.Duration {
+	if i >= HistogramBuckets-1 {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration(1<<uint(i)) * time.Microsecond
+}
+
+func bucketOf(d time.Duration) int {
+	us := uint64(d / time.Microsecond)
+	i := 0
+	for i < HistogramBuckets-1 && us > 1<<uint(i) {
+		i++
+	}
+	return i
+}
+
+func (h *histogram) observe(d time.Duration) {
+	atomic.AddUint64(&h.buckets[bucketOf(d)], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddInt64(&h.sum, int64(d))
+}
+
+func (h *histogram) snapshot() Histogram {
+	var s Histogram
+	for i := range h.buckets {
+		s.Buckets[i] = atomic.LoadUint64(&h.buckets[i])
+	}
+	s.Count = atomic.LoadUint64(&h.count)
+	s.Sum = time.Duration(atomic.LoadInt64(&h.sum))
+	return s
+}
+
+// Percentile returns the upper bound of the bucket holding the p-th
+// percentile, p in [0, 100].
+func (h Histogram) Percentile(p float64) time.Duration {
+	var count uint64
+	for _, n := range h.Buckets {
+		count += n
+	}
+	if count == 0 {
+		return 0
+	}
+
+	rank := uint64(p / 100 * float64(count))
+	if rank >= count {
+		rank = count - 1
+	}
+	var seen uint64
+	for i, n := range h.Buckets {
+		seen += n
+		if seen > rank {
+			return BucketBound(i)
+		}
+	}
+	return BucketBound(HistogramBuckets - 1)
+}
+
+// Mean returns the average latency.
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Latency returns the histogram of the latencies measured since the breaker
+// was created, false if WithLatencyHistogram is not set.
+func (b *Breaker) Latency() (Histogram, bool) {
+	if b.latency == nil {
+		return Histogram{}, false
+	}
+	return b.latency.snapshot(), true
+}
+
+// span is a request of Execute started by begin.
+type span struct {
+	start   int64 // 0 if the request is neither measured nor tracked
+	sampled bool  // the latency is measured
+
+	uncounted bool   // a non-probe passed through, see WithProbeSelector
+	ignored   bool   // the outcome is neutral, see WithDeadlineFailures
+	weight    uint32 // the cost of ExecuteWeighted, 0 for an ordinary request
+}
+
+// begin starts a request, it's measured if it's sampled
+// and tracked by the hang detection.
+func (b *Breaker) begin() span {
+	sampled := b.latency != nil && (b.sampling <= 1 || atomic.AddUint32(&b.sampled, 1)%b.sampling == 0)
+	if !sampled && b.hang == nil && b.slowCall == nil {
+		return span{}
+	}
+
+	s := span{start: b.now().UnixNano(), sampled: sampled}
+	if b.hang != nil {
+		b.enterHang(s.start)
+	}
+	return s
+}
+
+// finish measures a request started by begin and reports it to Done.
+func (b *Breaker) finish(s span, err error) {
+	var elapsed time.Duration
+	if s.start != 0 {
+		elapsed = time.Duration(b.now().UnixNano() - s.start)
+	}
+	if s.sampled {
+		b.latency.observe(elapsed)
+	}
+	if b.hang != nil {
+		b.hang.leave(s.start)
+	}
+	if s.uncounted {
+		b.release()
+		return
+	}
+	n := weight(s.weight)
+	if s.ignored {
+		b.release()
+		b.ignore(n)
+		return
+	}
+	slow := b.slowCall != nil && elapsed >= b.slowCall.threshold
+	if slow {
+		atomic.AddUint32(&b.slow, n)
+	}
+	b.done(n, err)
+	if slow {
+		b.onSlow()
+	}
+}
@@ -0,0 +1,68 @@
+package easybreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_Latency(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+	_, ok := b.Latency()
+	assert.False(t, ok)
+
+	ts := time.Unix(1520100000, 0)
+	b, err = New(
+		time.Minute, 2*time.Minute,
+		WithLatencyHistogram(),
+		WithNow(func() time.Time { return ts }),
+	)
+	assert.NoError(t, err)
+
+	for _, d := range []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, time.Second} {
+		b.Execute(func() error {
+			ts = ts.Add(d)
+			return nil
+		})
+	}
+
+	h, ok := b.Latency()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), h.Count)
+	assert.Equal(t, 1006*time.Millisecond, h.Sum)
+	assert.Equal(t, 251500*time.Microsecond, h.Mean())
+	assert.Equal(t, 1024*time.Microsecond, h.Percentile(0))
+	assert.Equal(t, 4096*time.Microsecond, h.Percentile(50))
+	assert.Equal(t, 1048576*time.Microsecond, h.Percentile(99))
+}
+
+func TestBreaker_StatsSampling(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithLatencyHistogram(),
+		WithStatsSampling(10),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		b.Execute(func() error { return nil })
+	}
+	h, _ := b.Latency()
+	assert.Equal(t, uint64(10), h.Count)
+	assert.Equal(t, uint32(100), b.Counts().Total)
+
+	_, err = New(time.Minute, time.Minute, WithStatsSampling(0))
+	assert.Error(t, err)
+}
+
+func TestHistogram_Buckets(t *testing.T) {
+	assert.Equal(t, 0, bucketOf(0))
+	assert.Equal(t, 0, bucketOf(time.Microsecond))
+	assert.Equal(t, 1, bucketOf(2*time.Microsecond))
+	assert.Equal(t, 2, bucketOf(3*time.Microsecond))
+	assert.Equal(t, HistogramBuckets-1, bucketOf(time.Hour))
+	assert.Equal(t, Histogram{}.Percentile(99), time.Duration(0))
+}
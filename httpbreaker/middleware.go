@@ -0,0 +1,113 @@
+package httpbreaker
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// errHandlerPanic reports a panicking handler to the breaker
+var errHandlerPanic = errors.New("httpbreaker: handler panicked")
+
+// Middleware runs the requests of next with the breaker, the rejected requests
+// are answered with 503 Service Unavailable, or by WithReject, and the 5xx
+// responses and the panics of next count as failures. The http.Flusher and
+// http.Hijacker of the response writer are forwarded, e.g. for the server-sent
+// events and the websockets.
+func Middleware(b *easybreaker.Breaker, next http.Handler, opts ...MiddlewareOption) http.Handler {
+	m := newMiddleware(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.serve(b, next, w, r)
+	})
+}
+
+// RouteMiddleware is Middleware with a breaker per route taken from pool,
+// the route of a request is given by route, e.g. the matched pattern of
+// the router, so the endpoints are isolated from each other without
+// a breaker per raw URL.
+func RouteMiddleware(pool *easybreaker.Pool, route func(*http.Request) string, next http.Handler, opts ...MiddlewareOption) http.Handler {
+	m := newMiddleware(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := pool.Get(route(r))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		m.serve(b, next, w, r)
+	})
+}
+
+// MuxPattern returns the route of RouteMiddleware as the pattern of mux
+// matching the request, the requests matching no pattern share a route.
+func MuxPattern(mux *http.ServeMux) func(*http.Request) string {
+	return func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
+}
+
+func (m *middleware) serve(b *easybreaker.Breaker, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if err := b.Allow(); err != nil {
+		m.reject(w, r, b)
+		return
+	}
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		if p := recover(); p != nil {
+			b.Done(errHandlerPanic)
+			panic(p)
+		}
+		if sw.status >= http.StatusInternalServerError {
+			b.Done(errServerError)
+		} else {
+			b.Done(nil)
+		}
+	}()
+	next.ServeHTTP(sw, r)
+}
+
+// statusWriter records the status code of the response
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher if the response writer does.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, it fails if the response writer doesn't.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httpbreaker: response writer can't be hijacked")
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the response writer for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
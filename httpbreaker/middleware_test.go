@@ -0,0 +1,105 @@
+package httpbreaker
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	b := newBreaker(t)
+	status := http.StatusOK
+	h := Middleware(b, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, easybreaker.Counts{Total: 1, Successes: 1}, b.Counts())
+
+	status = http.StatusInternalServerError
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, easybreaker.StateOpen, b.State())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestMiddleware_Writer(t *testing.T) {
+	b := newBreaker(t)
+	h := Middleware(b, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/events":
+			w.(http.Flusher).Flush()
+		case "/ws":
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+			rw.Flush()
+		case "/panic":
+			panic("boom")
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(t, rec.Flushed)
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/ws")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	// the handler returns once the hijacked connection is closed
+	assert.Eventually(t, func() bool { return b.Counts().Successes == 2 }, time.Second, time.Millisecond)
+
+	// the panic is counted as a failure and propagated
+	assert.Panics(t, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	})
+	assert.Equal(t, easybreaker.StateOpen, b.State())
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	pool, err := easybreaker.NewPool(10, time.Minute, time.Minute)
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	h := RouteMiddleware(pool, MuxPattern(mux), mux)
+
+	for _, c := range []struct {
+		path string
+		code int
+	}{
+		{"/users/1", http.StatusInternalServerError},
+		{"/users/2", http.StatusServiceUnavailable},
+		{"/health", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+		assert.Equal(t, c.code, rec.Code, c.path)
+	}
+
+	// a breaker per pattern, not per URL
+	assert.Equal(t, 2, pool.Len())
+	b, _ := pool.Get("/health")
+	assert.Equal(t, easybreaker.StateClosed, b.State())
+}
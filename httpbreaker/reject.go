@@ -0,0 +1,95 @@
+package httpbreaker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"text/template"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Reject writes the response of a request rejected by the breaker of the
+// middlewares, 503 Service Unavailable by default.
+type Reject func(w http.ResponseWriter, r *http.Request, b *easybreaker.Breaker)
+
+// RejectData is the data of the body templates of Synthesize.
+type RejectData struct {
+	Method string
+	Path   string
+	State  string // the state of the breaker, open or half-open
+}
+
+// Synthesize returns the Reject answering with status, the content type,
+// the headers and the body rendered by the text/template body with RejectData,
+// so the rejections match the error envelope of the API. The template isn't
+// escaped, the json function encodes a value, e.g. the path chosen by the
+// client, as a JSON value, quotes included:
+//
+//	reject, err := httpbreaker.Synthesize(http.StatusServiceUnavailable, "application/json", nil,
+//		`{"error": {"code": "unavailable", "message": {{json (printf "%s is temporarily unavailable" .Path)}}}}`)
+//	handler := httpbreaker.Middleware(breaker, next, httpbreaker.WithReject(reject))
+func Synthesize(status int, contentType string, header http.Header, body string) (Reject, error) {
+	if status < 100 || status > 999 {
+		return nil, errors.New("httpbreaker: invalid status code")
+	}
+	tmpl, err := template.New("reject").Funcs(template.FuncMap{"json": encodeJSON}).Parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, b *easybreaker.Breaker) {
+		var buf bytes.Buffer
+		data := RejectData{Method: r.Method, Path: r.URL.Path, State: b.State().String()}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			reject(w, r, b)
+			return
+		}
+
+		for k, v := range header {
+			w.Header()[k] = append([]string(nil), v...)
+		}
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+		w.WriteHeader(status)
+		w.Write(buf.Bytes())
+	}, nil
+}
+
+// encodeJSON is the json function of the templates of Synthesize.
+func encodeJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// MiddlewareOption sets up Middleware and RouteMiddleware.
+type MiddlewareOption func(*middleware)
+
+type middleware struct {
+	reject Reject
+}
+
+// Reject writes the responses of the rejected requests.
+func WithReject(fn Reject) MiddlewareOption {
+	return func(m *middleware) {
+		if fn != nil {
+			m.reject = fn
+		}
+	}
+}
+
+func newMiddleware(opts []MiddlewareOption) *middleware {
+	m := &middleware{reject: reject}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func reject(w http.ResponseWriter, r *http.Request, b *easybreaker.Breaker) {
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
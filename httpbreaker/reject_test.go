@@ -0,0 +1,45 @@
+package httpbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSynthesize(t *testing.T) {
+	reject, err := Synthesize(http.StatusTooManyRequests, "application/json", http.Header{"Retry-After": {"30"}},
+		`{"error": "{{.Method}} {{.Path}} unavailable, breaker {{.State}}"}`)
+	assert.NoError(t, err)
+
+	b := newBreaker(t)
+	b.ForceOpen()
+	h := Middleware(b, http.NotFoundHandler(), WithReject(reject))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	assert.Equal(t, `{"error": "POST /orders unavailable, breaker open"}`, rec.Body.String())
+
+	// the path chosen by the client is encoded by json
+	reject, err = Synthesize(http.StatusServiceUnavailable, "application/json", nil,
+		`{"error": {"code": "unavailable", "path": {{json .Path}}}}`)
+	assert.NoError(t, err)
+	h = Middleware(b, http.NotFoundHandler(), WithReject(reject))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, `/x%22,%22admin%22:true,%22y%5C`, nil))
+	var envelope struct {
+		Error map[string]interface{} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &envelope))
+	assert.Equal(t, map[string]interface{}{"code": "unavailable", "path": `/x","admin":true,"y\`}, envelope.Error)
+
+	_, err = Synthesize(0, "", nil, "")
+	assert.Error(t, err)
+	_, err = Synthesize(http.StatusServiceUnavailable, "", nil, "{{")
+	assert.Error(t, err)
+}
@@ -0,0 +1,320 @@
+// Package httpbreaker protects HTTP clients with a circuit breaker.
+package httpbreaker
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// StaleHeader marks the cached responses served while the breaker is open.
+const StaleHeader = "X-Breaker-Stale"
+
+// DefaultMaxStaleEntries is the number of the responses cached by WithMaxStale
+// unless set by WithMaxStaleEntries.
+const DefaultMaxStaleEntries = 1024
+
+// DefaultMaxStaleBody is the size of the largest body cached by WithMaxStale
+// unless set by WithMaxStaleBody.
+const DefaultMaxStaleBody = 1 << 20
+
+// errServerError reports a failed response to the breaker
+var errServerError = errors.New("httpbreaker: server error")
+
+// Transport is a http.RoundTripper running the requests with a breaker.
+type Transport struct {
+	base     http.RoundTripper
+	breaker  *easybreaker.Breaker
+	isFailed func(*http.Response) bool
+	maxStale time.Duration
+	entries  int
+	maxBody  int64
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*list.Element // the last response by method and URL
+	lru   *list.List               // the cached responses, the most recent first
+}
+
+type cachedResponse struct {
+	key        string
+	vary       []string // the names of the Vary headers
+	variant    string   // the key including the values of the Vary headers
+	status     string
+	statusCode int
+	proto      string
+	protoMajor int
+	protoMinor int
+	header     http.Header
+	body       []byte
+	storedAt   time.Time
+}
+
+type OptionCall func(*Transport) error
+
+// IsFailed decides whether a response counts as a failure, by default the 5xx ones.
+func WithIsFailed(isFailed func(*http.Response) bool) OptionCall {
+	return func(t *Transport) error {
+		if isFailed == nil {
+			return errors.New("httpbreaker: isFailed must be defined")
+		}
+		t.isFailed = isFailed
+		return nil
+	}
+}
+
+// MaxStale enables serving the last successful response of a GET request
+// while the breaker is open, as long as it's not older than maxStale.
+// The last response of a method and URL is cached, it's served only to the
+// requests with the same values of the headers named by its Vary header.
+// The requests with credentials, an Authorization or a Cookie header, and
+// the responses with Cache-Control private or no-store or Vary * are not
+// cached. The served responses are marked with StaleHeader and the Age header.
+func WithMaxStale(maxStale time.Duration) OptionCall {
+	return func(t *Transport) error {
+		if maxStale <= 0 {
+			return errors.New("httpbreaker: maxStale must be positive")
+		}
+		t.maxStale = maxStale
+		return nil
+	}
+}
+
+// MaxStaleEntries bounds the number of the responses cached by WithMaxStale,
+// the least recently stored ones are evicted, DefaultMaxStaleEntries by default.
+func WithMaxStaleEntries(n int) OptionCall {
+	return func(t *Transport) error {
+		if n <= 0 {
+			return errors.New("httpbreaker: maxStaleEntries must be positive")
+		}
+		t.entries = n
+		return nil
+	}
+}
+
+// MaxStaleBody bounds the size of the bodies cached by WithMaxStale,
+// DefaultMaxStaleBody by default. The larger responses are streamed
+// through without being cached.
+func WithMaxStaleBody(n int64) OptionCall {
+	return func(t *Transport) error {
+		if n <= 0 {
+			return errors.New("httpbreaker: maxStaleBody must be positive")
+		}
+		t.maxBody = n
+		return nil
+	}
+}
+
+// NewTransport wraps base, http.DefaultTransport if nil, with the breaker.
+func NewTransport(base http.RoundTripper, b *easybreaker.Breaker, fns ...OptionCall) (*Transport, error) {
+	if b == nil {
+		return nil, errors.New("httpbreaker: breaker must be set")
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t := &Transport{
+		base:     base,
+		breaker:  b,
+		isFailed: isServerError,
+		entries:  DefaultMaxStaleEntries,
+		maxBody:  DefaultMaxStaleBody,
+		now:      b.Clock().Now,
+		cache:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+	for _, fn := range fns {
+		if err := fn(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func isServerError(resp *http.Response) bool {
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// RoundTrip implements http.RoundTripper, it returns easybreaker.ErrBreakerOpen
+// when the breaker rejects the request and there is no fresh cached response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		if resp := t.stale(req); resp != nil {
+			return resp, nil
+		}
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.breaker.Done(err)
+		return nil, err
+	}
+	if t.isFailed(resp) {
+		t.breaker.Done(errServerError)
+		return resp, nil
+	}
+	t.breaker.Done(nil)
+
+	if t.maxStale > 0 && cacheable(req, resp) {
+		return t.store(req, resp)
+	}
+	return resp, nil
+}
+
+// cacheable reports whether the response can be served to other requests.
+func cacheable(req *http.Request, resp *http.Response) bool {
+	if req.Method != http.MethodGet || resp.StatusCode != http.StatusOK {
+		return false
+	}
+	if req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+		return false
+	}
+	for _, directive := range strings.Split(resp.Header.Get("Cache-Control"), ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "private", "no-store":
+			return false
+		}
+	}
+	for _, name := range varyNames(resp.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// varyNames returns the sorted canonical names of the Vary headers.
+func varyNames(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cacheKey returns the key of the response to req varying by the names.
+func cacheKey(req *http.Request, names []string) string {
+	var key strings.Builder
+	key.WriteString(req.Method)
+	key.WriteByte(' ')
+	key.WriteString(req.URL.String())
+	for _, name := range names {
+		key.WriteByte('\n')
+		key.WriteString(name)
+		key.WriteByte(':')
+		key.WriteString(strings.Join(req.Header.Values(name), ","))
+	}
+	return key.String()
+}
+
+// store caches the response unless its body is larger than maxBody,
+// the body read so far is replayed before the rest then.
+func (t *Transport) store(req *http.Request, resp *http.Response) (*http.Response, error) {
+	if resp.ContentLength > t.maxBody {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.maxBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	names := varyNames(resp.Header)
+	cached := &cachedResponse{
+		key:        cacheKey(req, nil),
+		vary:       names,
+		variant:    cacheKey(req, names),
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		proto:      resp.Proto,
+		protoMajor: resp.ProtoMajor,
+		protoMinor: resp.ProtoMinor,
+		header:     resp.Header.Clone(),
+		body:       body,
+		storedAt:   t.now(),
+	}
+
+	t.mu.Lock()
+	if e, ok := t.cache[cached.key]; ok {
+		t.lru.Remove(e)
+	}
+	t.cache[cached.key] = t.lru.PushFront(cached)
+	for t.lru.Len() > t.entries {
+		t.evict(t.lru.Back())
+	}
+	t.mu.Unlock()
+	return resp, nil
+}
+
+func (t *Transport) stale(req *http.Request) *http.Response {
+	if t.maxStale == 0 || req.Method != http.MethodGet {
+		return nil
+	}
+
+	t.mu.Lock()
+	e, ok := t.cache[cacheKey(req, nil)]
+	if !ok {
+		t.mu.Unlock()
+		return nil
+	}
+	cached := e.Value.(*cachedResponse)
+	if cached.variant != cacheKey(req, cached.vary) {
+		t.mu.Unlock()
+		return nil
+	}
+	age := t.now().Sub(cached.storedAt)
+	if age > t.maxStale {
+		t.evict(e)
+		t.mu.Unlock()
+		return nil
+	}
+	t.mu.Unlock()
+
+	header := cached.header.Clone()
+	header.Set(StaleHeader, "true")
+	header.Set("Age", strconv.Itoa(int(age/time.Second)))
+	header.Add("Warning", `110 - "Response is Stale"`)
+
+	return &http.Response{
+		Status:        cached.status,
+		StatusCode:    cached.statusCode,
+		Proto:         cached.proto,
+		ProtoMajor:    cached.protoMajor,
+		ProtoMinor:    cached.protoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.body)),
+		ContentLength: int64(len(cached.body)),
+		Request:       req,
+	}
+}
+
+// evict removes a cached response, the caller must hold mu.
+func (t *Transport) evict(e *list.Element) {
+	t.lru.Remove(e)
+	delete(t.cache, e.Value.(*cachedResponse).key)
+}
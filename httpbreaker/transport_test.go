@@ -0,0 +1,214 @@
+package httpbreaker
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBreaker(t *testing.T) *easybreaker.Breaker {
+	b, err := easybreaker.New(
+		time.Minute, time.Minute,
+		easybreaker.WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures > 0 },
+			func(uint32, uint32) bool { return false },
+		),
+	)
+	assert.NoError(t, err)
+	return b
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	b := newBreaker(t)
+	tr, err := NewTransport(nil, b)
+	assert.NoError(t, err)
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get(srv.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, easybreaker.Counts{Total: 1, Successes: 1}, b.Counts())
+
+	status = http.StatusBadGateway
+	resp, err = client.Get(srv.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, easybreaker.StateOpen, b.State())
+
+	_, err = client.Get(srv.URL)
+	assert.True(t, errors.Is(err, easybreaker.ErrBreakerOpen))
+}
+
+func TestTransport_MaxStale(t *testing.T) {
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	ts := time.Unix(1520100000, 0)
+	tr, err := NewTransport(nil, newBreaker(t), WithMaxStale(time.Minute))
+	assert.NoError(t, err)
+	tr.now = func() time.Time { return ts }
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get(srv.URL)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "hello", string(body))
+	assert.Empty(t, resp.Header.Get(StaleHeader))
+
+	fail = true
+	resp, err = client.Get(srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	ts = ts.Add(30 * time.Second)
+	resp, err = client.Get(srv.URL)
+	assert.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, "true", resp.Header.Get(StaleHeader))
+	assert.Equal(t, "30", resp.Header.Get("Age"))
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Equal(t, 1, resp.ProtoMajor)
+
+	// no cached response for the other URLs
+	_, err = client.Get(srv.URL + "/other")
+	assert.True(t, errors.Is(err, easybreaker.ErrBreakerOpen))
+
+	ts = ts.Add(31 * time.Second)
+	_, err = client.Get(srv.URL)
+	assert.True(t, errors.Is(err, easybreaker.ErrBreakerOpen))
+}
+
+func TestTransport_Cacheable(t *testing.T) {
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "max-age=60, private")
+		case "/vary":
+			w.Header().Set("Vary", "Accept-Language")
+		}
+		w.Write([]byte(r.URL.Path + " " + r.Header.Get("Accept-Language")))
+	}))
+	defer srv.Close()
+
+	tr, err := NewTransport(nil, newBreaker(t), WithMaxStale(time.Minute), WithMaxStaleEntries(2))
+	assert.NoError(t, err)
+	client := &http.Client{Transport: tr}
+	get := func(path string, header ...string) (string, error) {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), nil
+	}
+
+	for _, path := range []string{"/evicted", "/private", "/vary", "/user", "/public"} {
+		header := []string{"Accept-Language", "fr"}
+		if path == "/user" {
+			header = append(header, "Authorization", "Bearer token")
+		}
+		_, err := get(path, header...)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, tr.lru.Len())
+	fail = true
+	get("/")
+
+	// the cached variant only
+	body, err := get("/vary", "Accept-Language", "fr")
+	assert.NoError(t, err)
+	assert.Equal(t, "/vary fr", body)
+	_, err = get("/vary", "Accept-Language", "de")
+	assert.True(t, errors.Is(err, easybreaker.ErrBreakerOpen))
+
+	body, err = get("/public")
+	assert.NoError(t, err)
+	assert.Equal(t, "/public fr", body)
+
+	// neither private, nor with credentials, nor evicted
+	for _, path := range []string{"/private", "/user", "/evicted"} {
+		_, err = get(path, "Accept-Language", "fr")
+		assert.True(t, errors.Is(err, easybreaker.ErrBreakerOpen), path)
+	}
+}
+
+func TestTransport_MaxStaleBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := "0123456789"
+		if r.URL.Path == "/small" {
+			body = "0123"
+		}
+		if r.URL.Path == "/streamed" {
+			// no Content-Length, the body is chunked
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	tr, err := NewTransport(nil, newBreaker(t), WithMaxStale(time.Minute), WithMaxStaleBody(4))
+	assert.NoError(t, err)
+	client := &http.Client{Transport: tr}
+
+	for _, path := range []string{"/small", "/large", "/streamed"} {
+		resp, err := client.Get(srv.URL + path)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err)
+		if path == "/small" {
+			assert.Equal(t, "0123", string(body))
+		} else {
+			assert.Equal(t, "0123456789", string(body), path)
+		}
+	}
+	// the large bodies are streamed through without being cached
+	assert.Equal(t, 1, tr.lru.Len())
+	_, ok := tr.cache[http.MethodGet+" "+srv.URL+"/small"]
+	assert.True(t, ok)
+}
+
+func TestNewTransport(t *testing.T) {
+	_, err := NewTransport(nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewTransport(nil, newBreaker(t), WithMaxStale(0))
+	assert.Error(t, err)
+	_, err = NewTransport(nil, newBreaker(t), WithMaxStaleEntries(0))
+	assert.Error(t, err)
+	_, err = NewTransport(nil, newBreaker(t), WithMaxStaleBody(0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package easybreaker
+
+import (
+	"errors"
+	"sort"
+)
+
+// Impacts declares the user-facing features or endpoints protected by the
+// breaker, so the registry reports the features degraded by the breakers
+// which are not closed, e.g. on a product status page.
+func WithImpacts(features ...string) OptionCall {
+	return func(b *Breaker) error {
+		if len(features) == 0 {
+			return errors.New("circuit: impacts must be defined")
+		}
+		for _, feature := range features {
+			if feature == "" {
+				return errors.New("circuit: impacted feature must be named")
+			}
+		}
+		b.impacts = append(b.impacts, features...)
+		return nil
+	}
+}
+
+// Impacts returns the features protected by the breaker.
+func (b *Breaker) Impacts() []string {
+	return append([]string(nil), b.impacts...)
+}
+
+// Impact is a user-facing feature degraded by some breakers.
+type Impact struct {
+	Feature  string
+	Breakers []OpenBreaker // the breakers of the feature which are not closed, by name
+}
+
+// Impacts returns the features degraded by the breakers which are not closed,
+// sorted by feature.
+func (r *Registry) Impacts() []Impact {
+	byFeature := make(map[string][]OpenBreaker)
+	r.ForEach(func(name string, b *Breaker) bool {
+		if len(b.impacts) == 0 {
+			return true
+		}
+		state := b.State()
+		if state == StateClosed {
+			return true
+		}
+
+		open := OpenBreaker{Name: name, State: state}
+		if since, ok := b.OpenSince(); ok {
+			open.Since = since
+			open.Duration = b.now().Sub(since)
+		}
+		for _, feature := range b.impacts {
+			byFeature[feature] = append(byFeature[feature], open)
+		}
+		return true
+	})
+
+	impacts := make([]Impact, 0, len(byFeature))
+	for feature, breakers := range byFeature {
+		impacts = append(impacts, Impact{Feature: feature, Breakers: breakers})
+	}
+	sort.Slice(impacts, func(i, j int) bool { return impacts[i].Feature < impacts[j].Feature })
+	return impacts
+}
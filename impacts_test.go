@@ -0,0 +1,31 @@
+package easybreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Impacts(t *testing.T) {
+	r := NewRegistry(time.Minute, time.Minute)
+	payments, _ := r.Configure("payments", time.Minute, time.Minute, WithImpacts("checkout"))
+	search, _ := r.Configure("search", time.Minute, time.Minute, WithImpacts("search", "checkout"))
+	r.Configure("ads", time.Minute, time.Minute, WithImpacts("home"))
+	r.Get("logs")
+	assert.Equal(t, []string{"search", "checkout"}, search.Impacts())
+	assert.Empty(t, r.Impacts())
+
+	payments.ForceOpen()
+	search.ForceOpen()
+	impacts := r.Impacts()
+	assert.Len(t, impacts, 2)
+	assert.Equal(t, "checkout", impacts[0].Feature)
+	assert.Equal(t, "payments", impacts[0].Breakers[0].Name)
+	assert.Equal(t, "search", impacts[0].Breakers[1].Name)
+	assert.Equal(t, "search", impacts[1].Feature)
+	assert.Equal(t, StateOpen, impacts[1].Breakers[0].State)
+
+	_, err := New(time.Minute, time.Minute, WithImpacts(), WithImpacts(""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// IntervalStats is the summary of a finished window.
+type IntervalStats struct {
+	Window
+
+	// the failures of the window by category, the timeouts and the other
+	// failures without WithTaxonomy
+	Breakdown map[Category]uint64
+
+	// the latencies measured during the window, empty without WithLatencyHistogram
+	Latency       Histogram
+	P50, P90, P99 time.Duration
+}
+
+type intervalSummary struct {
+	fn func(IntervalStats)
+
+	mu        sync.Mutex // serializes the summaries of the windows finished concurrently
+	latency   Histogram  // the cumulative histogram at the end of the last window
+	breakdown map[Category]uint64
+}
+
+// IntervalSummary calls fn with the summary of every finished window,
+// at the rollovers of the closed state and on the transitions, e.g. to log
+// the totals, the failure breakdown and the latency percentiles periodically
+// without sampling the requests. fn is called synchronously by the request
+// finishing the window and must be fast.
+func WithIntervalSummary(fn func(IntervalStats)) OptionCall {
+	return func(b *Breaker) error {
+		if fn == nil {
+			return errors.New("circuit: interval summary must be defined")
+		}
+		b.summary = &intervalSummary{fn: fn}
+		return nil
+	}
+}
+
+// summarize reports the finished window w to the interval summary.
+func (b *Breaker) summarize(w Window) {
+	s := b.summary
+	s.mu.Lock()
+	stats := IntervalStats{Window: w}
+
+	if b.breakdown != nil {
+		current := b.Breakdown()
+		stats.Breakdown = make(map[Category]uint64, len(current))
+		for category, n := range current {
+			if d := n - s.breakdown[category]; d > 0 {
+				stats.Breakdown[category] = d
+			}
+		}
+		s.breakdown = current
+	} else {
+		stats.Breakdown = make(map[Category]uint64, 2)
+		if w.Counts.Timeouts > 0 {
+			stats.Breakdown[CategoryTimeout] = uint64(w.Counts.Timeouts)
+		}
+		if w.Counts.Failures > w.Counts.Timeouts {
+			stats.Breakdown[CategoryOther] = uint64(w.Counts.Failures - w.Counts.Timeouts)
+		}
+	}
+
+	if b.latency != nil {
+		current := b.latency.snapshot()
+		stats.Latency = current.sub(s.latency)
+		s.latency = current
+		stats.P50 = stats.Latency.Percentile(50)
+		stats.P90 = stats.Latency.Percentile(90)
+		stats.P99 = stats.Latency.Percentile(99)
+	}
+	s.mu.Unlock()
+
+	s.fn(stats)
+}
+
+// sub returns the latencies of h observed since the earlier snapshot prev.
+func (h Histogram) sub(prev Histogram) Histogram {
+	for i := range h.Buckets {
+		h.Buckets[i] -= prev.Buckets[i]
+	}
+	h.Count -= prev.Count
+	h.Sum -= prev.Sum
+	return h
+}
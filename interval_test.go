@@ -0,0 +1,62 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_IntervalSummary(t *testing.T) {
+	_, err := New(time.Minute, time.Minute, WithIntervalSummary(nil))
+	assert.Error(t, err)
+
+	var summaries []IntervalStats
+	ts := time.Unix(1520100000, 0)
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithLatencyHistogram(),
+		WithFailureThreshold(0.9),
+		WithIntervalSummary(func(s IntervalStats) { summaries = append(summaries, s) }),
+		WithNow(func() time.Time { return ts }),
+	)
+	assert.NoError(t, err)
+
+	run := func(d time.Duration, err error) {
+		b.Execute(func() error {
+			ts = ts.Add(d)
+			return err
+		})
+	}
+	run(time.Millisecond, nil)
+	run(time.Millisecond, nil)
+	run(time.Second, context.DeadlineExceeded)
+	run(time.Millisecond, errors.New("boom"))
+
+	ts = ts.Add(time.Minute)
+	run(3*time.Millisecond, nil)
+
+	assert.Len(t, summaries, 1)
+	s := summaries[0]
+	assert.Equal(t, uint64(0), s.Epoch)
+	assert.Equal(t, StateClosed, s.State)
+	assert.Equal(t, Counts{Total: 4, Failures: 2, Successes: 2, Timeouts: 1}, s.Counts)
+	assert.Equal(t, map[Category]uint64{CategoryTimeout: 1, CategoryOther: 1}, s.Breakdown)
+	assert.Equal(t, uint64(4), s.Latency.Count)
+	assert.Equal(t, 1024*time.Microsecond, s.P50)
+	assert.Equal(t, 1048576*time.Microsecond, s.P99)
+
+	// only the latencies of the window are summarized
+	ts = ts.Add(time.Minute)
+	run(time.Millisecond, nil)
+
+	assert.Len(t, summaries, 2)
+	s = summaries[1]
+	assert.Equal(t, uint64(1), s.Epoch)
+	assert.Equal(t, uint32(1), s.Counts.Total)
+	assert.Empty(t, s.Breakdown)
+	assert.Equal(t, uint64(1), s.Latency.Count)
+	assert.Equal(t, 4096*time.Microsecond, s.P99)
+}
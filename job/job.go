@@ -0,0 +1,181 @@
+// Package job runs the scheduled tasks through a breaker: a tick is skipped
+// while the breaker is open and, optionally, retried once the cooldown is
+// over instead of waiting for the next tick, the outcome of the task is fed
+// back to the breaker, so the batch jobs stop hammering an unhealthy
+// dependency on every tick. Job implements the Job interface of the usual
+// cron schedulers:
+//
+//	j, err := job.New(breaker, syncInvoices, job.WithRetry(time.Minute))
+//	defer j.Close()
+//	c.AddJob("*/5 * * * *", j)
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// ErrSkipped is returned by RunCtx while the breaker is open,
+// it wraps the rejection of the breaker.
+var ErrSkipped = errors.New("job: skipped")
+
+// Job is a scheduled task gated by a breaker.
+type Job struct {
+	breaker *easybreaker.Breaker
+	task    func(ctx context.Context) error
+	retry   time.Duration
+	onError func(error)
+
+	runs    uint64
+	skipped uint64
+
+	mu     sync.Mutex
+	timer  easybreaker.Timer // the pending retry
+	stop   chan struct{}
+	closed bool
+}
+
+type OptionCall func(*Job) error
+
+// Retry reschedules a skipped tick after the delay, e.g. the cooldown of
+// the breaker, instead of waiting for the next tick. A single retry is
+// pending at once, it's canceled by a tick running the task.
+func WithRetry(after time.Duration) OptionCall {
+	return func(j *Job) error {
+		if after <= 0 {
+			return errors.New("job: retry must be positive")
+		}
+		j.retry = after
+		return nil
+	}
+}
+
+// OnError is called with the errors of the ticks run by Run and the retries,
+// including ErrSkipped, the schedulers discard them.
+func WithOnError(fn func(error)) OptionCall {
+	return func(j *Job) error {
+		if fn == nil {
+			return errors.New("job: error handler must be defined")
+		}
+		j.onError = fn
+		return nil
+	}
+}
+
+// New returns the task gated by b, the retries use the clock of b.
+func New(b *easybreaker.Breaker, task func(ctx context.Context) error, fns ...OptionCall) (*Job, error) {
+	if b == nil {
+		return nil, errors.New("job: breaker must be set")
+	}
+	if task == nil {
+		return nil, errors.New("job: task must be defined")
+	}
+
+	j := &Job{breaker: b, task: task}
+	for _, fn := range fns {
+		if err := fn(j); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// Run runs a tick with the background context, it's the method called by
+// the schedulers.
+func (j *Job) Run() {
+	if err := j.RunCtx(context.Background()); err != nil && j.onError != nil {
+		j.onError(err)
+	}
+}
+
+// RunCtx runs the task unless the breaker is open, it returns ErrSkipped
+// then and schedules the retry. The error of the task is counted by the
+// breaker and returned.
+func (j *Job) RunCtx(ctx context.Context) error {
+	if err := j.breaker.Allow(); err != nil {
+		atomic.AddUint64(&j.skipped, 1)
+		j.reschedule()
+		return fmt.Errorf("%w: %w", ErrSkipped, err)
+	}
+	j.cancel()
+
+	atomic.AddUint64(&j.runs, 1)
+	err := j.task(ctx)
+	j.breaker.Done(err)
+	return err
+}
+
+// Runs returns the number of the ticks which ran the task.
+func (j *Job) Runs() uint64 {
+	return atomic.LoadUint64(&j.runs)
+}
+
+// Skipped returns the number of the ticks skipped while the breaker was open.
+func (j *Job) Skipped() uint64 {
+	return atomic.LoadUint64(&j.skipped)
+}
+
+// Close cancels the pending retry, the ticks still run.
+func (j *Job) Close() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.closed = true
+	j.cancelLocked()
+}
+
+func (j *Job) reschedule() {
+	if j.retry == 0 {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed || j.timer != nil {
+		return
+	}
+	t := j.breaker.Clock().NewTimer(j.retry)
+	stop := make(chan struct{})
+	j.timer, j.stop = t, stop
+
+	go func() {
+		select {
+		case <-t.C():
+		case <-stop:
+			return
+		}
+
+		j.mu.Lock()
+		if j.timer != t {
+			j.mu.Unlock()
+			return
+		}
+		j.timer, j.stop = nil, nil
+		j.mu.Unlock()
+
+		j.Run()
+	}()
+}
+
+func (j *Job) cancel() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.cancelLocked()
+}
+
+// cancelLocked cancels the pending retry, the caller must hold mu.
+func (j *Job) cancelLocked() {
+	if j.timer == nil {
+		return
+	}
+	j.timer.Stop()
+	close(j.stop)
+	j.timer, j.stop = nil, nil
+}
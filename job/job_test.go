@@ -0,0 +1,97 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/rfyiamcool/easybreaker/breakertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJob(t *testing.T) {
+	clock := breakertest.NewClock(time.Unix(1520100000, 0))
+	b, err := easybreaker.New(
+		time.Minute, 2*time.Minute,
+		easybreaker.WithClock(clock),
+		easybreaker.WithLeastReqs(1),
+	)
+	assert.NoError(t, err)
+
+	ran := make(chan struct{}, 1)
+	taskErr := errors.New("failed")
+	var errs []error
+	j, err := New(b, func(ctx context.Context) error {
+		defer func() { ran <- struct{}{} }()
+		return taskErr
+	}, WithRetry(2*time.Minute), WithOnError(func(err error) { errs = append(errs, err) }))
+	assert.NoError(t, err)
+	defer j.Close()
+
+	// the failure trips the breaker
+	assert.Equal(t, taskErr, j.RunCtx(context.Background()))
+	<-ran
+	assert.Equal(t, easybreaker.StateOpen, b.State())
+
+	// the ticks are skipped, a single retry is pending
+	err = j.RunCtx(context.Background())
+	assert.True(t, errors.Is(err, ErrSkipped))
+	assert.True(t, errors.Is(err, easybreaker.ErrBreakerOpen))
+	j.Run()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, uint64(2), j.Skipped())
+	assert.Equal(t, uint64(1), j.Runs())
+
+	// the retry probes the dependency once the cooldown is over
+	taskErr = nil
+	clock.Advance(2 * time.Minute)
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("the retry did not run")
+	}
+	assert.Eventually(t, func() bool { return b.Counts().Successes == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, easybreaker.StateHalfOpen, b.State())
+	assert.Equal(t, uint64(2), j.Runs())
+	assert.Len(t, errs, 1)
+}
+
+func TestJob_Close(t *testing.T) {
+	clock := breakertest.NewClock(time.Unix(1520100000, 0))
+	b, err := easybreaker.New(time.Minute, time.Minute, easybreaker.WithClock(clock))
+	assert.NoError(t, err)
+	b.ForceOpen()
+
+	runs := 0
+	j, err := New(b, func(ctx context.Context) error { runs++; return nil }, WithRetry(time.Minute))
+	assert.NoError(t, err)
+
+	j.Run()
+	j.Close()
+	b.Release()
+	clock.Advance(time.Minute)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, runs)
+	assert.Equal(t, uint64(1), j.Skipped())
+
+	// the ticks still run
+	j.Run()
+	assert.Equal(t, 1, runs)
+}
+
+func TestNew(t *testing.T) {
+	b, err := easybreaker.New(time.Minute, time.Minute)
+	assert.NoError(t, err)
+	task := func(ctx context.Context) error { return nil }
+
+	_, err = New(nil, task)
+	assert.Error(t, err)
+	_, err = New(b, nil)
+	assert.Error(t, err)
+	_, err = New(b, task, WithRetry(0))
+	assert.Error(t, err)
+	_, err = New(b, task, WithOnError(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// LoadFunc is called with the load observed by ObserveLoad being in
+// the closed state. If it returns true, the circuit breaker will be placed
+// into the open state. The half-open state doesn't close while it holds.
+func WithLoadFunc(toOpen func(load int) bool) OptionCall {
+	return func(b *Breaker) error {
+		if toOpen == nil {
+			return errors.New("circuit: load func must be defined")
+		}
+		b.loadToOpen = toOpen
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
+// ObserveLoad feeds an external load signal, e.g. the depth of a queue or
+// the pending work, so the breaker can open before the requests fail.
+func (b *Breaker) ObserveLoad(load int) {
+	atomic.StoreInt64(&b.load, int64(load))
+	if b.loadToOpen == nil {
+		return
+	}
+
+	until := atomic.LoadInt64(&b.until)
+	if atomic.LoadInt32(&b.state) != closed || b.override() != forcedNone || !b.loadToOpen(load) || !b.guarded(closed, open) {
+		return
+	}
+
+	now := b.now().UnixNano()
+	if atomic.CompareAndSwapInt64(&b.until, until, now+b.cooldown) {
+		total, failures := unpackCounts(atomic.LoadUint64(&b.counts))
+		b.setTripReason(TripReason{Strategy: StrategyLoad, Value: float64(load)}, total, failures)
+		b.transit(closed, open)
+	}
+}
+
+// Load returns the last load observed by ObserveLoad.
+func (b *Breaker) Load() int {
+	return int(atomic.LoadInt64(&b.load))
+}
+
+func (b *Breaker) overloaded() bool {
+	return b.loadToOpen != nil && b.loadToOpen(b.Load())
+}
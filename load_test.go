@@ -0,0 +1,49 @@
+package easybreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_ObserveLoad(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithLeastReqs(1),
+		WithLoadFunc(func(load int) bool { return load > 100 }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.ObserveLoad(50)
+	assert.Equal(t, 50, b.Load())
+	assert.Equal(t, StateClosed, b.State())
+
+	b.ObserveLoad(150)
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, int64(1520100120000000000), b.until)
+
+	// the probes succeed, but the load is still high
+	b.now = now(1520100121)
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.Equal(t, ErrBreakerOpen, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateOpen, b.State())
+
+	b.now = now(1520100242)
+	b.ObserveLoad(10)
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestWithLoadFunc(t *testing.T) {
+	_, err := New(time.Minute, time.Minute, WithLoadFunc(nil))
+	assert.Error(t, err)
+
+	b, err := New(time.Minute, time.Minute)
+	assert.NoError(t, err)
+	b.ObserveLoad(1000)
+	assert.Equal(t, StateClosed, b.State())
+}
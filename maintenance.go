@@ -0,0 +1,112 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// MaintenanceWindow is a planned downtime of the dependency during which
+// the breaker is forced open, or forced closed, e.g. while the dependency
+// is expected to fail without the breaker tripping.
+type MaintenanceWindow struct {
+	Start time.Time
+	End   time.Time
+	Open  bool // forced open, forced closed otherwise
+}
+
+// Schedule returns the maintenance window holding at a time, if any.
+type Schedule interface {
+	At(now time.Time) (MaintenanceWindow, bool)
+}
+
+// ScheduleFunc is a Schedule function.
+type ScheduleFunc func(now time.Time) (MaintenanceWindow, bool)
+
+func (f ScheduleFunc) At(now time.Time) (MaintenanceWindow, bool) {
+	return f(now)
+}
+
+// Windows returns the schedule of explicit maintenance windows.
+func Windows(windows ...MaintenanceWindow) Schedule {
+	return ScheduleFunc(func(now time.Time) (MaintenanceWindow, bool) {
+		for _, w := range windows {
+			if !now.Before(w.Start) && now.Before(w.End) {
+				return w, true
+			}
+		}
+		return MaintenanceWindow{}, false
+	})
+}
+
+// Recurring returns the schedule of a maintenance window of length d starting
+// at the offset at since midnight, in the location of the time source, on the
+// given weekdays, every day if none, like a cron entry:
+//
+//	easybreaker.Recurring(2*time.Hour, 30*time.Minute, true, time.Sunday) // 0 2 * * 0 for 30m
+func Recurring(at time.Duration, d time.Duration, open bool, days ...time.Weekday) Schedule {
+	return ScheduleFunc(func(now time.Time) (MaintenanceWindow, bool) {
+		y, m, day := now.Date()
+		midnight := time.Date(y, m, day, 0, 0, 0, 0, now.Location())
+		// the windows of the previous days may still hold
+		for back := 0; time.Duration(back)*24*time.Hour <= at+d; back++ {
+			start := midnight.AddDate(0, 0, -back).Add(at)
+			if !weekdayIn(start.Weekday(), days) {
+				continue
+			}
+			if end := start.Add(d); !now.Before(start) && now.Before(end) {
+				return MaintenanceWindow{Start: start, End: end, Open: open}, true
+			}
+		}
+		return MaintenanceWindow{}, false
+	})
+}
+
+func weekdayIn(day time.Weekday, days []time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return len(days) == 0
+}
+
+// MaintenanceWindows forces the breaker open or closed automatically during
+// the maintenance windows of the schedule, for the planned downtimes without
+// manual toggling. ForceOpen, Disable and Release take precedence.
+func WithMaintenanceWindows(schedule Schedule) OptionCall {
+	return func(b *Breaker) error {
+		if schedule == nil {
+			return errors.New("circuit: maintenance schedule must be defined")
+		}
+		b.maintenance = schedule
+		return nil
+	}
+}
+
+// Maintenance returns the maintenance window holding now, if any.
+func (b *Breaker) Maintenance() (MaintenanceWindow, bool) {
+	if b.maintenance == nil {
+		return MaintenanceWindow{}, false
+	}
+	return b.maintenance.At(b.now())
+}
+
+// override returns the forced state of the manual control,
+// or of the maintenance window holding now.
+func (b *Breaker) override() int32 {
+	forced := atomic.LoadInt32(&b.forced)
+	if forced == forcedNone && b.chaosOpen() {
+		return forcedOpen
+	}
+	if forced != forcedNone || b.maintenance == nil {
+		return forced
+	}
+	if w, ok := b.maintenance.At(b.now()); ok {
+		if w.Open {
+			return forcedOpen
+		}
+		return forcedClosed
+	}
+	return forcedNone
+}
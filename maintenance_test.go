@@ -0,0 +1,72 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurring(t *testing.T) {
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		assert.NoError(t, err)
+		return ts
+	}
+
+	// 23:00 for 2 hours on Sundays
+	s := Recurring(23*time.Hour, 2*time.Hour, true, time.Sunday)
+	w, ok := s.At(at("2018-03-04T23:30:00Z"))
+	assert.True(t, ok)
+	assert.Equal(t, MaintenanceWindow{Start: at("2018-03-04T23:00:00Z"), End: at("2018-03-05T01:00:00Z"), Open: true}, w)
+	_, ok = s.At(at("2018-03-05T00:30:00Z"))
+	assert.True(t, ok)
+	_, ok = s.At(at("2018-03-05T01:00:00Z"))
+	assert.False(t, ok)
+	_, ok = s.At(at("2018-03-03T23:30:00Z"))
+	assert.False(t, ok)
+
+	// every day
+	_, ok = Recurring(0, time.Hour, false).At(at("2018-03-03T00:59:59Z"))
+	assert.True(t, ok)
+}
+
+func TestBreaker_MaintenanceWindows(t *testing.T) {
+	start := time.Unix(1520100000, 0)
+	b, err := New(time.Minute, time.Minute,
+		WithMaintenanceWindows(Windows(
+			MaintenanceWindow{Start: start, End: start.Add(time.Minute), Open: true},
+			MaintenanceWindow{Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)},
+		)),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, ErrBreakerOpen, b.Execute(func() error { return nil }))
+	w, ok := b.Maintenance()
+	assert.True(t, ok)
+	assert.True(t, w.Open)
+
+	// the manual control takes precedence
+	b.Disable()
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	b.Release()
+
+	b.now = now(1520100060)
+	assert.Equal(t, StateClosed, b.State())
+	_, ok = b.Maintenance()
+	assert.False(t, ok)
+
+	// forced closed, the failures don't trip
+	b.now = now(1520103600)
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateClosed, b.State())
+	b.now = now(1520107200)
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+
+	_, err = New(time.Minute, time.Minute, WithMaintenanceWindows(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+package easybreaker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// StrategyRatio selects WithFailureThreshold in a manifest.
+const StrategyRatio = "ratio"
+
+// ManifestVersion is the version of the format of the manifests,
+// the manifests of the previous versions are migrated on load.
+const ManifestVersion = 1
+
+// manifestMigrations upgrade a manifest of the version i to the version i+1.
+var manifestMigrations = []func(doc interface{}) interface{}{
+	// 0, the bare list of the breakers
+	func(doc interface{}) interface{} {
+		return map[interface{}]interface{}{"version": 1, "breakers": doc}
+	},
+}
+
+type manifest struct {
+	Version  int           `yaml:"version"`
+	Breakers []interface{} `yaml:"breakers"`
+}
+
+// ManifestBreaker is a breaker of a manifest, in YAML or JSON:
+//
+//	version: 1
+//	breakers:
+//	  - name: payments/stripe
+//	    interval: 1m
+//	    cooldown: 10s
+//	    least_reqs: 20
+//	    impacts: [checkout]
+//	    strategies:
+//	      - type: ratio
+//	        threshold: 0.5
+//	      - type: max-failures
+//	        threshold: 100
+//
+// The durations must be set, they are not inherited like with Configure.
+type ManifestBreaker struct {
+	Name       string             `yaml:"name"`
+	Interval   time.Duration      `yaml:"interval"`
+	Cooldown   time.Duration      `yaml:"cooldown"`
+	LeastReqs  uint32             `yaml:"least_reqs"`
+	Impacts    []string           `yaml:"impacts"`
+	Strategies []ManifestStrategy `yaml:"strategies"`
+}
+
+// ManifestStrategy selects a strategy opening the breaker, by type:
+//
+//	ratio         WithFailureThreshold(threshold)
+//	max-failures  WithMaxFailures(threshold)
+//	velocity      WithFailureVelocity(threshold, window)
+//	spike         WithSpikeDetection(threshold, intervals, least_reqs)
+type ManifestStrategy struct {
+	Type      string        `yaml:"type"`
+	Threshold float64       `yaml:"threshold"`
+	Window    time.Duration `yaml:"window"`
+	Intervals int           `yaml:"intervals"`
+	LeastReqs uint32        `yaml:"least_reqs"`
+}
+
+// ManifestEntry is the outcome of a breaker of a manifest.
+type ManifestEntry struct {
+	Name    string
+	Updated bool  // the breaker existed and was replaced
+	Err     error // the breaker is invalid, nothing was changed
+}
+
+// LoadManifest creates or replaces the breakers of a manifest, a YAML or JSON
+// document of ManifestBreaker. The unknown fields are errors, so are the typos.
+// The manifests of the previous versions, e.g. the bare list of the breakers
+// of the version 0, are migrated, the ones of a later version are refused.
+//
+// The manifest is applied atomically like with Warm, it returns the outcome
+// of every breaker, the error is not nil if the manifest or any of its
+// breakers is invalid, nothing is changed then.
+func (r *Registry) LoadManifest(rd io.Reader) ([]ManifestEntry, error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	items, err := decodeManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ManifestEntry, len(items))
+	defs := make([]Definition, len(items))
+	failed := false
+	for i, item := range items {
+		def, err := decodeManifestBreaker(item)
+		entries[i] = ManifestEntry{Name: def.Name, Err: err}
+		defs[i] = def
+		if err != nil {
+			failed = true
+		}
+	}
+	if failed {
+		return entries, errors.New("circuit: invalid manifest breakers")
+	}
+
+	existed, errs := r.apply(defs)
+	for i := range entries {
+		if errs != nil {
+			entries[i].Err = errs[i]
+		} else {
+			entries[i].Updated = existed[i]
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return entries, errors.New("circuit: invalid manifest breakers")
+	}
+	return entries, nil
+}
+
+// decodeManifest returns the breakers of a manifest migrated to ManifestVersion.
+func decodeManifest(data []byte) ([]interface{}, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("circuit: invalid manifest: %v", err)
+	}
+
+	version := 0
+	switch d := doc.(type) {
+	case []interface{}:
+	case map[interface{}]interface{}:
+		v, ok := d["version"].(int)
+		if !ok || v <= 0 {
+			return nil, errors.New("circuit: manifest version must be set")
+		}
+		version = v
+	default:
+		return nil, errors.New("circuit: invalid manifest")
+	}
+	if version > ManifestVersion {
+		return nil, fmt.Errorf("circuit: manifest version %d is newer than %d", version, ManifestVersion)
+	}
+	for ; version < ManifestVersion; version++ {
+		doc = manifestMigrations[version](doc)
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := yaml.UnmarshalStrict(data, &m); err != nil {
+		return nil, fmt.Errorf("circuit: invalid manifest: %v", err)
+	}
+	return m.Breakers, nil
+}
+
+// decodeManifestBreaker decodes a breaker of a manifest strictly,
+// the name is returned whenever it's readable.
+func decodeManifestBreaker(item interface{}) (Definition, error) {
+	data, err := yaml.Marshal(item)
+	if err != nil {
+		return Definition{}, err
+	}
+	var m ManifestBreaker
+	if err := yaml.UnmarshalStrict(data, &m); err != nil {
+		if fields, ok := item.(map[interface{}]interface{}); ok {
+			m.Name, _ = fields["name"].(string)
+		}
+		return Definition{Name: m.Name}, fmt.Errorf("circuit: breaker %q: %v", m.Name, err)
+	}
+
+	def := Definition{Name: m.Name, Interval: m.Interval, Cooldown: m.Cooldown}
+	if m.LeastReqs > 0 {
+		def.Options = append(def.Options, WithLeastReqs(m.LeastReqs))
+	}
+	if len(m.Impacts) > 0 {
+		def.Options = append(def.Options, WithImpacts(m.Impacts...))
+	}
+	for _, s := range m.Strategies {
+		fn, err := s.option()
+		if err != nil {
+			return def, fmt.Errorf("circuit: breaker %q: %w", m.Name, err)
+		}
+		def.Options = append(def.Options, fn)
+	}
+	return def, nil
+}
+
+func (s ManifestStrategy) option() (OptionCall, error) {
+	switch s.Type {
+	case StrategyRatio:
+		return WithFailureThreshold(s.Threshold), nil
+	case StrategyMaxFailures:
+		if s.Threshold != float64(uint32(s.Threshold)) {
+			return nil, errors.New("circuit: max failures must be an integer")
+		}
+		return WithMaxFailures(uint32(s.Threshold)), nil
+	case StrategyVelocity:
+		return WithFailureVelocity(s.Threshold, s.Window), nil
+	case StrategySpike:
+		return WithSpikeDetection(s.Threshold, s.Intervals, s.LeastReqs), nil
+	}
+	return nil, fmt.Errorf("circuit: unknown strategy %q", s.Type)
+}
@@ -0,0 +1,102 @@
+package easybreaker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_LoadManifest(t *testing.T) {
+	r := NewRegistry(time.Minute, 10*time.Second)
+	r.Get("search")
+
+	entries, err := r.LoadManifest(strings.NewReader(`
+- name: payments/stripe
+  interval: 1m
+  cooldown: 10s
+  least_reqs: 20
+  impacts: [checkout]
+  strategies:
+    - type: ratio
+      threshold: 0.5
+    - type: max-failures
+      threshold: 100
+- name: search
+  interval: 30s
+  cooldown: 5s
+  strategies:
+    - {type: velocity, threshold: 50, window: 10s}
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []ManifestEntry{{Name: "payments/stripe"}, {Name: "search", Updated: true}}, entries)
+
+	b, ok := r.Lookup("payments/stripe")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(20), b.atLeastReqs)
+	assert.Equal(t, uint32(100), b.maxFailures)
+	assert.Equal(t, []string{"checkout"}, b.Impacts())
+	search, _ := r.Lookup("search")
+	assert.NotNil(t, search.velocity)
+	assert.Equal(t, 30*time.Second, time.Duration(search.interval))
+
+	// JSON is YAML, the typos are reported by breaker and nothing is changed
+	entries, err = r.LoadManifest(strings.NewReader(`[
+		{"name": "search", "interval": "1m", "cooldown": "1m", "leastreqs": 5},
+		{"name": "orders", "interval": "1m", "cooldown": "1m", "strategies": [{"type": "ratio", "threshold": 2}]},
+		{"name": "users", "interval": "1m", "cooldown": "1m", "strategies": [{"type": "fancy"}]},
+		{"name": "carts", "interval": "1m", "cooldown": "1m"}
+	]`))
+	assert.Error(t, err)
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "search", entries[0].Name)
+	assert.Contains(t, entries[0].Err.Error(), "leastreqs")
+	assert.NoError(t, entries[1].Err)
+	assert.Contains(t, entries[2].Err.Error(), `unknown strategy "fancy"`)
+	assert.NoError(t, entries[3].Err)
+	got, _ := r.Lookup("search")
+	assert.True(t, search == got)
+
+	// the invalid options are reported once the breakers are created
+	entries, err = r.LoadManifest(strings.NewReader(`[
+		{"name": "orders", "interval": "1m", "cooldown": "1m", "strategies": [{"type": "ratio", "threshold": 2}]},
+		{"name": "carts", "interval": "1m", "cooldown": "1m"}
+	]`))
+	assert.Error(t, err)
+	assert.Contains(t, entries[0].Err.Error(), "failure threshold")
+	assert.NoError(t, entries[1].Err)
+	assert.Equal(t, []string{"payments/stripe", "search"}, r.Names())
+
+	_, err = r.LoadManifest(strings.NewReader(`{"name": "search"}`))
+	assert.Error(t, err)
+}
+
+func TestRegistry_LoadManifestVersions(t *testing.T) {
+	r := NewRegistry(time.Minute, 10*time.Second)
+
+	entries, err := r.LoadManifest(strings.NewReader(`
+version: 1
+breakers:
+  - name: search
+    interval: 1m
+    cooldown: 10s
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []ManifestEntry{{Name: "search"}}, entries)
+
+	// the bare list of the version 0 is migrated
+	entries, err = r.LoadManifest(strings.NewReader(`[{"name": "search", "interval": "1m", "cooldown": "1m"}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []ManifestEntry{{Name: "search", Updated: true}}, entries)
+
+	for _, doc := range []string{
+		`{"version": 2, "breakers": []}`,
+		`{"breakers": []}`,
+		`{"version": 1, "breakers": [], "defaults": {}}`,
+		`"search"`,
+	} {
+		_, err = r.LoadManifest(strings.NewReader(doc))
+		assert.Error(t, err, doc)
+	}
+}
@@ -0,0 +1,100 @@
+// Package outlier ejects the endpoints of a load balancer with a breaker per
+// address: the addresses whose breakers are open are skipped by Pick and
+// the outcomes of the requests are fed back into their breakers.
+//
+// It doesn't depend on a RPC framework, the balancer.Picker of gRPC is in
+// the grpcadapter module, kept apart so this module doesn't depend on grpc.
+package outlier
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// ErrNoEndpoint is returned by Pick when every address is ejected.
+var ErrNoEndpoint = errors.New("outlier: no endpoint available")
+
+// Ejector holds the breakers of the addresses.
+type Ejector struct {
+	registry *easybreaker.Registry
+	next     uint32
+
+	mu      sync.Mutex
+	reasons map[string]string // the reasons of the last ejections by address
+	status  atomic.Value      // the last generated Status
+}
+
+// New returns an ejector creating the breakers of the addresses
+// with the given settings.
+func New(interval time.Duration, cooldown time.Duration, fns ...easybreaker.OptionCall) (*Ejector, error) {
+	// validate the settings once
+	if _, err := easybreaker.New(interval, cooldown, fns...); err != nil {
+		return nil, err
+	}
+	e := &Ejector{reasons: make(map[string]string)}
+	opts := append(append([]easybreaker.OptionCall(nil), fns...), easybreaker.WithSink(easybreaker.SeverityWarn, e.onEject))
+	e.registry = easybreaker.NewRegistry(interval, cooldown, opts...)
+	return e, nil
+}
+
+// Pick returns the next address in round robin whose breaker accepts
+// the request, done must be called with the outcome of the request, e.g.
+// in a defer, the next calls are ignored. The ejected addresses are probed
+// again by Pick once their cooldown elapsed.
+func (e *Ejector) Pick(addrs []string) (string, func(error), error) {
+	if len(addrs) == 0 {
+		return "", nil, ErrNoEndpoint
+	}
+
+	start := atomic.AddUint32(&e.next, 1)
+	for i := 0; i < len(addrs); i++ {
+		addr := addrs[(int(start)+i)%len(addrs)]
+		b, err := e.registry.Get(addr)
+		if err != nil {
+			return "", nil, err
+		}
+		if b.Allow() == nil {
+			var once sync.Once
+			return addr, func(err error) { once.Do(func() { b.Done(err) }) }, nil
+		}
+	}
+	return "", nil, ErrNoEndpoint
+}
+
+// Breaker returns the breaker of the address.
+func (e *Ejector) Breaker(addr string) (*easybreaker.Breaker, error) {
+	return e.registry.Get(addr)
+}
+
+// Update drops the breakers of the addresses which are not in addrs,
+// on the resolver updates.
+func (e *Ejector) Update(addrs []string) {
+	keep := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		keep[addr] = true
+	}
+	for _, addr := range e.registry.Names() {
+		if !keep[addr] {
+			e.registry.Remove(addr)
+			e.mu.Lock()
+			delete(e.reasons, addr)
+			e.mu.Unlock()
+		}
+	}
+}
+
+// Ejected returns the addresses whose breakers are not closed.
+func (e *Ejector) Ejected() []string {
+	var ejected []string
+	e.registry.ForEach(func(addr string, b *easybreaker.Breaker) bool {
+		if b.State() != easybreaker.StateClosed {
+			ejected = append(ejected, addr)
+		}
+		return true
+	})
+	return ejected
+}
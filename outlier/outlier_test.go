@@ -0,0 +1,85 @@
+package outlier
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEjector(t *testing.T) {
+	ts := time.Unix(1520100000, 0)
+	e, err := New(
+		time.Minute, 2*time.Minute,
+		easybreaker.WithLeastReqs(1),
+		easybreaker.WithNow(func() time.Time { return ts }),
+	)
+	assert.NoError(t, err)
+
+	addrs := []string{"10.0.0.1:443", "10.0.0.2:443", "10.0.0.3:443"}
+	picked := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		addr, done, err := e.Pick(addrs)
+		assert.NoError(t, err)
+		picked[addr]++
+		if addr == "10.0.0.2:443" {
+			done(errors.New("unavailable"))
+		} else {
+			done(nil)
+		}
+	}
+	assert.Equal(t, 3, len(picked))
+	assert.Equal(t, []string{"10.0.0.2:443"}, e.Ejected())
+
+	// the ejected address is skipped
+	for i := 0; i < 6; i++ {
+		addr, done, err := e.Pick(addrs)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "10.0.0.2:443", addr)
+		done(nil)
+	}
+
+	// and probed again after the cooldown
+	ts = ts.Add(3 * time.Minute)
+	readmitted := false
+	for i := 0; i < 6; i++ {
+		addr, done, _ := e.Pick(addrs)
+		readmitted = readmitted || addr == "10.0.0.2:443"
+		done(nil)
+	}
+	assert.True(t, readmitted)
+	assert.Empty(t, e.Ejected())
+
+	// done is called once, e.g. by a deferred release
+	addr, done, err := e.Pick(addrs)
+	assert.NoError(t, err)
+	done(nil)
+	done(nil)
+	b, _ := e.Breaker(addr)
+	assert.Equal(t, uint32(0), b.Counts().InFlight)
+
+	e.Update(addrs[:1])
+	b, _ = e.Breaker("10.0.0.1:443")
+	assert.Equal(t, easybreaker.StateClosed, b.State())
+	addr, _, err = e.Pick(addrs[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:443", addr)
+}
+
+func TestEjector_NoEndpoint(t *testing.T) {
+	e, err := New(time.Minute, time.Minute)
+	assert.NoError(t, err)
+
+	_, _, err = e.Pick(nil)
+	assert.Equal(t, ErrNoEndpoint, err)
+
+	b, _ := e.Breaker("10.0.0.1:443")
+	b.ForceOpen()
+	_, _, err = e.Pick([]string{"10.0.0.1:443"})
+	assert.Equal(t, ErrNoEndpoint, err)
+
+	_, err = New(0, time.Minute)
+	assert.Error(t, err)
+}
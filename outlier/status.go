@@ -0,0 +1,99 @@
+package outlier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Status is the document of the ejected endpoints scraped by the control planes.
+type Status struct {
+	Generated time.Time  `json:"generated"`
+	Endpoints int        `json:"endpoints"`
+	Ejected   []Ejection `json:"ejected"`
+}
+
+// Ejection is an ejected endpoint.
+type Ejection struct {
+	Address string            `json:"address"`
+	State   easybreaker.State `json:"state"`
+	Since   time.Time         `json:"since"`
+	Reason  string            `json:"reason"`
+
+	// the time the endpoint is probed again,
+	// zero while it's probed or forced open until released
+	ReadmitAt time.Time `json:"readmit_at,omitempty"`
+}
+
+func (e *Ejector) onEject(ev easybreaker.Event) {
+	if ev.Type != easybreaker.EventStateChange || ev.To != easybreaker.StateOpen {
+		return
+	}
+
+	reason := fmt.Sprintf("%d failures of %d requests", ev.Counts.Failures, ev.Counts.Total)
+	if ev.From == easybreaker.StateHalfOpen {
+		reason = "probes failed, " + reason
+	}
+	e.mu.Lock()
+	e.reasons[ev.Name] = reason
+	e.mu.Unlock()
+}
+
+// Status generates the status document.
+func (e *Ejector) Status() Status {
+	status := Status{Generated: time.Now(), Ejected: []Ejection{}}
+	e.registry.ForEach(func(addr string, b *easybreaker.Breaker) bool {
+		status.Endpoints++
+
+		state := b.State()
+		if state == easybreaker.StateClosed {
+			return true
+		}
+		ejection := Ejection{Address: addr, State: state}
+		ejection.Since, _ = b.OpenSince()
+		if d := b.Debug(); state == easybreaker.StateOpen && !d.ForcedOpen {
+			ejection.ReadmitAt = d.Until
+		}
+		e.mu.Lock()
+		ejection.Reason = e.reasons[addr]
+		e.mu.Unlock()
+		if ejection.Reason == "" {
+			ejection.Reason = "forced open"
+		}
+
+		status.Ejected = append(status.Ejected, ejection)
+		return true
+	})
+	return status
+}
+
+// Publish generates the status document every period until ctx is done,
+// the last one is served by ServeHTTP.
+func (e *Ejector) Publish(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		e.status.Store(e.Status())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ServeHTTP serves the last status document generated by Publish as JSON,
+// or a fresh one if Publish is not running.
+func (e *Ejector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status, ok := e.status.Load().(Status)
+	if !ok {
+		status = e.Status()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,54 @@
+package outlier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEjector_Status(t *testing.T) {
+	ts := time.Unix(1520100000, 0)
+	e, err := New(
+		time.Minute, 2*time.Minute,
+		easybreaker.WithNow(func() time.Time { return ts }),
+	)
+	assert.NoError(t, err)
+
+	bad, _ := e.Breaker("10.0.0.2:443")
+	bad.Execute(func() error { return nil })
+	bad.Execute(func() error { return errors.New("unavailable") })
+	forced, _ := e.Breaker("10.0.0.3:443")
+	forced.ForceOpen()
+	e.Breaker("10.0.0.1:443")
+
+	status := e.Status()
+	assert.Equal(t, 3, status.Endpoints)
+	assert.Equal(t, []Ejection{
+		{
+			Address: "10.0.0.2:443", State: easybreaker.StateOpen, Since: ts,
+			Reason: "1 failures of 2 requests", ReadmitAt: ts.Add(2 * time.Minute),
+		},
+		{
+			Address: "10.0.0.3:443", State: easybreaker.StateOpen, Since: ts,
+			Reason: "forced open",
+		},
+	}, status.Ejected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e.Publish(ctx, time.Second)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, float64(3), doc["endpoints"])
+	assert.Len(t, doc["ejected"], 2)
+	assert.Equal(t, "open", doc["ejected"].([]interface{})[0].(map[string]interface{})["state"])
+}
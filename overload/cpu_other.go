@@ -0,0 +1,12 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+
+package overload
+
+import (
+	"time"
+)
+
+// cpuTime is not supported, the CPU limit is ignored.
+func cpuTime() (time.Duration, bool) {
+	return 0, false
+}
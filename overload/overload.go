@@ -0,0 +1,140 @@
+// Package overload sheds the load when the process itself is the bottleneck,
+// the sampler feeds the CPU usage, the number of goroutines and the heap size
+// of the process into a breaker opening while any of them exceeds its limit:
+//
+//	b, sampler, err := overload.New(overload.Limits{CPU: 0.9, Goroutines: 10000}, time.Second)
+//	go sampler.Run(ctx, time.Second)
+//	http.Handle("/", httpbreaker.Middleware(b, handler))
+package overload
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+const (
+	heapMetric = "/memory/classes/heap/objects:bytes"
+
+	// the interval of the breaker, the counts are not used
+	interval = time.Minute
+)
+
+// Limits are the resources of the process the breaker opens at,
+// the zero limits are not checked.
+type Limits struct {
+	CPU        float64 // the CPU usage, 1 means all the CPUs are busy
+	Goroutines int
+	HeapBytes  uint64
+}
+
+// Sample is the resource usage of the process.
+type Sample struct {
+	CPU        float64
+	Goroutines int
+	HeapBytes  uint64
+}
+
+// Load returns the usage of the most used resource in percent of its limit.
+func (l Limits) Load(s Sample) int {
+	var load float64
+	if l.CPU > 0 && s.CPU/l.CPU > load {
+		load = s.CPU / l.CPU
+	}
+	if l.Goroutines > 0 && float64(s.Goroutines)/float64(l.Goroutines) > load {
+		load = float64(s.Goroutines) / float64(l.Goroutines)
+	}
+	if l.HeapBytes > 0 && float64(s.HeapBytes)/float64(l.HeapBytes) > load {
+		load = float64(s.HeapBytes) / float64(l.HeapBytes)
+	}
+	return int(load * 100)
+}
+
+// Sampler samples the resource usage and feeds it to the breaker.
+type Sampler struct {
+	breaker *easybreaker.Breaker
+	limits  Limits
+
+	mu       sync.Mutex
+	lastCPU  time.Duration
+	lastWall time.Time
+	samples  []metrics.Sample
+}
+
+// New returns a breaker opening while the process exceeds any of the limits
+// and the sampler feeding it, the breaker closes once the process is back
+// under the limits after the cooldown period. The options are applied
+// to the breaker.
+func New(limits Limits, cooldown time.Duration, fns ...easybreaker.OptionCall) (*easybreaker.Breaker, *Sampler, error) {
+	if limits.CPU <= 0 && limits.Goroutines <= 0 && limits.HeapBytes == 0 {
+		return nil, nil, errors.New("overload: a limit must be set")
+	}
+
+	opts := []easybreaker.OptionCall{
+		easybreaker.WithStateFunc(
+			func(uint32, uint32) bool { return false },
+			func(uint32, uint32) bool { return true },
+		),
+		easybreaker.WithLoadFunc(func(load int) bool { return load >= 100 }),
+	}
+	b, err := easybreaker.New(interval, cooldown, append(opts, fns...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := &Sampler{
+		breaker: b,
+		limits:  limits,
+		samples: []metrics.Sample{{Name: heapMetric}},
+	}
+	s.lastCPU, _ = cpuTime()
+	s.lastWall = time.Now()
+	return b, s, nil
+}
+
+// Sample takes a sample of the resource usage and feeds it to the breaker.
+// The CPU usage is measured since the previous sample.
+func (s *Sampler) Sample() Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sample := Sample{Goroutines: runtime.NumGoroutine()}
+
+	metrics.Read(s.samples)
+	if s.samples[0].Value.Kind() == metrics.KindUint64 {
+		sample.HeapBytes = s.samples[0].Value.Uint64()
+	}
+
+	now := time.Now()
+	if cpu, ok := cpuTime(); ok {
+		wall := now.Sub(s.lastWall)
+		if wall > 0 {
+			sample.CPU = float64(cpu-s.lastCPU) / float64(wall) / float64(runtime.NumCPU())
+		}
+		s.lastCPU = cpu
+	}
+	s.lastWall = now
+
+	s.breaker.ObserveLoad(s.limits.Load(sample))
+	return sample
+}
+
+// Run samples the resource usage every period until ctx is done.
+func (s *Sampler) Run(ctx context.Context, every time.Duration) {
+	clock := s.breaker.Clock()
+	for {
+		timer := clock.NewTimer(every)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+			s.Sample()
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package overload
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits_Load(t *testing.T) {
+	l := Limits{CPU: 0.8, Goroutines: 1000, HeapBytes: 1 << 30}
+	assert.Equal(t, 50, l.Load(Sample{CPU: 0.4, Goroutines: 100}))
+	assert.Equal(t, 150, l.Load(Sample{CPU: 0.4, Goroutines: 1500}))
+	assert.Equal(t, 200, l.Load(Sample{HeapBytes: 2 << 30}))
+
+	assert.Equal(t, 0, Limits{Goroutines: 1000}.Load(Sample{CPU: 1}))
+}
+
+func TestSampler_Sample(t *testing.T) {
+	_, _, err := New(Limits{}, time.Second)
+	assert.Error(t, err)
+
+	b, s, err := New(Limits{Goroutines: runtime.NumGoroutine() + 10}, time.Second)
+	assert.NoError(t, err)
+
+	sample := s.Sample()
+	assert.True(t, sample.Goroutines > 0)
+	assert.True(t, sample.HeapBytes > 0)
+	assert.Equal(t, easybreaker.StateClosed, b.State())
+
+	stop := make(chan struct{})
+	for i := 0; i < 20; i++ {
+		go func() { <-stop }()
+	}
+	s.Sample()
+	close(stop)
+	assert.Equal(t, easybreaker.StateOpen, b.State())
+}
@@ -0,0 +1,102 @@
+package easybreaker
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Pool holds the breakers of the short-lived keys, e.g. the peers or the
+// sessions of a proxy, bounded to a number of breakers. The least recently
+// used breaker is evicted once the pool is full, it's recreated closed
+// on its next use.
+type Pool struct {
+	interval time.Duration
+	cooldown time.Duration
+	fns      []OptionCall
+	max      int
+
+	mu      sync.Mutex
+	lru     *list.List // of *poolEntry, the most recently used first
+	entries map[string]*list.Element
+	evicted uint64
+}
+
+type poolEntry struct {
+	key     string
+	breaker *Breaker
+}
+
+// NewPool returns a pool of max breakers at most created with the given settings,
+// WithName is added to the options of every breaker with its key.
+func NewPool(max int, interval time.Duration, cooldown time.Duration, fns ...OptionCall) (*Pool, error) {
+	if max <= 0 {
+		return nil, errors.New("circuit: max must be positive")
+	}
+	// validate the settings once
+	if _, err := New(interval, cooldown, fns...); err != nil {
+		return nil, err
+	}
+
+	return &Pool{
+		interval: interval,
+		cooldown: cooldown,
+		fns:      fns,
+		max:      max,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}, nil
+}
+
+// Get returns the breaker of the key, it's created if it doesn't exist.
+func (p *Pool) Get(key string) (*Breaker, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.entries[key]; ok {
+		p.lru.MoveToFront(e)
+		return e.Value.(*poolEntry).breaker, nil
+	}
+
+	opts := make([]OptionCall, 0, len(p.fns)+1)
+	opts = append(opts, WithName(key))
+	b, err := New(p.interval, p.cooldown, append(opts, p.fns...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.lru.Len() >= p.max {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.entries, oldest.Value.(*poolEntry).key)
+		p.evicted++
+	}
+	p.entries[key] = p.lru.PushFront(&poolEntry{key: key, breaker: b})
+	return b, nil
+}
+
+// Remove deletes the breaker of the key, e.g. once the connection is closed.
+func (p *Pool) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.entries[key]; ok {
+		p.lru.Remove(e)
+		delete(p.entries, key)
+	}
+}
+
+// Len returns the number of the breakers of the pool.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lru.Len()
+}
+
+// Evicted returns the number of the breakers evicted by the bound.
+func (p *Pool) Evicted() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.evicted
+}
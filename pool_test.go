@@ -0,0 +1,60 @@
+package easybreaker
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool(t *testing.T) {
+	p, err := NewPool(2, time.Minute, 2*time.Minute, WithLeastReqs(5))
+	assert.NoError(t, err)
+
+	a, _ := p.Get("10.0.0.1:80")
+	b, _ := p.Get("10.0.0.2:80")
+	assert.Equal(t, "10.0.0.1:80", a.name)
+	assert.Equal(t, uint32(5), a.atLeastReqs)
+
+	// a is used, b is the least recently used one
+	got, _ := p.Get("10.0.0.1:80")
+	assert.True(t, got == a)
+	p.Get("10.0.0.3:80")
+	assert.Equal(t, 2, p.Len())
+	assert.Equal(t, uint64(1), p.Evicted())
+
+	got, _ = p.Get("10.0.0.2:80")
+	assert.False(t, got == b)
+
+	p.Remove("10.0.0.2:80")
+	assert.Equal(t, 1, p.Len())
+
+	_, err = NewPool(0, time.Minute, time.Minute)
+	assert.Error(t, err)
+	_, err = NewPool(10, 0, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestPool_Concurrent(t *testing.T) {
+	p, err := NewPool(100, time.Minute, time.Minute)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				b, err := p.Get(fmt.Sprint(i*1000 + j))
+				assert.NoError(t, err)
+				b.Execute(func() error { return nil })
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, p.Len())
+	assert.Equal(t, uint64(7900), p.Evicted())
+}
@@ -0,0 +1,56 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// pressure warns once per window about the failure ratio approaching the trip
+type pressure struct {
+	ratio   float64
+	minReqs uint32
+
+	warned   uint64 // the epoch of the last warned window, plus one
+	warnings uint64
+}
+
+// WarningThreshold is a failure ratio of the interval, lower than the one
+// opening the breaker, emitting an EventWarning while the traffic is still
+// admitted, so the dashboards show the pressure before the trip and the
+// teams can step in. It's emitted once per window, based on minReqs
+// requests at least.
+func WithWarningThreshold(ratio float64, minReqs uint32) OptionCall {
+	return func(b *Breaker) error {
+		if !(ratio > 0 && ratio <= 1) {
+			return errors.New("circuit: warning threshold must be in (0, 1]")
+		}
+		b.pressure = &pressure{ratio: ratio, minReqs: minReqs}
+		return nil
+	}
+}
+
+// warn emits an EventWarning if the failures of the closed window reached
+// the warning threshold for the first time.
+func (b *Breaker) warn(total uint32, failures uint32) {
+	p := b.pressure
+	if p == nil || total == 0 || total < p.minReqs || float64(failures)/float64(total) < p.ratio {
+		return
+	}
+
+	epoch := atomic.LoadUint64(&b.epoch) + 1
+	warned := atomic.LoadUint64(&p.warned)
+	if warned == epoch || !atomic.CompareAndSwapUint64(&p.warned, warned, epoch) {
+		return
+	}
+	atomic.AddUint64(&p.warnings, 1)
+	b.emit(Event{Type: EventWarning, Severity: SeverityWarn, From: StateClosed, To: StateClosed, Counts: b.current()})
+}
+
+// Warnings returns the number of windows which reached the warning threshold
+// of WithWarningThreshold.
+func (b *Breaker) Warnings() uint64 {
+	if b.pressure == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&b.pressure.warnings)
+}
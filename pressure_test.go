@@ -0,0 +1,60 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_WarningThreshold(t *testing.T) {
+	var warnings []Event
+	b, err := New(
+		time.Minute, time.Minute,
+		WithName("api"),
+		WithFailureThreshold(0.5),
+		WithWarningThreshold(0.2, 4),
+		WithSink(SeverityWarn, func(e Event) { warnings = append(warnings, e) }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	failed := func() error { return errors.New("failed") }
+	ok := func() error { return nil }
+
+	// not enough requests
+	b.Execute(ok)
+	b.Execute(ok)
+	b.Execute(failed)
+	assert.Empty(t, warnings)
+
+	// 2 of 6, warned once per window
+	b.Execute(ok)
+	b.Execute(ok)
+	b.Execute(failed)
+	b.Execute(failed)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, EventWarning, warnings[0].Type)
+	assert.Equal(t, SeverityWarn, warnings[0].Severity)
+	assert.Equal(t, Counts{Total: 6, Failures: 2, Successes: 4}, warnings[0].Counts)
+	assert.Equal(t, `[warn] breaker "api" approaching the trip, total=6 failures=2`, warnings[0].String())
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, uint64(1), b.Warnings())
+
+	// the next window warns again
+	b.now = now(1520100061)
+	for i := 0; i < 4; i++ {
+		b.Execute(ok)
+	}
+	b.Execute(failed)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, uint64(2), b.Warnings())
+
+	_, err = New(time.Minute, time.Minute, WithWarningThreshold(0, 0))
+	assert.Error(t, err)
+
+	b, err = New(time.Minute, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), b.Warnings())
+}
@@ -0,0 +1,103 @@
+package easybreaker
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+)
+
+// ProbeSelector is consulted on every request in the half-open state to
+// decide whether it's used as a probe, e.g. only the idempotent requests.
+// The other requests are rejected, or with passNonProbes admitted without
+// being counted, so they don't weigh on the decision to close. Only Execute,
+// ExecuteN and ExecuteCtx pass them through, Allow can't tell them apart
+// in Done and rejects them.
+func WithProbeSelector(isProbe func() bool, passNonProbes bool) OptionCall {
+	return func(b *Breaker) error {
+		if isProbe == nil {
+			return errors.New("circuit: probe selector must be defined")
+		}
+		b.isProbe = isProbe
+		b.passNonProbes = passNonProbes
+		return nil
+	}
+}
+
+// probeRate admits a percentage of the requests of the half-open state
+type probeRate struct {
+	percent float64
+	seq     uint64
+}
+
+// HalfOpenPercent admits percent of the requests of the half-open state as
+// probes, in (0, 100], spread evenly from the first one, and rejects the
+// others, so the load of the probes scales with the traffic instead of
+// arriving as a burst of the first atLeastReqs requests.
+func WithHalfOpenPercent(percent float64) OptionCall {
+	return func(b *Breaker) error {
+		if !(percent > 0 && percent <= 100) {
+			return errors.New("circuit: half-open percent must be in (0, 100]")
+		}
+		b.probeRate = &probeRate{percent: percent}
+		return nil
+	}
+}
+
+// reset spreads the requests of a new half-open state from the first one.
+func (r *probeRate) reset() {
+	if r != nil {
+		atomic.StoreUint64(&r.seq, 0)
+	}
+}
+
+// admit reports whether the request is in the percentage.
+func (r *probeRate) admit() bool {
+	if r == nil {
+		return true
+	}
+	n := float64(atomic.AddUint64(&r.seq, 1))
+	return math.Ceil(n*r.percent/100) > math.Ceil((n-1)*r.percent/100)
+}
+
+// allow admits a request as Allow, with pass the non-probes of the
+// half-open state are admitted without being counted, counted is false.
+func (b *Breaker) allow(pass bool) (counted bool, err error) {
+	return b.allowClass(pass, "")
+}
+
+// allowClass is allow for a request of an operation class, rejected while
+// its budget is tripped and admitted while open with WithPartialOpen.
+func (b *Breaker) allowClass(pass bool, class string) (counted bool, err error) {
+	b.chaosAdmit()
+	c := b.budgets[class]
+	if c != nil && !c.ready(b.now().UnixNano()) {
+		return false, b.admit(false)
+	}
+	if (b.isProbe != nil || b.probeRate != nil) && atomic.LoadInt32(&b.state) == halfOpen &&
+		b.override() == forcedNone {
+		if b.isProbe != nil && !b.isProbe() {
+			if pass && b.passNonProbes {
+				atomic.AddUint32(&b.inFlight, 1)
+				return false, nil
+			}
+			return false, b.admit(false)
+		}
+		if !b.probeRate.admit() {
+			return false, b.admit(false)
+		}
+	}
+	if err := b.admit(b.ready() || b.passThrough(class)); err != nil {
+		return false, err
+	}
+	if c != nil {
+		atomic.AddUint64(&c.counts, totalUnit)
+	}
+	return true, nil
+}
+
+// release ends a request in flight.
+func (b *Breaker) release() {
+	if atomic.AddUint32(&b.inFlight, ^uint32(0)) == 0 && atomic.LoadInt32(&b.drainWaiting) > 0 {
+		b.notifyDrained()
+	}
+}
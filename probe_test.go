@@ -0,0 +1,104 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_ProbeSelector(t *testing.T) {
+	probe := false
+	for _, pass := range []bool{false, true} {
+		b, err := New(
+			time.Minute, time.Minute,
+			WithLeastReqs(2),
+			WithProbeSelector(func() bool { return probe }, pass),
+			withTime(1520100000),
+		)
+		assert.NoError(t, err)
+
+		probe = true
+		b.Execute(func() error { return errors.New("failed") })
+		b.now = now(1520100060)
+		assert.NoError(t, b.Execute(func() error { return nil }))
+		assert.Equal(t, StateHalfOpen, b.State())
+
+		probe = false
+		err = b.Execute(func() error { return errors.New("failed") })
+		assert.Equal(t, ErrBreakerOpen, b.Allow())
+		if pass {
+			// passed through, not counted
+			assert.EqualError(t, err, "failed")
+		} else {
+			assert.Equal(t, ErrBreakerOpen, err)
+		}
+		assert.Equal(t, Counts{Total: 1, Successes: 1}, b.Counts())
+
+		probe = true
+		assert.NoError(t, b.Execute(func() error { return nil }))
+		assert.NoError(t, b.Execute(func() error { return nil }))
+		assert.Equal(t, StateClosed, b.State())
+	}
+
+	_, err := New(time.Minute, time.Minute, WithProbeSelector(nil, false))
+	assert.Error(t, err)
+}
+
+func TestBreaker_HalfOpenPercent(t *testing.T) {
+	b, err := New(
+		time.Minute, time.Minute,
+		WithLeastReqs(3),
+		WithHalfOpenPercent(25),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	b.now = now(1520100060)
+
+	// the request moving the breaker to the half-open state,
+	// then 1 of every 4
+	var admitted []int
+	for i := 0; i < 9; i++ {
+		if b.Execute(func() error { return nil }) == nil {
+			admitted = append(admitted, i)
+		}
+	}
+	assert.Equal(t, []int{0, 1, 5}, admitted)
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.Equal(t, Counts{Total: 3, Successes: 3}, b.Counts())
+
+	// the closed state admits all the requests
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateClosed, b.State())
+	assert.NoError(t, b.Execute(func() error { return nil }))
+
+	// every half-open state spreads its requests from the first one
+	b.Execute(func() error { return errors.New("failed") })
+	for period := 0; period < 2; period++ {
+		b.now = now(1520100060 + int64(period+2)*60)
+		admitted = admitted[:0]
+		for i := 0; i < 9; i++ {
+			err := b.Execute(func() error {
+				if i == 5 {
+					return errors.New("failed")
+				}
+				return nil
+			})
+			if !errors.Is(err, ErrBreakerOpen) {
+				admitted = append(admitted, i)
+			}
+		}
+		assert.Equal(t, []int{0, 1, 5}, admitted, period)
+		// the failed probe opens the breaker again
+		assert.Equal(t, ErrBreakerOpen, b.Execute(func() error { return nil }))
+		assert.Equal(t, StateOpen, b.State())
+	}
+
+	_, err = New(time.Minute, time.Minute, WithHalfOpenPercent(0))
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithHalfOpenPercent(101))
+	assert.Error(t, err)
+}
@@ -0,0 +1,329 @@
+package easybreaker
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Registry holds the circuit breakers by name, the breakers which are not
+// configured explicitly are created on the first use with the default settings.
+//
+// The names are hierarchical, the namespaces are separated by slashes,
+// e.g. "payments/stripe/charges". The settings are layered, the defaults
+// of the registry, then of every namespace from the outermost, then of the
+// name set with Configure, and a breaker is created with the merged layers.
+type Registry struct {
+	trips uint64 // the trips of the removed and replaced breakers, first to be aligned for atomic
+
+	mu         sync.RWMutex // guards the layers, taken before the locks of the shards
+	global     layer
+	namespaces map[string]layer
+	names      map[string]layer // the layers set with Configure
+
+	// the breakers are striped by the hash of their names,
+	// so Get scales with the cores
+	shards [registryShards]registryShard
+}
+
+const registryShards = 64
+
+type registryShard struct {
+	mu       sync.RWMutex
+	breakers map[string]*Breaker
+	_        [32]byte // pads the shard to a cache line
+}
+
+// shard returns the shard of the name with the FNV-1a hash.
+func (r *Registry) shard(name string) *registryShard {
+	h := uint32(2166136261)
+	for i := 0; i < len(name); i++ {
+		h ^= uint32(name[i])
+		h *= 16777619
+	}
+	return &r.shards[h%registryShards]
+}
+
+// layer is a level of settings, the zero durations are inherited
+// and the options are appended to the ones of the outer layers.
+type layer struct {
+	interval time.Duration
+	cooldown time.Duration
+	fns      []OptionCall
+}
+
+func (l layer) merge(inner layer) layer {
+	if inner.interval != 0 {
+		l.interval = inner.interval
+	}
+	if inner.cooldown != 0 {
+		l.cooldown = inner.cooldown
+	}
+	fns := make([]OptionCall, 0, len(l.fns)+len(inner.fns))
+	fns = append(fns, l.fns...)
+	l.fns = append(fns, inner.fns...)
+	return l
+}
+
+// NewRegistry returns a registry creating the breakers with the given defaults,
+// WithName is added to the options of every breaker.
+func NewRegistry(interval time.Duration, cooldown time.Duration, fns ...OptionCall) *Registry {
+	r := &Registry{
+		global:     layer{interval: interval, cooldown: cooldown, fns: fns},
+		namespaces: make(map[string]layer),
+		names:      make(map[string]layer),
+	}
+	for i := range r.shards {
+		r.shards[i].breakers = make(map[string]*Breaker)
+	}
+	return r
+}
+
+// SetDefaults replaces the defaults of the registry,
+// the existing breakers are changed by Propagate.
+func (r *Registry) SetDefaults(interval time.Duration, cooldown time.Duration, fns ...OptionCall) {
+	r.mu.Lock()
+	r.global = layer{interval: interval, cooldown: cooldown, fns: fns}
+	r.mu.Unlock()
+}
+
+// Namespace sets the defaults of the breakers under the namespace, e.g.
+// "payments" for "payments/stripe". The zero durations are inherited from
+// the outer layers and the options are appended to theirs.
+// The existing breakers are changed by Propagate.
+func (r *Registry) Namespace(namespace string, interval time.Duration, cooldown time.Duration, fns ...OptionCall) error {
+	if namespace == "" || strings.HasPrefix(namespace, "/") || strings.HasSuffix(namespace, "/") {
+		return errors.New("circuit: invalid namespace")
+	}
+
+	r.mu.Lock()
+	r.namespaces[namespace] = layer{interval: interval, cooldown: cooldown, fns: fns}
+	r.mu.Unlock()
+	return nil
+}
+
+// settings merges the layers of the name, r.mu must be held.
+func (r *Registry) settings(name string) layer {
+	l := r.global
+	for i := strings.IndexByte(name, '/'); i > 0; {
+		if ns, ok := r.namespaces[name[:i]]; ok {
+			l = l.merge(ns)
+		}
+		j := strings.IndexByte(name[i+1:], '/')
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+
+	if own, ok := r.names[name]; ok {
+		// the durations of Configure are not inherited
+		l = l.merge(own)
+		l.interval, l.cooldown = own.interval, own.cooldown
+	}
+	return l
+}
+
+// create returns the breaker of the name, fns are applied before the layers.
+func (r *Registry) create(name string, fns ...OptionCall) (*Breaker, error) {
+	l := r.settings(name)
+	return New(l.interval, l.cooldown, append(fns, r.options(name, l.fns)...)...)
+}
+
+// withState shares the state, the counts and the timers of old,
+// unless the layers map them in the shared memory.
+func withState(old *Breaker) OptionCall {
+	return func(b *Breaker) error {
+		b.shared = old.shared
+		return nil
+	}
+}
+
+// Propagate recreates the existing breakers under the namespace with their
+// merged layers, all of them if namespace is empty, after a change of
+// a layer. The recreated breakers share the state, the counts and the
+// timers of the replaced ones, an open breaker stays open until the end
+// of its cooldown, and the requests still going through the replaced
+// breakers are counted by the recreated ones. The subscriptions to the
+// events are not carried over. Nothing is changed if a breaker can't be
+// created.
+func (r *Registry) Propagate(namespace string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inNamespace := InNamespace(namespace)
+	created := make(map[string]*Breaker)
+	for _, e := range r.snapshot() {
+		if namespace != "" && e.name != namespace && !inNamespace(e.name, nil) {
+			continue
+		}
+		b, err := r.create(e.name, withState(e.breaker))
+		if err != nil {
+			return err
+		}
+		created[e.name] = b
+	}
+
+	for name, b := range created {
+		r.store(name, b)
+	}
+	return nil
+}
+
+// InNamespace is a filter of List keeping the breakers under the namespace.
+func InNamespace(namespace string) func(name string, b *Breaker) bool {
+	prefix := strings.TrimSuffix(namespace, "/") + "/"
+	return func(name string, b *Breaker) bool {
+		return strings.HasPrefix(name, prefix)
+	}
+}
+
+// Get returns the breaker with the name, it's created with the merged
+// layers of its settings if it doesn't exist.
+func (r *Registry) Get(name string) (*Breaker, error) {
+	shard := r.shard(name)
+	shard.mu.RLock()
+	b, ok := shard.breakers[name]
+	shard.mu.RUnlock()
+	if ok {
+		return b, nil
+	}
+
+	// created out of the lock of the shard, the breaker of a concurrent
+	// Get or Configure wins
+	r.mu.RLock()
+	b, err := r.create(name)
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if existing, ok := shard.breakers[name]; ok {
+		return existing, nil
+	}
+	shard.breakers[name] = b
+	return b, nil
+}
+
+// Lookup returns the breaker with the name, false if it doesn't exist.
+func (r *Registry) Lookup(name string) (*Breaker, bool) {
+	shard := r.shard(name)
+	shard.mu.RLock()
+	b, ok := shard.breakers[name]
+	shard.mu.RUnlock()
+	return b, ok
+}
+
+// Configure sets the settings of the breaker with the name and creates it,
+// replacing the existing one. The options are appended to the ones of the
+// registry and of the namespaces.
+func (r *Registry) Configure(name string, interval time.Duration, cooldown time.Duration, fns ...OptionCall) (*Breaker, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	own, hadOwn := r.names[name]
+	r.names[name] = layer{interval: interval, cooldown: cooldown, fns: fns}
+	b, err := r.create(name)
+	if err != nil {
+		if hadOwn {
+			r.names[name] = own
+		} else {
+			delete(r.names, name)
+		}
+		return nil, err
+	}
+
+	r.store(name, b)
+	return b, nil
+}
+
+// Remove deletes the breaker with the name and its settings.
+func (r *Registry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.names, name)
+	r.store(name, nil)
+}
+
+// store replaces the breaker with the name, deleting it if b is nil,
+// and keeps the trips of the replaced one unless b shares its state.
+func (r *Registry) store(name string, b *Breaker) {
+	shard := r.shard(name)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if old, ok := shard.breakers[name]; ok && (b == nil || b.shared != old.shared) {
+		atomic.AddUint64(&r.trips, old.Trips())
+	}
+	if b == nil {
+		delete(shard.breakers, name)
+		return
+	}
+	shard.breakers[name] = b
+}
+
+// Names returns the sorted names of the breakers.
+func (r *Registry) Names() []string {
+	entries := r.snapshot()
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.name
+	}
+	return names
+}
+
+// ForEach calls fn with the breakers sorted by name until it returns false.
+// The breakers are snapshotted first, the ones created or removed
+// meanwhile are not seen and fn can use the registry.
+func (r *Registry) ForEach(fn func(name string, b *Breaker) bool) {
+	for _, e := range r.snapshot() {
+		if !fn(e.name, e.breaker) {
+			return
+		}
+	}
+}
+
+// List returns the breakers for which filter returns true sorted by name,
+// all of them if filter is nil.
+func (r *Registry) List(filter func(name string, b *Breaker) bool) []*Breaker {
+	var breakers []*Breaker
+	for _, e := range r.snapshot() {
+		if filter == nil || filter(e.name, e.breaker) {
+			breakers = append(breakers, e.breaker)
+		}
+	}
+	return breakers
+}
+
+type entry struct {
+	name    string
+	breaker *Breaker
+}
+
+func (r *Registry) snapshot() []entry {
+	var entries []entry
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mu.RLock()
+		for name, b := range shard.breakers {
+			entries = append(entries, entry{name: name, breaker: b})
+		}
+		shard.mu.RUnlock()
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries
+}
+
+func (r *Registry) options(name string, fns []OptionCall) []OptionCall {
+	opts := make([]OptionCall, 0, len(fns)+1)
+	opts = append(opts, WithName(name))
+	return append(opts, fns...)
+}
@@ -0,0 +1,165 @@
+package easybreaker
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Get(t *testing.T) {
+	r := NewRegistry(time.Minute, 2*time.Minute, WithLeastReqs(10))
+
+	var wg sync.WaitGroup
+	breakers := make([]*Breaker, 10)
+	wg.Add(len(breakers))
+	for i := range breakers {
+		go func(i int) {
+			defer wg.Done()
+			b, err := r.Get("api")
+			assert.NoError(t, err)
+			breakers[i] = b
+		}(i)
+	}
+	wg.Wait()
+
+	for _, b := range breakers {
+		assert.True(t, breakers[0] == b)
+	}
+	assert.Equal(t, "api", breakers[0].name)
+	assert.Equal(t, uint32(10), breakers[0].atLeastReqs)
+
+	_, err := NewRegistry(0, time.Minute).Get("api")
+	assert.Error(t, err)
+}
+
+func TestRegistry_Configure(t *testing.T) {
+	r := NewRegistry(time.Minute, 2*time.Minute)
+
+	b, err := r.Configure("db", time.Second, time.Second, WithLeastReqs(5))
+	assert.NoError(t, err)
+	assert.Equal(t, "db", b.name)
+
+	got, err := r.Get("db")
+	assert.NoError(t, err)
+	assert.True(t, b == got)
+	assert.Equal(t, uint32(5), got.atLeastReqs)
+
+	_, err = r.Configure("db", 0, time.Second)
+	assert.Error(t, err)
+
+	r.Get("api")
+	assert.Equal(t, []string{"api", "db"}, r.Names())
+
+	r.Remove("db")
+	assert.Equal(t, []string{"api"}, r.Names())
+}
+
+func TestRegistry_ForEach(t *testing.T) {
+	r := NewRegistry(time.Minute, 2*time.Minute)
+	for _, name := range []string{"db", "api", "cache"} {
+		r.Get(name)
+	}
+
+	var names []string
+	r.ForEach(func(name string, b *Breaker) bool {
+		names = append(names, name)
+		// the registry can be used meanwhile
+		r.Remove("cache")
+		r.Get("search")
+		return name != "cache"
+	})
+	assert.Equal(t, []string{"api", "cache"}, names)
+
+	db, _ := r.Get("db")
+	db.ForceOpen()
+	open := r.List(func(name string, b *Breaker) bool { return b.State() == StateOpen })
+	assert.Equal(t, []*Breaker{db}, open)
+	assert.Len(t, r.List(nil), 3)
+}
+
+func TestRegistry_Namespace(t *testing.T) {
+	r := NewRegistry(time.Minute, 2*time.Minute, WithLeastReqs(10))
+	assert.NoError(t, r.Namespace("payments", time.Second, time.Second, WithLeastReqs(20)))
+	assert.NoError(t, r.Namespace("payments/stripe", time.Second, time.Second, WithLeastReqs(30)))
+
+	charges, _ := r.Get("payments/stripe/charges")
+	paypal, _ := r.Get("payments/paypal")
+	search, _ := r.Get("search")
+	r.Get("paymentsv2/refunds")
+	assert.Equal(t, uint32(30), charges.atLeastReqs)
+	assert.Equal(t, uint32(20), paypal.atLeastReqs)
+	assert.Equal(t, int64(time.Second), paypal.interval)
+	assert.Equal(t, uint32(10), search.atLeastReqs)
+
+	assert.Equal(t, []*Breaker{paypal, charges}, r.List(InNamespace("payments")))
+	assert.Equal(t, []*Breaker{charges}, r.List(InNamespace("payments/stripe/")))
+
+	assert.Error(t, r.Namespace("", time.Second, time.Second))
+	assert.Error(t, r.Namespace("payments/", time.Second, time.Second))
+}
+
+func TestRegistry_Layers(t *testing.T) {
+	r := NewRegistry(time.Minute, 2*time.Minute, WithMaxFailures(100))
+	assert.NoError(t, r.Namespace("payments", 0, time.Second, WithLeastReqs(20)))
+
+	// the layers are merged, the zero interval is inherited
+	charges, _ := r.Get("payments/charges")
+	assert.Equal(t, int64(time.Minute), charges.interval)
+	assert.Equal(t, int64(time.Second), charges.cooldown)
+	assert.Equal(t, uint32(20), charges.atLeastReqs)
+	assert.Equal(t, uint32(100), charges.maxFailures)
+
+	refunds, err := r.Configure("payments/refunds", time.Second, time.Second, WithMaxFailures(5))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(20), refunds.atLeastReqs)
+	assert.Equal(t, uint32(5), refunds.maxFailures)
+	search, _ := r.Get("search")
+
+	// a change of a layer is propagated on demand
+	assert.NoError(t, r.Namespace("payments", 0, 0, WithLeastReqs(40)))
+	got, _ := r.Get("payments/charges")
+	assert.True(t, got == charges)
+	charges.Trip(time.Minute)
+
+	assert.NoError(t, r.Propagate("payments"))
+	got, _ = r.Get("payments/charges")
+	assert.False(t, got == charges)
+	// the state is carried over and still shared with the replaced breaker
+	assert.Equal(t, StateOpen, got.State())
+	charges.Reset()
+	charges.Execute(func() error { return nil })
+	assert.Equal(t, StateClosed, got.State())
+	assert.Equal(t, uint32(1), got.Counts().Successes)
+	assert.Equal(t, uint64(1), r.Gauges().Trips)
+	assert.Equal(t, uint32(40), got.atLeastReqs)
+	assert.Equal(t, int64(2*time.Minute), got.cooldown)
+	got, _ = r.Get("payments/refunds")
+	assert.Equal(t, uint32(5), got.maxFailures)
+	got, _ = r.Get("search")
+	assert.True(t, got == search)
+
+	// nothing is changed on an invalid layer
+	r.SetDefaults(0, time.Minute)
+	assert.Error(t, r.Propagate(""))
+	got, _ = r.Get("search")
+	assert.True(t, got == search)
+}
+
+func BenchmarkRegistry_Get(b *testing.B) {
+	r := NewRegistry(time.Minute, 2*time.Minute)
+	names := make([]string, 1024)
+	for i := range names {
+		names[i] = fmt.Sprintf("peer-%d", i)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			r.Get(names[i%len(names)])
+			i++
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Rejection describes a request rejected with ErrBreakerOpen.
+type Rejection struct {
+	Name  string
+	State State
+
+	// the time left before the breaker lets the probes through,
+	// zero in the half-open state or while forced open
+	Remaining time.Duration
+}
+
+// OnReject is called synchronously on every rejected request,
+// e.g. to count and log the shed traffic, it must not block.
+func WithOnReject(fn func(Rejection)) OptionCall {
+	return func(b *Breaker) error {
+		if fn == nil {
+			return errors.New("circuit: onReject must be defined")
+		}
+		b.onReject = fn
+		return nil
+	}
+}
+
+func (b *Breaker) rejected() {
+	if b.onReject == nil {
+		return
+	}
+
+	r := Rejection{Name: b.name, State: b.State()}
+	if r.State == StateOpen && b.override() == forcedNone {
+		if remaining := atomic.LoadInt64(&b.until) - b.now().UnixNano(); remaining > 0 {
+			r.Remaining = time.Duration(remaining)
+		}
+	}
+	b.onReject(r)
+}
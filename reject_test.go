@@ -0,0 +1,35 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_OnReject(t *testing.T) {
+	var rejections []Rejection
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithName("api"),
+		WithOnReject(func(r Rejection) { rejections = append(rejections, r) }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	b.now = now(1520100030)
+	b.Execute(func() error { return nil })
+
+	b.ForceOpen()
+	b.Execute(func() error { return nil })
+
+	assert.Equal(t, []Rejection{
+		{Name: "api", State: StateOpen, Remaining: 90 * time.Second},
+		{Name: "api", State: StateOpen},
+	}, rejections)
+
+	_, err = New(time.Minute, time.Minute, WithOnReject(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package easybreaker
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// window is the ring of the outcomes the strategies of the closed state
+// decide on, see WithSlidingWindow and WithCountWindow.
+type window interface {
+	// observe records n outcomes of requests at now
+	observe(now int64, n uint32, failed bool)
+	snapshot(now int64) (total uint32, failures uint32)
+	reset()
+	// bytes returns the memory held by the ring, see Footprint
+	bytes() int
+}
+
+// the outcomes held by the slots of countRing
+const (
+	outcomeNone = uint32(iota)
+	outcomeSuccess
+	outcomeFailure
+)
+
+func unitOf(outcome uint32) uint64 {
+	switch outcome {
+	case outcomeSuccess:
+		return totalUnit
+	case outcomeFailure:
+		return totalUnit | failureUnit
+	}
+	return 0
+}
+
+// countRing is a count-based window of the last outcomes, recorded by many
+// goroutines without a lock: a producer claims a slot with the sequence,
+// swaps its outcome and adjusts the counts by the replaced one. The counts
+// are transiently off while the producers of the same slot race, they
+// always settle to the outcomes of the slots.
+type countRing struct {
+	seq    uint64
+	counts uint64 // packed as the counts of the breaker
+	slots  []uint32
+}
+
+func newCountRing(size int) *countRing {
+	return &countRing{slots: make([]uint32, size)}
+}
+
+func (r *countRing) record(now int64, failed bool) {
+	outcome := outcomeSuccess
+	if failed {
+		outcome = outcomeFailure
+	}
+	i := (atomic.AddUint64(&r.seq, 1) - 1) % uint64(len(r.slots))
+	old := atomic.SwapUint32(&r.slots[i], outcome)
+	// wraps around on a replaced failure, as a subtraction
+	atomic.AddUint64(&r.counts, unitOf(outcome)-unitOf(old))
+}
+
+func (r *countRing) observe(now int64, n uint32, failed bool) {
+	// the outcomes past the size of the ring replace the first ones
+	if n > uint32(len(r.slots)) {
+		n = uint32(len(r.slots))
+	}
+	for i := uint32(0); i < n; i++ {
+		r.record(now, failed)
+	}
+}
+
+func (r *countRing) snapshot(now int64) (uint32, uint32) {
+	return unpackCounts(atomic.LoadUint64(&r.counts))
+}
+
+func (r *countRing) reset() {
+	for i := range r.slots {
+		if old := atomic.SwapUint32(&r.slots[i], outcomeNone); old != outcomeNone {
+			atomic.AddUint64(&r.counts, -unitOf(old))
+		}
+	}
+}
+
+func (r *countRing) bytes() int {
+	return int(unsafe.Sizeof(*r)) + len(r.slots)*4
+}
+
+// bucketRing is a time-based window of buckets of a width, recorded by many
+// goroutines without a lock. A bucket is recycled by the first producer
+// swapping its epoch, the outcomes recorded at the same instant by the
+// others may be lost.
+type bucketRing struct {
+	width   int64
+	buckets []ringBucket
+}
+
+type ringBucket struct {
+	epoch  int64  // the index of the width the bucket counts
+	counts uint64 // packed as the counts of the breaker
+}
+
+func newBucketRing(size int, width int64) *bucketRing {
+	r := &bucketRing{width: width, buckets: make([]ringBucket, size)}
+	for i := range r.buckets {
+		r.buckets[i].epoch = -1
+	}
+	return r
+}
+
+func (r *bucketRing) record(now int64, failed bool) {
+	unit := totalUnit
+	if failed {
+		unit |= failureUnit
+	}
+	r.add(now, unit)
+}
+
+func (r *bucketRing) observe(now int64, n uint32, failed bool) {
+	unit := totalUnit
+	if failed {
+		unit |= failureUnit
+	}
+	r.add(now, uint64(n)*unit)
+}
+
+// add adds the packed counts to the bucket of now.
+func (r *bucketRing) add(now int64, counts uint64) {
+	epoch := now / r.width
+	b := &r.buckets[epoch%int64(len(r.buckets))]
+	for {
+		e := atomic.LoadInt64(&b.epoch)
+		if e == epoch {
+			break
+		}
+		if e > epoch {
+			return // recorded too late, the bucket moved on
+		}
+		if atomic.CompareAndSwapInt64(&b.epoch, e, epoch) {
+			atomic.StoreUint64(&b.counts, 0)
+			break
+		}
+	}
+	atomic.AddUint64(&b.counts, counts)
+}
+
+func (r *bucketRing) snapshot(now int64) (uint32, uint32) {
+	epoch := now / r.width
+	var total, failures uint32
+	for i := range r.buckets {
+		b := &r.buckets[i]
+		if e := atomic.LoadInt64(&b.epoch); e <= epoch-int64(len(r.buckets)) || e > epoch {
+			continue
+		}
+		t, f := unpackCounts(atomic.LoadUint64(&b.counts))
+		total += t
+		failures += f
+	}
+	return total, failures
+}
+
+func (r *bucketRing) reset() {
+	for i := range r.buckets {
+		atomic.StoreInt64(&r.buckets[i].epoch, -1)
+		atomic.StoreUint64(&r.buckets[i].counts, 0)
+	}
+}
+
+func (r *bucketRing) bytes() int {
+	return int(unsafe.Sizeof(*r)) + len(r.buckets)*int(unsafe.Sizeof(ringBucket{}))
+}
@@ -0,0 +1,129 @@
+package easybreaker
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountRing(t *testing.T) {
+	r := newCountRing(3)
+	r.record(0, true)
+	r.record(0, false)
+	total, failures := r.snapshot(0)
+	assert.Equal(t, uint32(2), total)
+	assert.Equal(t, uint32(1), failures)
+
+	// the oldest failure is replaced
+	r.record(0, false)
+	r.record(0, false)
+	total, failures = r.snapshot(0)
+	assert.Equal(t, uint32(3), total)
+	assert.Equal(t, uint32(0), failures)
+
+	r.reset()
+	total, failures = r.snapshot(0)
+	assert.Equal(t, uint32(0), total)
+	assert.Equal(t, uint32(0), failures)
+}
+
+func TestCountRing_Concurrent(t *testing.T) {
+	r := newCountRing(64)
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(failed bool) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				r.record(0, failed)
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+
+	var failures uint32
+	for _, outcome := range r.slots {
+		if outcome == outcomeFailure {
+			failures++
+		}
+	}
+	total, f := r.snapshot(0)
+	assert.Equal(t, uint32(64), total)
+	assert.Equal(t, failures, f)
+}
+
+func TestBucketRing(t *testing.T) {
+	second := int64(time.Second)
+	r := newBucketRing(3, second)
+	r.record(0, true)
+	r.record(second, false)
+	r.record(2*second, false)
+	total, failures := r.snapshot(2 * second)
+	assert.Equal(t, uint32(3), total)
+	assert.Equal(t, uint32(1), failures)
+
+	// the first bucket expired and is recycled
+	total, failures = r.snapshot(3 * second)
+	assert.Equal(t, uint32(2), total)
+	assert.Equal(t, uint32(0), failures)
+	r.record(3*second, true)
+	r.record(0, true) // too late
+	total, failures = r.snapshot(3 * second)
+	assert.Equal(t, uint32(3), total)
+	assert.Equal(t, uint32(1), failures)
+
+	r.reset()
+	total, _ = r.snapshot(3 * second)
+	assert.Equal(t, uint32(0), total)
+}
+
+// mutexRing is the baseline of the benchmarks
+type mutexRing struct {
+	mu       sync.Mutex
+	slots    []bool
+	next     int
+	total    uint32
+	failures uint32
+}
+
+func (r *mutexRing) record(now int64, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.total == uint32(len(r.slots)) {
+		r.total--
+		if r.slots[r.next] {
+			r.failures--
+		}
+	}
+	r.slots[r.next] = failed
+	r.next = (r.next + 1) % len(r.slots)
+	r.total++
+	if failed {
+		r.failures++
+	}
+}
+
+func benchmarkRing(b *testing.B, record func(now int64, failed bool)) {
+	b.SetParallelism(32)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			record(int64(i), i%16 == 0)
+			i++
+		}
+	})
+}
+
+func BenchmarkCountRing(b *testing.B) {
+	benchmarkRing(b, newCountRing(1024).record)
+}
+
+func BenchmarkBucketRing(b *testing.B) {
+	benchmarkRing(b, newBucketRing(10, 1000).record)
+}
+
+func BenchmarkMutexRing(b *testing.B) {
+	benchmarkRing(b, (&mutexRing{slots: make([]bool, 1024)}).record)
+}
@@ -0,0 +1,105 @@
+package easybreaker
+
+import (
+	"errors"
+)
+
+// SignalFunc returns the badness of a health signal from 0, healthy, to 1,
+// the values out of the range are clamped.
+type SignalFunc func(counts Counts) float64
+
+// Signal is a weighted health signal of Score.
+type Signal struct {
+	Name   string
+	Weight float64
+	Value  SignalFunc
+}
+
+// Score combines weighted health signals, e.g. the failure rate, a latency
+// percentile, the results of external probes and load hints, into one score
+// from 0 to 1 with a trip threshold:
+//
+//	score, err := easybreaker.NewScore(0.5,
+//		easybreaker.Signal{Name: "failures", Weight: 2, Value: easybreaker.FailureRate()},
+//		easybreaker.Signal{Name: "p99", Weight: 1, Value: easybreaker.Gauge(p99Millis, 500)},
+//	)
+//	b, err := easybreaker.New(time.Minute, 10*time.Second, easybreaker.WithStateFunc(score.ToOpen, toClosed))
+type Score struct {
+	signals   []Signal
+	weights   float64
+	threshold float64
+}
+
+// NewScore returns the score of the signals tripping at the threshold.
+func NewScore(threshold float64, signals ...Signal) (*Score, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, errors.New("circuit: score threshold must be in (0, 1]")
+	}
+	if len(signals) == 0 {
+		return nil, errors.New("circuit: score signals must be defined")
+	}
+
+	s := &Score{threshold: threshold}
+	for _, signal := range signals {
+		if signal.Value == nil {
+			return nil, errors.New("circuit: signal value must be defined")
+		}
+		if signal.Weight <= 0 {
+			return nil, errors.New("circuit: signal weight must be positive")
+		}
+		s.weights += signal.Weight
+	}
+	s.signals = signals
+	return s, nil
+}
+
+// Value returns the weighted average of the signals.
+func (s *Score) Value(counts Counts) float64 {
+	var sum float64
+	for _, signal := range s.signals {
+		sum += signal.Weight * clamp(signal.Value(counts))
+	}
+	return sum / s.weights
+}
+
+// Values returns the value of every signal by name, for debugging.
+func (s *Score) Values(counts Counts) map[string]float64 {
+	values := make(map[string]float64, len(s.signals))
+	for _, signal := range s.signals {
+		values[signal.Name] = clamp(signal.Value(counts))
+	}
+	return values
+}
+
+// ToOpen is a ToState reporting whether the score reached the threshold.
+func (s *Score) ToOpen(total uint32, failures uint32) bool {
+	return s.Value(Counts{Total: total, Failures: failures}) >= s.threshold
+}
+
+// FailureRate is the ratio of the failed requests of the interval.
+func FailureRate() SignalFunc {
+	return func(counts Counts) float64 {
+		if counts.Total == 0 {
+			return 0
+		}
+		return float64(counts.Failures) / float64(counts.Total)
+	}
+}
+
+// Gauge turns an external value, e.g. a latency percentile or a queue depth,
+// into a signal reaching 1 at max.
+func Gauge(value func() float64, max float64) SignalFunc {
+	return func(Counts) float64 {
+		return value() / max
+	}
+}
+
+func clamp(v float64) float64 {
+	if v < 0 || v != v {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
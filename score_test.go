@@ -0,0 +1,61 @@
+package easybreaker
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewScore(t *testing.T) {
+	_, err := NewScore(0, Signal{Weight: 1, Value: FailureRate()})
+	assert.Error(t, err)
+	_, err = NewScore(0.5)
+	assert.Error(t, err)
+	_, err = NewScore(0.5, Signal{Weight: 0, Value: FailureRate()})
+	assert.Error(t, err)
+	_, err = NewScore(0.5, Signal{Weight: 1})
+	assert.Error(t, err)
+}
+
+func TestScore_Value(t *testing.T) {
+	latency := 0.0
+	score, err := NewScore(0.5,
+		Signal{Name: "failures", Weight: 3, Value: FailureRate()},
+		Signal{Name: "latency", Weight: 1, Value: Gauge(func() float64 { return latency }, 500)},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, float64(0), score.Value(Counts{}))
+	assert.Equal(t, 0.375, score.Value(Counts{Total: 2, Failures: 1}))
+	assert.False(t, score.ToOpen(2, 1))
+
+	latency = 1000
+	assert.Equal(t, map[string]float64{"failures": 0.5, "latency": 1}, score.Values(Counts{Total: 2, Failures: 1}))
+	assert.Equal(t, 0.625, score.Value(Counts{Total: 2, Failures: 1}))
+	assert.True(t, score.ToOpen(2, 1))
+
+	latency = math.NaN()
+	assert.Equal(t, 0.375, score.Value(Counts{Total: 2, Failures: 1}))
+}
+
+func TestScore_Breaker(t *testing.T) {
+	score, err := NewScore(0.5, Signal{Name: "failures", Weight: 1, Value: FailureRate()})
+	assert.NoError(t, err)
+
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithStateFunc(score.ToOpen, defaultToClosed),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateClosed, b.State())
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+}
@@ -0,0 +1,75 @@
+// Package shared is the convention letting the libraries of a process,
+// e.g. an HTTP client and a DB layer, find the same breaker for the same
+// dependency instead of each creating their own.
+//
+// The application sets the registry, for the process or per request:
+//
+//	shared.SetDefault(registry)
+//	ctx = shared.WithRegistry(ctx, tenantRegistry)
+//
+// and the libraries get the breakers named after the dependency:
+//
+//	b, err := shared.For(ctx, shared.Name("http", req.URL.Host))
+package shared
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// the defaults of the default registry
+const (
+	DefaultInterval = time.Minute
+	DefaultCooldown = 10 * time.Second
+)
+
+type registryKey struct{}
+
+var defaultRegistry atomic.Value // *easybreaker.Registry
+
+func init() {
+	defaultRegistry.Store(easybreaker.NewRegistry(DefaultInterval, DefaultCooldown))
+}
+
+// Default returns the registry of the process.
+func Default() *easybreaker.Registry {
+	return defaultRegistry.Load().(*easybreaker.Registry)
+}
+
+// SetDefault replaces the registry of the process,
+// it's meant to be called by the application on startup.
+func SetDefault(registry *easybreaker.Registry) {
+	if registry != nil {
+		defaultRegistry.Store(registry)
+	}
+}
+
+// WithRegistry returns a context carrying the registry,
+// it takes precedence over the default one.
+func WithRegistry(ctx context.Context, registry *easybreaker.Registry) context.Context {
+	return context.WithValue(ctx, registryKey{}, registry)
+}
+
+// RegistryFrom returns the registry of the context, the default one if none.
+func RegistryFrom(ctx context.Context) *easybreaker.Registry {
+	if registry, ok := ctx.Value(registryKey{}).(*easybreaker.Registry); ok && registry != nil {
+		return registry
+	}
+	return Default()
+}
+
+// For returns the breaker of the dependency from the registry of the context.
+func For(ctx context.Context, name string) (*easybreaker.Breaker, error) {
+	return RegistryFrom(ctx).Get(name)
+}
+
+// Name is the convention of the names of the dependencies, the protocol
+// and the lowercased host, e.g. "http/api.example.com:443" or "postgres/db-primary",
+// so the libraries speaking the same protocol share the breaker of a host.
+func Name(protocol string, host string) string {
+	return strings.ToLower(protocol) + "/" + strings.ToLower(host)
+}
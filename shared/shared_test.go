@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFor(t *testing.T) {
+	ctx := context.Background()
+	name := Name("HTTP", "API.example.com:443")
+	assert.Equal(t, "http/api.example.com:443", name)
+
+	// the libraries share the breaker of the dependency
+	client, err := For(ctx, name)
+	assert.NoError(t, err)
+	other, err := For(ctx, name)
+	assert.NoError(t, err)
+	assert.True(t, client == other)
+
+	registry := easybreaker.NewRegistry(time.Second, time.Second)
+	SetDefault(registry)
+	defer SetDefault(easybreaker.NewRegistry(DefaultInterval, DefaultCooldown))
+	b, _ := For(ctx, name)
+	assert.False(t, b == client)
+	assert.Equal(t, []string{name}, registry.Names())
+
+	tenant := easybreaker.NewRegistry(time.Second, time.Second)
+	b, _ = For(WithRegistry(ctx, tenant), name)
+	got, _ := tenant.Get(name)
+	assert.True(t, b == got)
+
+	SetDefault(nil)
+	assert.True(t, Default() == registry)
+}
@@ -0,0 +1,113 @@
+package easybreaker
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// the layout of shared, its version is bumped along with the struct
+const (
+	sharedVersion = 5
+	sharedMagic   = 0xeb5a0000 | sharedVersion
+)
+
+// shared is the state of the breaker, allocated with the breaker or mapped
+// in the shared memory of the processes of a host with WithSharedMemory.
+// The magic comes first in every version of the layout, so a mapping of
+// another version is told apart, then the 64-bit fields for their alignment.
+type shared struct {
+	magic uint32 // sharedMagic once initialized, 1 while initializing
+	_     uint32
+
+	until       int64  // until timestamp of the interval (in closed state) or cooldown (in open state) period
+	windowStart int64  // the start of the current window
+	openedAt    int64  // the time the breaker left the closed state, 0 while closed
+	epoch       uint64 // the number of the finished windows
+	trips       uint64 // the number of the transitions from the closed state to the open one
+
+	// requests in total (the high 32 bits) and requests returned an error
+	// (the low 32 bits) during the interval, packed to be snapshotted at once
+	counts uint64
+
+	state     int32  // current state
+	forced    int32  // the override of ForceOpen and Disable
+	timeouts  uint32 // requests of the interval failed with a timeout, see IsTimeout
+	successes uint32 // requests of the interval returned no error
+	ignored   uint32 // requests of the interval failed with an ignored error, see CategoryIgnored
+	slow      uint32 // requests of the interval slower than the threshold of WithSlowCallThreshold
+}
+
+// sharedMigrations upgrades in place the mappings of the older layouts of
+// the same size, by magic, the first process of the new version migrates
+// the mapping of the processes still running the old one.
+var sharedMigrations = map[uint32]func(s *shared){
+	// the version 5 appended slow in the padding of the version 4, zeroed
+	sharedMagic&^0xffff | 4: func(s *shared) {},
+}
+
+// SharedMemory maps the state of the breaker, its counts, its timers and its
+// manual control, in the file at path, typically in /dev/shm, so the processes
+// of a host, e.g. the prefork workers, share one breaker without a network
+// store. Every process must set the same settings, the events, the trip
+// reasons, the histograms and the requests in flight stay per process.
+// The memory is mapped for the life of the process.
+func WithSharedMemory(path string) OptionCall {
+	return func(b *Breaker) error {
+		if path == "" {
+			return errors.New("circuit: shared memory path must be set")
+		}
+		data, err := mapShared(path, int(unsafe.Sizeof(shared{})))
+		if err != nil {
+			return err
+		}
+
+		s := (*shared)(unsafe.Pointer(&data[0]))
+		if magic := atomic.LoadUint32(&s.magic); magic > 1 && magic != sharedMagic {
+			if migrate, ok := sharedMigrations[magic]; ok {
+				// the waiters of init see the mapping initializing meanwhile
+				if atomic.CompareAndSwapUint32(&s.magic, magic, 1) {
+					migrate(s)
+					atomic.StoreUint32(&s.magic, sharedMagic)
+				}
+				b.shared = s
+				return nil
+			}
+			if magic&^0xffff == sharedMagic&^0xffff {
+				return fmt.Errorf("circuit: shared memory of layout version %d, not %d", magic&0xffff, sharedVersion)
+			}
+			return errors.New("circuit: shared memory of another layout")
+		}
+		b.shared = s
+		return nil
+	}
+}
+
+// sharedInitTimeout is how long a process waits for another one
+// initializing the shared memory, in real time.
+const sharedInitTimeout = time.Second
+
+// init starts the first interval unless another process did already, it
+// fails if the process initializing the shared memory didn't finish in time,
+// e.g. it died halfway.
+func (b *Breaker) init() error {
+	if atomic.CompareAndSwapUint32(&b.magic, 0, 1) {
+		start := b.now().UnixNano()
+		atomic.StoreInt64(&b.windowStart, start)
+		atomic.StoreInt64(&b.until, start+b.interval)
+		atomic.StoreUint32(&b.magic, sharedMagic)
+		return nil
+	}
+
+	deadline := time.Now().Add(sharedInitTimeout)
+	for atomic.LoadUint32(&b.magic) == 1 {
+		if time.Now().After(deadline) {
+			return errors.New("circuit: shared memory initialization timed out, the file must be removed")
+		}
+		runtime.Gosched()
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+
+package easybreaker
+
+import (
+	"errors"
+)
+
+// mapShared is not supported.
+func mapShared(path string, size int) ([]byte, error) {
+	return nil, errors.New("circuit: shared memory not supported")
+}
@@ -0,0 +1,65 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package easybreaker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_SharedMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.breaker")
+
+	// two mappings of the file share the memory as two processes would
+	first, err := New(time.Minute, time.Minute, WithSharedMemory(path), withTime(1520100000))
+	assert.NoError(t, err)
+	second, err := New(time.Minute, time.Minute, WithSharedMemory(path), withTime(1520100030))
+	assert.NoError(t, err)
+	assert.Equal(t, first.until, second.until)
+
+	assert.NoError(t, first.Execute(func() error { return nil }))
+	assert.Equal(t, Counts{Total: 1, Successes: 1}, second.Counts())
+
+	second.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, first.State())
+	assert.Equal(t, uint64(1), first.Trips())
+
+	first.Disable()
+	assert.Equal(t, StateClosed, second.State())
+
+	// a mapping of the previous version of the layout is migrated
+	previous := make([]byte, unsafe.Sizeof(shared{}))
+	(*shared)(unsafe.Pointer(&previous[0])).magic = sharedMagic - 1
+	(*shared)(unsafe.Pointer(&previous[0])).trips = 3
+	assert.NoError(t, os.WriteFile(path, previous, 0o600))
+	migrated, err := New(time.Minute, time.Minute, WithSharedMemory(path))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(sharedMagic), migrated.magic)
+	assert.Equal(t, uint64(3), migrated.Trips())
+
+	// a mapping of another version of the layout is refused
+	other := make([]byte, unsafe.Sizeof(shared{}))
+	(*shared)(unsafe.Pointer(&other[0])).magic = sharedMagic - 2
+	assert.NoError(t, os.WriteFile(path, other, 0o600))
+	_, err = New(time.Minute, time.Minute, WithSharedMemory(path))
+	assert.EqualError(t, err, "circuit: shared memory of layout version 3, not 5")
+
+	// the initialization of a process which died halfway is not awaited forever
+	initializing := make([]byte, unsafe.Sizeof(shared{}))
+	(*shared)(unsafe.Pointer(&initializing[0])).magic = 1
+	assert.NoError(t, os.WriteFile(path, initializing, 0o600))
+	_, err = New(time.Minute, time.Minute, WithSharedMemory(path))
+	assert.EqualError(t, err, "circuit: shared memory initialization timed out, the file must be removed")
+
+	assert.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = New(time.Minute, time.Minute, WithSharedMemory(path))
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithSharedMemory(""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package easybreaker
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// mapShared maps size bytes of the file at path, created zeroed if needed.
+func mapShared(path string, size int) ([]byte, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	switch fi.Size() {
+	case 0:
+		if err := f.Truncate(int64(size)); err != nil {
+			return nil, err
+		}
+	case int64(size):
+	default:
+		return nil, errors.New("circuit: shared memory of another layout")
+	}
+
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
@@ -0,0 +1,80 @@
+package sidecar
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Client is the Go client of the server, e.g. for the tests of the
+// clients in other languages. It's safe for concurrent use, the
+// requests are serialized on the connection.
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Dial connects to the server at the unix socket path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+func (c *Client) do(request string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.conn.Write([]byte(request + "\n")); err != nil {
+		return "", err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	if strings.HasPrefix(line, "ERR ") {
+		return "", errors.New("sidecar: " + strings.TrimPrefix(line, "ERR "))
+	}
+	return line, nil
+}
+
+// Allow returns easybreaker.ErrBreakerOpen when the breaker rejects the request,
+// an accepted request must be recorded with Record.
+func (c *Client) Allow(name string) error {
+	resp, err := c.do("ALLOW " + name)
+	if err != nil {
+		return err
+	}
+	if resp == "OPEN" {
+		return easybreaker.ErrBreakerOpen
+	}
+	return nil
+}
+
+// Record reports the outcome of a request accepted by Allow.
+func (c *Client) Record(name string, failed bool) error {
+	outcome := "ok"
+	if failed {
+		outcome = "fail"
+	}
+	_, err := c.do("RECORD " + name + " " + outcome)
+	return err
+}
+
+// State returns the state of the breaker.
+func (c *Client) State(name string) (string, error) {
+	return c.do("STATE " + name)
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,192 @@
+// Package sidecar serves the decisions of the breakers of a registry over
+// a unix socket, so the processes written in other languages on the same
+// host share the circuits of the Go ones.
+//
+// The protocol is a line of text per request and per response:
+//
+//	ALLOW <name>             -> OK | OPEN
+//	RECORD <name> ok|fail    -> OK
+//	STATE <name>             -> closed | half-open | open
+//
+// A malformed request is answered by "ERR <message>", a line longer than
+// MaxLineLength closes the connection. Only ALLOW creates the breakers,
+// STATE answers closed for an unknown one. Every accepted ALLOW
+// must be followed by a RECORD on the same connection, the requests
+// accepted and not recorded when the connection is closed count as successes.
+package sidecar
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// MaxLineLength is the longest request accepted, newline included.
+const MaxLineLength = 4096
+
+var errFailed = errors.New("sidecar: failed")
+
+// Server answers the requests of the clients with the breakers of a registry.
+type Server struct {
+	registry *easybreaker.Registry
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer returns a server of the breakers of registry.
+func NewServer(registry *easybreaker.Registry) (*Server, error) {
+	if registry == nil {
+		return nil, errors.New("sidecar: registry must be set")
+	}
+	return &Server{registry: registry, conns: make(map[net.Conn]struct{})}, nil
+}
+
+// ListenAndServe listens on the unix socket at path, replacing a stale one.
+func (s *Server) ListenAndServe(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts the connections of l until Close.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return net.ErrClosed
+	}
+	s.listeners = append(s.listeners, l)
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.serve(conn)
+	}
+}
+
+// Close stops the listeners and closes the connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for _, l := range s.listeners {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Server) serve(conn net.Conn) {
+	// the requests accepted by ALLOW and not recorded yet
+	pending := make(map[*easybreaker.Breaker]int)
+	defer func() {
+		for b, n := range pending {
+			for ; n > 0; n-- {
+				b.Done(nil)
+			}
+		}
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	r := bufio.NewReaderSize(conn, MaxLineLength)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			w.WriteString("ERR request too long\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		w.WriteString(s.handle(string(line), pending))
+		w.WriteByte('\n')
+		// the pipelined requests are answered at once
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) handle(line string, pending map[*easybreaker.Breaker]int) string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "ERR malformed request"
+	}
+
+	name := fields[1]
+	switch strings.ToUpper(fields[0]) {
+	case "ALLOW":
+		b, err := s.registry.Get(name)
+		if err != nil {
+			return "ERR " + err.Error()
+		}
+		if b.Allow() != nil {
+			return "OPEN"
+		}
+		pending[b]++
+		return "OK"
+	case "RECORD":
+		if len(fields) != 3 || (fields[2] != "ok" && fields[2] != "fail") {
+			return "ERR malformed request"
+		}
+		b, ok := s.registry.Lookup(name)
+		if !ok || pending[b] == 0 {
+			return "ERR not allowed"
+		}
+		pending[b]--
+		if fields[2] == "fail" {
+			b.Done(errFailed)
+		} else {
+			b.Done(nil)
+		}
+		return "OK"
+	case "STATE":
+		b, ok := s.registry.Lookup(name)
+		if !ok {
+			return easybreaker.StateClosed.String()
+		}
+		return b.State().String()
+	}
+	return "ERR unknown command"
+}
@@ -0,0 +1,109 @@
+package sidecar
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	reg := easybreaker.NewRegistry(time.Minute, time.Minute, easybreaker.WithStateFunc(
+		func(total uint32, failures uint32) bool { return failures >= 2 },
+		func(uint32, uint32) bool { return true },
+	))
+	s, err := NewServer(reg)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "breakers.sock")
+	served := make(chan error, 1)
+	go func() { served <- s.ListenAndServe(path) }()
+
+	var c *Client
+	assert.Eventually(t, func() bool {
+		c, err = Dial(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, c.Allow("api"))
+		assert.NoError(t, c.Record("api", true))
+	}
+	assert.Equal(t, easybreaker.ErrBreakerOpen, c.Allow("api"))
+	state, err := c.State("api")
+	assert.NoError(t, err)
+	assert.Equal(t, "open", state)
+
+	assert.EqualError(t, c.Record("db", false), "sidecar: not allowed")
+	_, err = c.do("PING")
+	assert.EqualError(t, err, "sidecar: malformed request")
+	_, err = c.do("PING db")
+	assert.EqualError(t, err, "sidecar: unknown command")
+	state, err = c.State("cache")
+	assert.NoError(t, err)
+	assert.Equal(t, "closed", state)
+	// only ALLOW creates the breakers
+	_, ok := reg.Lookup("db")
+	assert.False(t, ok)
+	_, ok = reg.Lookup("cache")
+	assert.False(t, ok)
+
+	// the pending requests are released with the connection
+	assert.NoError(t, c.Allow("db"))
+	db, _ := reg.Get("db")
+	assert.Equal(t, uint32(1), db.Counts().InFlight)
+	c.Close()
+	assert.Eventually(t, func() bool { return db.Counts().InFlight == 0 }, time.Second, time.Millisecond)
+
+	assert.NoError(t, s.Close())
+	assert.NoError(t, <-served)
+
+	_, err = NewServer(nil)
+	assert.Error(t, err)
+}
+
+func TestServer_Pipelined(t *testing.T) {
+	s, err := NewServer(easybreaker.NewRegistry(time.Minute, time.Minute))
+	assert.NoError(t, err)
+
+	client, server := net.Pipe()
+	s.wg.Add(1)
+	s.conns[server] = struct{}{}
+	go s.serve(server)
+
+	go client.Write([]byte("ALLOW api\nRECORD api ok\nSTATE api\n"))
+	buf := make([]byte, 64)
+	var got []byte
+	for len(got) < len("OK\nOK\nclosed\n") {
+		n, err := client.Read(buf)
+		assert.NoError(t, err)
+		got = append(got, buf[:n]...)
+	}
+	assert.Equal(t, "OK\nOK\nclosed\n", string(got))
+	client.Close()
+	s.wg.Wait()
+}
+
+func TestServer_LongLine(t *testing.T) {
+	reg := easybreaker.NewRegistry(time.Minute, time.Minute)
+	s, err := NewServer(reg)
+	assert.NoError(t, err)
+
+	client, server := net.Pipe()
+	s.wg.Add(1)
+	s.conns[server] = struct{}{}
+	go s.serve(server)
+
+	go client.Write([]byte("ALLOW " + strings.Repeat("a", MaxLineLength) + "\n"))
+	got, err := bufio.NewReader(client).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "ERR request too long\n", got)
+	s.wg.Wait()
+	assert.Empty(t, reg.Names())
+	client.Close()
+}
@@ -0,0 +1,169 @@
+// Package sink provides event sinks for the breakers, they are subscribed
+// with their Write method:
+//
+//	s := sink.NewJSONLines(f)
+//	defer s.Close()
+//	b, err := easybreaker.New(time.Minute, 10*time.Second, easybreaker.WithSink(easybreaker.SeverityInfo, s.Write))
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// ErrClosed is returned once the sink is closed.
+var ErrClosed = errors.New("sink: closed")
+
+// Record is the serialized form of an event.
+type Record struct {
+	Source    string            `json:"source,omitempty"` // the host of the breaker, set by the fleet-wide sinks
+	Name      string            `json:"name"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Type      string            `json:"type"`
+	Severity  string            `json:"severity"`
+	Time      time.Time         `json:"time"`
+	From      string            `json:"from,omitempty"`
+	To        string            `json:"to,omitempty"`
+	Total     uint32            `json:"total"`
+	Failures  uint32            `json:"failures"`
+	Successes uint32            `json:"successes"`
+	Timeouts  uint32            `json:"timeouts,omitempty"`
+	Ignored   uint32            `json:"ignored,omitempty"`
+	Slow      uint32            `json:"slow,omitempty"`
+	InFlight  uint32            `json:"inflight"`
+}
+
+// NewRecord returns the serialized form of e.
+func NewRecord(e easybreaker.Event) Record {
+	r := Record{
+		Name:      e.Name,
+		Tags:      e.Tags,
+		Type:      e.Type.String(),
+		Severity:  e.Severity.String(),
+		Time:      e.Time,
+		Total:     e.Counts.Total,
+		Failures:  e.Counts.Failures,
+		Successes: e.Counts.Successes,
+		Timeouts:  e.Counts.Timeouts,
+		Ignored:   e.Counts.Ignored,
+		Slow:      e.Counts.Slow,
+		InFlight:  e.Counts.InFlight,
+	}
+	if e.Type == easybreaker.EventStateChange || e.Type == easybreaker.EventRollover {
+		r.From = e.From.String()
+		r.To = e.To.String()
+	}
+	return r
+}
+
+// JSONLines writes the events as JSON lines to a writer in the background,
+// each line is encoded on its own and written whole by a single Write, so
+// the writer can be a file rotated by an external tool with copytruncate,
+// a pipe or a socket. The events are dropped while the buffer is full
+// rather than blocking the requests.
+type JSONLines struct {
+	w       io.Writer
+	lines   chan jsonLine
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.RWMutex
+	closed  bool
+	dropped uint64
+	err     error // the first write error, owned by run
+}
+
+// jsonLine is a line to write or, without a line, a flush waiting
+// for the lines queued before it.
+type jsonLine struct {
+	line    []byte
+	flushed chan error
+}
+
+// jsonLinesBuffer is the number of the lines queued before they are dropped.
+const jsonLinesBuffer = 1024
+
+// NewJSONLines returns a sink writing to w, the writer is not closed by Close.
+func NewJSONLines(w io.Writer) *JSONLines {
+	s := &JSONLines{w: w, lines: make(chan jsonLine, jsonLinesBuffer), done: make(chan struct{})}
+	go s.run()
+	return s
+}
+
+// Write queues the event, it's the function subscribed to the breakers.
+// The write errors are reported by Flush and Close.
+func (s *JSONLines) Write(e easybreaker.Event) {
+	line, err := json.Marshal(NewRecord(e))
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.lines <- jsonLine{line: line}:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+func (s *JSONLines) run() {
+	defer close(s.done)
+
+	for l := range s.lines {
+		if l.flushed != nil {
+			l.flushed <- s.err
+			continue
+		}
+		if s.err == nil {
+			_, s.err = s.w.Write(l.line)
+		}
+	}
+}
+
+// Dropped returns the number of the events dropped on a full buffer.
+func (s *JSONLines) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Flush waits for the queued events to be written and returns the first
+// write error, the events following it are dropped.
+func (s *JSONLines) Flush() error {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return ErrClosed
+	}
+	flushed := make(chan error, 1)
+	s.lines <- jsonLine{flushed: flushed}
+	s.mu.RUnlock()
+
+	return <-flushed
+}
+
+// Close writes the queued events, the following events are dropped.
+func (s *JSONLines) Close() error {
+	closed := true
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.lines)
+		s.mu.Unlock()
+		closed = false
+	})
+	if closed {
+		return ErrClosed
+	}
+	<-s.done
+	return s.err
+}
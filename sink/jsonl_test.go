@@ -0,0 +1,109 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONLines(&buf)
+
+	ts := time.Unix(1520100000, 0)
+	b, err := easybreaker.New(
+		time.Minute, 2*time.Minute,
+		easybreaker.WithName("api"),
+		easybreaker.WithStateFunc(
+			func(uint32, uint32) bool { return true },
+			func(uint32, uint32) bool { return true },
+		),
+		easybreaker.WithNow(func() time.Time { return ts }),
+		easybreaker.WithSink(easybreaker.SeverityDebug, s.Write),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return nil })
+
+	assert.NoError(t, s.Flush())
+	assert.NoError(t, s.Close())
+	assert.Equal(t, uint64(0), s.Dropped())
+
+	var records []Record
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r Record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	assert.Len(t, records, 3)
+	assert.Equal(t, "admitted", records[0].Type)
+	assert.Equal(t, Record{
+		Name: "api", Type: "state-change", Severity: "warn", Time: records[1].Time,
+		From: "closed", To: "open", Total: 1, Failures: 1,
+	}, records[1])
+	assert.True(t, ts.Equal(records[1].Time))
+	assert.Equal(t, "rejected", records[2].Type)
+
+	b.Execute(func() error { return nil })
+	assert.Equal(t, ErrClosed, s.Flush())
+	assert.Equal(t, ErrClosed, s.Close())
+
+	s = NewJSONLines(failingWriter{})
+	s.Write(easybreaker.Event{})
+	assert.Error(t, s.Flush())
+	assert.Error(t, s.Close())
+}
+
+// lineWriter records the writes, each one must be a whole line.
+type lineWriter struct {
+	writes  chan []byte
+	entered chan struct{}
+	blocked chan struct{}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.blocked
+	w.writes <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+func TestJSONLines_Background(t *testing.T) {
+	w := &lineWriter{writes: make(chan []byte, jsonLinesBuffer+1), entered: make(chan struct{}, 1), blocked: make(chan struct{})}
+	s := NewJSONLines(w)
+
+	// the writer blocks on the first line, the next ones are queued and then dropped
+	s.Write(easybreaker.Event{Name: "api", Type: easybreaker.EventAdmitted})
+	<-w.entered
+	for i := 0; i < jsonLinesBuffer+1; i++ {
+		s.Write(easybreaker.Event{Name: "api", Type: easybreaker.EventAdmitted})
+	}
+	assert.Equal(t, uint64(1), s.Dropped())
+
+	close(w.blocked)
+	assert.NoError(t, s.Close())
+	close(w.writes)
+	n := 0
+	for p := range w.writes {
+		var r Record
+		assert.NoError(t, json.Unmarshal(p, &r))
+		assert.Equal(t, byte('\n'), p[len(p)-1])
+		n++
+	}
+	assert.Equal(t, jsonLinesBuffer+1, n)
+}
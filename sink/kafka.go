@@ -0,0 +1,120 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Producer publishes a message to a Kafka topic, it's implemented
+// on top of the client of the application, e.g. sarama or franz-go,
+// so the package doesn't depend on any of them.
+type Producer interface {
+	Produce(topic string, key, value []byte) error
+}
+
+// Kafka publishes the state changes of the breakers to a Kafka topic,
+// keyed by the name of the breaker so the changes of a breaker are ordered.
+// The events are published in the background, they are dropped while
+// the buffer is full rather than blocking the requests.
+type Kafka struct {
+	producer Producer
+	topic    string
+	source   string
+
+	events  chan easybreaker.Event
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.RWMutex
+	closed  bool
+	dropped uint64
+	err     atomic.Value // the last produce error
+}
+
+// NewKafka returns a sink publishing to topic with a buffer of size events,
+// the messages are the records with the host name as source.
+func NewKafka(producer Producer, topic string, size int) (*Kafka, error) {
+	if producer == nil {
+		return nil, errors.New("sink: producer must be set")
+	}
+	if topic == "" {
+		return nil, errors.New("sink: topic must be set")
+	}
+	if size <= 0 {
+		return nil, errors.New("sink: buffer size must be positive")
+	}
+
+	host, _ := os.Hostname()
+	k := &Kafka{
+		producer: producer,
+		topic:    topic,
+		source:   host,
+		events:   make(chan easybreaker.Event, size),
+		done:     make(chan struct{}),
+	}
+	go k.run()
+	return k, nil
+}
+
+// Write queues the state changes, it's the function subscribed to the breakers.
+func (k *Kafka) Write(e easybreaker.Event) {
+	if e.Type != easybreaker.EventStateChange {
+		return
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.closed {
+		return
+	}
+	select {
+	case k.events <- e:
+	default:
+		atomic.AddUint64(&k.dropped, 1)
+	}
+}
+
+func (k *Kafka) run() {
+	defer close(k.done)
+
+	for e := range k.events {
+		r := NewRecord(e)
+		r.Source = k.source
+		value, err := json.Marshal(r)
+		if err == nil {
+			err = k.producer.Produce(k.topic, []byte(e.Name), value)
+		}
+		if err != nil {
+			k.err.Store(err)
+		}
+	}
+}
+
+// Dropped returns the number of the events dropped on a full buffer.
+func (k *Kafka) Dropped() uint64 {
+	return atomic.LoadUint64(&k.dropped)
+}
+
+// Err returns the last error of the producer.
+func (k *Kafka) Err() error {
+	err, _ := k.err.Load().(error)
+	return err
+}
+
+// Close publishes the queued events and returns the last error of the producer,
+// the producer itself is not closed.
+func (k *Kafka) Close() error {
+	k.once.Do(func() {
+		k.mu.Lock()
+		k.closed = true
+		close(k.events)
+		k.mu.Unlock()
+	})
+	<-k.done
+	return k.Err()
+}
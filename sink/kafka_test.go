@@ -0,0 +1,93 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+type message struct {
+	topic string
+	key   string
+	value Record
+}
+
+type producer struct {
+	mu       sync.Mutex
+	messages []message
+	block    chan struct{}
+	err      error
+}
+
+func (p *producer) Produce(topic string, key, value []byte) error {
+	if p.block != nil {
+		<-p.block
+	}
+	var r Record
+	if err := json.Unmarshal(value, &r); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.messages = append(p.messages, message{topic: topic, key: string(key), value: r})
+	p.mu.Unlock()
+	return p.err
+}
+
+func TestKafka(t *testing.T) {
+	p := &producer{}
+	k, err := NewKafka(p, "breakers", 10)
+	assert.NoError(t, err)
+
+	b, err := easybreaker.New(
+		time.Minute, 2*time.Minute,
+		easybreaker.WithName("api"),
+		easybreaker.WithStateFunc(
+			func(uint32, uint32) bool { return true },
+			func(uint32, uint32) bool { return true },
+		),
+		easybreaker.WithSink(easybreaker.SeverityDebug, k.Write),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return nil })
+	assert.NoError(t, k.Close())
+
+	// only the state change is published
+	assert.Len(t, p.messages, 1)
+	assert.Equal(t, "breakers", p.messages[0].topic)
+	assert.Equal(t, "api", p.messages[0].key)
+	assert.Equal(t, "open", p.messages[0].value.To)
+	assert.NotEmpty(t, p.messages[0].value.Source)
+
+	// dropped after Close
+	k.Write(easybreaker.Event{Type: easybreaker.EventStateChange})
+	assert.Len(t, p.messages, 1)
+
+	_, err = NewKafka(nil, "breakers", 10)
+	assert.Error(t, err)
+	_, err = NewKafka(p, "", 10)
+	assert.Error(t, err)
+	_, err = NewKafka(p, "breakers", 0)
+	assert.Error(t, err)
+}
+
+func TestKafka_Dropped(t *testing.T) {
+	p := &producer{block: make(chan struct{}), err: errors.New("broker down")}
+	k, err := NewKafka(p, "breakers", 1)
+	assert.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		k.Write(easybreaker.Event{Type: easybreaker.EventStateChange})
+	}
+	close(p.block)
+
+	// the first one is being produced or queued, along with a second one at most
+	assert.True(t, k.Dropped() >= 3)
+	assert.EqualError(t, k.Close(), "broker down")
+}
@@ -0,0 +1,109 @@
+package sink
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Notifier is notified of the events of the breakers,
+// e.g. an integration with Slack or PagerDuty.
+type Notifier interface {
+	Notify(e easybreaker.Event)
+}
+
+// NotifierFunc adapts a function, e.g. the Write method of a sink, to Notifier.
+type NotifierFunc func(easybreaker.Event)
+
+func (fn NotifierFunc) Notify(e easybreaker.Event) {
+	fn(e)
+}
+
+// Limiter protects a notifier from the flapping breakers, each breaker
+// notifies burst events at most and then one every period, and an event
+// repeating the previous one of the breaker within the period is dropped.
+// The periods are measured with the time of the events.
+type Limiter struct {
+	next   Notifier
+	every  time.Duration
+	burst  float64
+	mu     sync.Mutex
+	states map[string]*limiterState
+
+	suppressed uint64
+}
+
+type limiterState struct {
+	tokens float64
+	last   time.Time // the time of the last notified event
+	typ    easybreaker.EventType
+	to     easybreaker.State
+}
+
+// NewLimiter returns a notifier forwarding the events to next.
+func NewLimiter(next Notifier, every time.Duration, burst int) (*Limiter, error) {
+	if next == nil {
+		return nil, errors.New("sink: notifier must be set")
+	}
+	if every <= 0 {
+		return nil, errors.New("sink: period must be positive")
+	}
+	if burst <= 0 {
+		return nil, errors.New("sink: burst must be positive")
+	}
+	return &Limiter{
+		next:   next,
+		every:  every,
+		burst:  float64(burst),
+		states: make(map[string]*limiterState),
+	}, nil
+}
+
+// Notify forwards the event unless it's rate limited or a duplicate,
+// the subscribed function is l.Notify.
+func (l *Limiter) Notify(e easybreaker.Event) {
+	if !l.allow(e) {
+		atomic.AddUint64(&l.suppressed, 1)
+		return
+	}
+	l.next.Notify(e)
+}
+
+func (l *Limiter) allow(e easybreaker.Event) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.states[e.Name]
+	if !ok {
+		l.states[e.Name] = &limiterState{tokens: l.burst - 1, last: e.Time, typ: e.Type, to: e.To}
+		return true
+	}
+
+	elapsed := e.Time.Sub(s.last)
+	if elapsed < l.every && e.Type == s.typ && e.To == s.to {
+		return false
+	}
+
+	if elapsed > 0 {
+		s.tokens += float64(elapsed) / float64(l.every)
+		if s.tokens > l.burst {
+			s.tokens = l.burst
+		}
+		s.last = e.Time
+	}
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	s.typ = e.Type
+	s.to = e.To
+	return true
+}
+
+// Suppressed returns the number of the dropped events.
+func (l *Limiter) Suppressed() uint64 {
+	return atomic.LoadUint64(&l.suppressed)
+}
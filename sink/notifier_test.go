@@ -0,0 +1,44 @@
+package sink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	var notified []easybreaker.Event
+	l, err := NewLimiter(NotifierFunc(func(e easybreaker.Event) { notified = append(notified, e) }), time.Minute, 2)
+	assert.NoError(t, err)
+
+	ts := time.Unix(1520100000, 0)
+	change := func(name string, to easybreaker.State, after time.Duration) {
+		ts = ts.Add(after)
+		l.Notify(easybreaker.Event{Name: name, Type: easybreaker.EventStateChange, To: to, Time: ts})
+	}
+
+	change("api", easybreaker.StateOpen, 0)
+	change("api", easybreaker.StateOpen, time.Second) // duplicate
+	change("api", easybreaker.StateClosed, time.Second)
+	change("api", easybreaker.StateOpen, time.Second) // burst exhausted
+	change("db", easybreaker.StateOpen, 0)            // another breaker
+	assert.Len(t, notified, 3)
+	assert.Equal(t, uint64(2), l.Suppressed())
+
+	// a token is back after the period
+	change("api", easybreaker.StateOpen, time.Minute)
+	assert.Len(t, notified, 4)
+	assert.Equal(t, "api", notified[3].Name)
+
+	_, err = NewLimiter(nil, time.Minute, 1)
+	assert.Error(t, err)
+	_, err = NewLimiter(NotifierFunc(nil), 0, 1)
+	assert.Error(t, err)
+}
+func TestLimiter_Invalid(t *testing.T) {
+	next := NotifierFunc(func(easybreaker.Event) {})
+	_, err := NewLimiter(next, time.Minute, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+const (
+	defaultWebhookTimeout = 5 * time.Second
+	defaultWebhookRetries = 3
+	defaultWebhookBackoff = time.Second
+	webhookBuffer         = 64
+)
+
+// Webhook posts the record of an event as JSON to a URL when a breaker
+// opens or closes. The requests are sent in the background and retried
+// on the network errors and the 5xx responses.
+type Webhook struct {
+	url     string
+	client  *http.Client
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	source  string
+
+	events  chan easybreaker.Event
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.RWMutex
+	closed  bool
+	dropped uint64
+	err     atomic.Value // the last failed notification
+}
+
+type OptionCall func(*Webhook) error
+
+// Timeout is the timeout of a single request, 5 seconds by default.
+func WithTimeout(timeout time.Duration) OptionCall {
+	return func(w *Webhook) error {
+		if timeout <= 0 {
+			return errors.New("sink: timeout must be positive")
+		}
+		w.timeout = timeout
+		return nil
+	}
+}
+
+// Retries is the number of the retries of a failed request, 3 by default,
+// the backoff doubles after every retry. The 4xx responses are not retried,
+// except 408 Request Timeout and 429 Too Many Requests, the request
+// won't succeed.
+func WithRetries(retries int, backoff time.Duration) OptionCall {
+	return func(w *Webhook) error {
+		if retries < 0 {
+			return errors.New("sink: retries must not be negative")
+		}
+		if backoff <= 0 {
+			return errors.New("sink: backoff must be positive")
+		}
+		w.retries = retries
+		w.backoff = backoff
+		return nil
+	}
+}
+
+// Client is the http client of the requests, http.DefaultClient by default.
+func WithClient(client *http.Client) OptionCall {
+	return func(w *Webhook) error {
+		if client == nil {
+			return errors.New("sink: client must be set")
+		}
+		w.client = client
+		return nil
+	}
+}
+
+// NewWebhook returns a sink posting to url.
+func NewWebhook(url string, fns ...OptionCall) (*Webhook, error) {
+	if url == "" {
+		return nil, errors.New("sink: url must be set")
+	}
+
+	host, _ := os.Hostname()
+	w := &Webhook{
+		url:     url,
+		client:  http.DefaultClient,
+		timeout: defaultWebhookTimeout,
+		retries: defaultWebhookRetries,
+		backoff: defaultWebhookBackoff,
+		source:  host,
+		events:  make(chan easybreaker.Event, webhookBuffer),
+		done:    make(chan struct{}),
+	}
+	for _, fn := range fns {
+		if err := fn(w); err != nil {
+			return nil, err
+		}
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write queues the openings and the closings,
+// it's the function subscribed to the breakers.
+func (w *Webhook) Write(e easybreaker.Event) {
+	if e.Type != easybreaker.EventStateChange || e.To == easybreaker.StateHalfOpen {
+		return
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return
+	}
+	select {
+	case w.events <- e:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+}
+
+func (w *Webhook) run() {
+	defer close(w.done)
+
+	for e := range w.events {
+		r := NewRecord(e)
+		r.Source = w.source
+		body, err := json.Marshal(r)
+		if err == nil {
+			err = w.post(body)
+		}
+		if err != nil {
+			w.err.Store(err)
+		}
+	}
+}
+
+func (w *Webhook) post(body []byte) error {
+	backoff := w.backoff
+	for retry := 0; ; retry++ {
+		permanent, err := w.send(body)
+		if err == nil || permanent || retry == w.retries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// send posts the body, the error is permanent if retrying is pointless.
+func (w *Webhook) send(body []byte) (permanent bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return permanentStatus(resp.StatusCode), fmt.Errorf("sink: webhook responded %s", resp.Status)
+	}
+	return false, nil
+}
+
+// permanentStatus reports whether the status of a failed response
+// is a mistake of the request rather than of the server.
+func permanentStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return code >= http.StatusBadRequest && code < http.StatusInternalServerError
+}
+
+// Dropped returns the number of the events dropped on a full buffer.
+func (w *Webhook) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Err returns the error of the last failed notification.
+func (w *Webhook) Err() error {
+	err, _ := w.err.Load().(error)
+	return err
+}
+
+// Close sends the queued notifications and returns the error
+// of the last failed one.
+func (w *Webhook) Close() error {
+	w.once.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		close(w.events)
+		w.mu.Unlock()
+	})
+	<-w.done
+	return w.Err()
+}
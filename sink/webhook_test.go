@@ -0,0 +1,108 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		records  []Record
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var rec Record
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&rec))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		records = append(records, rec)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhook(srv.URL, WithRetries(1, time.Millisecond), WithTimeout(time.Second))
+	assert.NoError(t, err)
+
+	ts := time.Unix(1520100000, 0)
+	b, err := easybreaker.New(
+		time.Minute, 2*time.Minute,
+		easybreaker.WithName("api"),
+		easybreaker.WithLeastReqs(1),
+		easybreaker.WithStateFunc(
+			func(uint32, uint32) bool { return true },
+			func(uint32, uint32) bool { return true },
+		),
+		easybreaker.WithNow(func() time.Time { return ts }),
+		easybreaker.WithSink(easybreaker.SeverityInfo, w.Write),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	ts = ts.Add(3 * time.Minute)
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+	assert.NoError(t, w.Close())
+
+	// the half-open state is not notified, the first request is retried
+	assert.Equal(t, 3, attempts)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "open", records[0].To)
+	assert.Equal(t, "closed", records[1].To)
+	assert.Equal(t, "api", records[1].Name)
+}
+
+func TestWebhook_Failed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhook(srv.URL, WithRetries(2, time.Millisecond))
+	assert.NoError(t, err)
+	w.Write(easybreaker.Event{Type: easybreaker.EventStateChange, To: easybreaker.StateOpen})
+	assert.EqualError(t, w.Close(), "sink: webhook responded 500 Internal Server Error")
+
+	// the 4xx are not retried, except 408 and 429
+	for code, want := range map[int]int{
+		http.StatusBadRequest:      1,
+		http.StatusNotFound:        1,
+		http.StatusRequestTimeout:  3,
+		http.StatusTooManyRequests: 3,
+	} {
+		attempts := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(code)
+		}))
+		w, err := NewWebhook(srv.URL, WithRetries(2, time.Millisecond))
+		assert.NoError(t, err)
+		w.Write(easybreaker.Event{Type: easybreaker.EventStateChange, To: easybreaker.StateOpen})
+		assert.Error(t, w.Close())
+		assert.Equal(t, want, attempts, code)
+		srv.Close()
+	}
+
+	_, err = NewWebhook("")
+	assert.Error(t, err)
+	_, err = NewWebhook(srv.URL, WithTimeout(0))
+	assert.Error(t, err)
+	_, err = NewWebhook(srv.URL, WithRetries(-1, time.Second))
+	assert.Error(t, err)
+	_, err = NewWebhook(srv.URL, WithClient(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// SlidingWindow makes the strategies of the closed state decide on the
+// outcomes of the last interval, counted in buckets of interval/buckets,
+// rather than on the counts of the current interval zeroed on rollover,
+// so a burst of failures straddling two intervals still trips the breaker.
+// Counts and the events still report the counts of the current interval.
+// The window is local to the process, even with WithSharedMemory.
+func WithSlidingWindow(buckets int) OptionCall {
+	return func(b *Breaker) error {
+		if buckets <= 0 {
+			return errors.New("circuit: sliding window buckets must be positive")
+		}
+		width := b.interval / int64(buckets)
+		if width <= 0 {
+			return errors.New("circuit: sliding window buckets must be shorter than the interval")
+		}
+		if b.sliding != nil {
+			return errors.New("circuit: sliding and count windows are exclusive")
+		}
+		b.sliding = newBucketRing(buckets, width)
+		return nil
+	}
+}
+
+// CountWindow makes the strategies of the closed state decide on the
+// outcomes of the last size requests rather than on the counts of the
+// current interval, for the traffic too irregular for a time window.
+// A weighted request takes as many slots as its weight. Counts and the
+// events still report the counts of the current interval, the window is
+// local to the process, like WithSlidingWindow, which excludes it.
+func WithCountWindow(size int) OptionCall {
+	return func(b *Breaker) error {
+		if size <= 0 {
+			return errors.New("circuit: count window size must be positive")
+		}
+		if b.sliding != nil {
+			return errors.New("circuit: sliding and count windows are exclusive")
+		}
+		b.sliding = newCountRing(size)
+		return nil
+	}
+}
+
+// slide records n outcomes of requests in the window.
+func (b *Breaker) slide(n uint32, failed bool) {
+	if b.sliding == nil {
+		return
+	}
+	b.sliding.observe(b.now().UnixNano(), n, failed)
+}
+
+// sample returns the counts the strategies of the closed state decide on,
+// the ones of the window or of the current interval.
+func (b *Breaker) sample(now int64) (uint32, uint32) {
+	if b.sliding != nil {
+		return b.sliding.snapshot(now)
+	}
+	return unpackCounts(atomic.LoadUint64(&b.counts))
+}
@@ -0,0 +1,73 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_SlidingWindow(t *testing.T) {
+	failed := func() error { return errors.New("failed") }
+	toOpen := func(total uint32, failures uint32) bool { return failures >= 3 }
+
+	for _, sliding := range []bool{false, true} {
+		fns := []OptionCall{WithStateFunc(toOpen, defaultToClosed), withTime(1520100000)}
+		if sliding {
+			fns = append(fns, WithSlidingWindow(6))
+		}
+		b, err := New(time.Minute, time.Minute, fns...)
+		assert.NoError(t, err)
+
+		// an old failure slides out of the window
+		b.Execute(failed)
+		b.now = now(1520100050)
+		b.Execute(failed)
+		b.ExecuteWeighted(3, func() error { return nil })
+		b.now = now(1520100070)
+		b.Execute(failed)
+		assert.Equal(t, StateClosed, b.State())
+		assert.Equal(t, Counts{Total: 1, Failures: 1}, b.Counts())
+
+		// the burst straddles the rollover
+		b.now = now(1520100075)
+		b.Execute(failed)
+		if sliding {
+			assert.Equal(t, StateOpen, b.State())
+		} else {
+			assert.Equal(t, StateClosed, b.State())
+		}
+	}
+
+	_, err := New(time.Minute, time.Minute, WithSlidingWindow(0))
+	assert.Error(t, err)
+	_, err = New(time.Nanosecond, time.Minute, WithSlidingWindow(2))
+	assert.Error(t, err)
+}
+
+func TestBreaker_CountWindow(t *testing.T) {
+	failed := func() error { return errors.New("failed") }
+	toOpen := func(total uint32, failures uint32) bool { return total == 4 && failures >= 2 }
+	b, err := New(time.Minute, time.Minute, WithStateFunc(toOpen, defaultToClosed), WithCountWindow(4), withTime(1520100000))
+	assert.NoError(t, err)
+
+	// the first failure is replaced by the successes
+	b.Execute(failed)
+	b.ExecuteWeighted(3, func() error { return nil })
+	b.Execute(func() error { return nil })
+	assert.Equal(t, StateClosed, b.State())
+
+	// the window outlives the rollover
+	b.now = now(1520100070)
+	b.Execute(failed)
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, Counts{Total: 1, Failures: 1}, b.Counts())
+	b.Execute(failed)
+	assert.Equal(t, StateOpen, b.State())
+
+	_, err = New(time.Minute, time.Minute, WithCountWindow(0))
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithCountWindow(10), WithSlidingWindow(6))
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// StrategySlowCalls is the strategy of WithSlowCallThreshold,
+// the value is the ratio of the slow requests.
+const StrategySlowCalls = "slow-calls"
+
+type slowCall struct {
+	threshold time.Duration
+	ratio     float64
+}
+
+// SlowCallThreshold counts the requests of Execute taking threshold or more
+// as slow, failed or not, and opens the breaker once ratio of the requests
+// of the interval are slow, in (0, 1], protecting from the degraded but not
+// failing dependencies. The ratio is considered once atLeastReqs requests
+// are counted in the interval, see Counts.Slow.
+func WithSlowCallThreshold(threshold time.Duration, ratio float64) OptionCall {
+	return func(b *Breaker) error {
+		if threshold <= 0 {
+			return errors.New("circuit: slow call threshold must be positive")
+		}
+		if !(ratio > 0 && ratio <= 1) {
+			return errors.New("circuit: slow call ratio must be in (0, 1]")
+		}
+		b.slowCall = &slowCall{threshold: threshold, ratio: ratio}
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
+// onSlow trips the breaker if enough requests of the interval were slow.
+func (b *Breaker) onSlow() {
+	if atomic.LoadInt32(&b.state) != closed || b.override() != forcedNone {
+		return
+	}
+	total, _ := unpackCounts(atomic.LoadUint64(&b.counts))
+	if total == 0 || total < b.atLeastReqs {
+		return
+	}
+	ratio := float64(atomic.LoadUint32(&b.slow)) / float64(total)
+	if ratio >= b.slowCall.ratio {
+		b.trip(TripReason{Strategy: StrategySlowCalls, Threshold: b.slowCall.ratio, Value: ratio})
+	}
+}
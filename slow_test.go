@@ -0,0 +1,43 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_SlowCallThreshold(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithLeastReqs(4), WithStateFunc(func(total, failures uint32) bool { return failures > 1 }, defaultToClosed), WithSlowCallThreshold(2*time.Second, 0.5), withTime(1520100000))
+	assert.NoError(t, err)
+
+	ts := int64(1520100000)
+	call := func(d int64, err error) func() error {
+		return func() error {
+			ts += d
+			b.now = now(ts)
+			return err
+		}
+	}
+
+	// not enough requests yet
+	b.Execute(call(3, nil))
+	b.Execute(call(2, nil))
+	b.Execute(call(1, nil))
+	assert.Equal(t, Counts{Total: 3, Successes: 3, Slow: 2}, b.Counts())
+	assert.Equal(t, StateClosed, b.State())
+
+	// a slow failure counts
+	b.Execute(call(5, errors.New("failed")))
+	assert.Equal(t, StateOpen, b.State())
+	reason, _ := b.TripReason()
+	assert.Equal(t, StrategySlowCalls, reason.Strategy)
+	assert.Equal(t, 0.5, reason.Threshold)
+	assert.Equal(t, 0.75, reason.Value)
+
+	_, err = New(time.Minute, time.Minute, WithSlowCallThreshold(0, 0.5))
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithSlowCallThreshold(time.Second, 0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package easybreaker
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// spike compares the failure rate of the interval with the previous ones
+type spike struct {
+	factor  float64
+	minReqs uint32
+
+	mu     sync.Mutex
+	rates  []float64 // the failure rates of the previous intervals, a ring
+	totals []uint32
+	next   int
+
+	baseline uint64 // math.Float64bits of the average rate, 0 without history
+}
+
+// SpikeDetection opens the breaker once the failure rate of the interval
+// jumps to factor times the average rate of the previous intervals, catching
+// the regressions a static ratio tuned for the steady state misses.
+// The rate of the interval must be based on minReqs requests at least.
+// Without failures in the previous intervals, their rate is assumed to be
+// a single failure.
+func WithSpikeDetection(factor float64, intervals int, minReqs uint32) OptionCall {
+	return func(b *Breaker) error {
+		if factor <= 1 {
+			return errors.New("circuit: spike factor must be greater than 1")
+		}
+		if intervals <= 0 {
+			return errors.New("circuit: spike intervals must be positive")
+		}
+		b.spike = &spike{
+			factor:  factor,
+			minReqs: minReqs,
+			rates:   make([]float64, 0, intervals),
+			totals:  make([]uint32, 0, intervals),
+		}
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
+// observe records the counts of a finished interval of the closed state.
+func (s *spike) observe(counts Counts) {
+	if s == nil || counts.Total == 0 {
+		return
+	}
+
+	rate := float64(counts.Failures) / float64(counts.Total)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rates) < cap(s.rates) {
+		s.rates = append(s.rates, rate)
+		s.totals = append(s.totals, counts.Total)
+	} else {
+		s.rates[s.next] = rate
+		s.totals[s.next] = counts.Total
+	}
+	s.next = (s.next + 1) % cap(s.rates)
+
+	var sum float64
+	var total uint64
+	for i, r := range s.rates {
+		sum += r
+		total += uint64(s.totals[i])
+	}
+	baseline := sum / float64(len(s.rates))
+	if floor := 1 / float64(total); baseline < floor {
+		baseline = floor
+	}
+	atomic.StoreUint64(&s.baseline, math.Float64bits(baseline))
+}
+
+// exceeded reports whether the failure rate jumped,
+// along with the rate and the threshold.
+func (s *spike) exceeded(total uint32, failures uint32) (float64, float64, bool) {
+	if s == nil || total == 0 || total < s.minReqs {
+		return 0, 0, false
+	}
+
+	bits := atomic.LoadUint64(&s.baseline)
+	if bits == 0 {
+		return 0, 0, false
+	}
+	rate := float64(failures) / float64(total)
+	threshold := s.factor * math.Float64frombits(bits)
+	return rate, threshold, rate >= threshold
+}
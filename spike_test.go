@@ -0,0 +1,75 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_SpikeDetection(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithSpikeDetection(3, 2, 10),
+		WithStateFunc(func(uint32, uint32) bool { return false }, defaultToClosed),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	run := func(total, failures int) {
+		for i := 0; i < total; i++ {
+			if i < failures {
+				b.Execute(func() error { return errors.New("failed") })
+			} else {
+				b.Execute(func() error { return nil })
+			}
+		}
+	}
+
+	// no baseline yet
+	run(10, 9)
+	assert.Equal(t, StateClosed, b.State())
+
+	// the baseline is 0.9, the rate can't jump to 2.7
+	b.now = now(1520100061)
+	run(100, 10)
+	assert.Equal(t, StateClosed, b.State())
+
+	// the baseline is (0.1 + 0) / 2 after another two intervals
+	b.now = now(1520100122)
+	b.Execute(func() error { return nil })
+	b.spike.observe(Counts{Total: 100})
+
+	b.counts = packCounts(5, 4)
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateClosed, b.State()) // less than minReqs
+
+	b.counts = packCounts(18, 1)
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateClosed, b.State()) // 2 of 19 < 0.15
+
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State()) // 4 of 21 > 0.15
+}
+
+func TestSpike_Floor(t *testing.T) {
+	s := &spike{factor: 2, rates: make([]float64, 0, 1), totals: make([]uint32, 0, 1)}
+	s.observe(Counts{Total: 100})
+
+	// a single failure of 100 is assumed
+	_, _, ok := s.exceeded(100, 1)
+	assert.False(t, ok)
+	rate, threshold, ok := s.exceeded(100, 2)
+	assert.True(t, ok)
+	assert.Equal(t, 0.02, rate)
+	assert.Equal(t, 0.02, threshold)
+}
+
+func TestWithSpikeDetection(t *testing.T) {
+	_, err := New(time.Minute, time.Minute, WithSpikeDetection(1, 1, 0))
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithSpikeDetection(2, 0, 0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// StrategyStartup is the strategy of WithStartupProbe,
+// the error of the trip reason is the one of the probe.
+const StrategyStartup = "startup"
+
+type startupProbe struct {
+	fn      func(ctx context.Context) error
+	timeout time.Duration
+}
+
+// StartupProbe verifies the dependency once in New, the breaker starts open
+// for a cooldown if probe fails, then probes the dependency as usual,
+// rather than starting closed and absorbing a burst of failures.
+// The context of probe expires after timeout on the clock of the breaker,
+// New waits for probe.
+func WithStartupProbe(probe func(ctx context.Context) error, timeout time.Duration) OptionCall {
+	return func(b *Breaker) error {
+		if probe == nil {
+			return errors.New("circuit: startup probe must be defined")
+		}
+		if timeout <= 0 {
+			return errors.New("circuit: startup probe timeout must be positive")
+		}
+		b.startup = &startupProbe{fn: probe, timeout: timeout}
+		return nil
+	}
+}
+
+// probeStartup runs the startup probe, the breaker trips if it fails.
+func (b *Breaker) probeStartup() {
+	ctx, cancel := b.withTimeoutCtx(context.Background(), b.startup.timeout)
+	defer cancel()
+	if err := b.startup.fn(ctx); err != nil {
+		b.trip(TripReason{Strategy: StrategyStartup, Err: err})
+	}
+}
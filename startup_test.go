@@ -0,0 +1,74 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// manualClock is a Clock whose timers fire on demand
+type manualClock struct {
+	now    time.Time
+	timers chan chan time.Time
+}
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+func (c *manualClock) NewTimer(time.Duration) Timer {
+	ch := make(chan time.Time, 1)
+	c.timers <- ch
+	return manualTimer(ch)
+}
+
+type manualTimer chan time.Time
+
+func (t manualTimer) C() <-chan time.Time { return t }
+func (t manualTimer) Stop() bool          { return true }
+
+func TestBreaker_StartupProbe(t *testing.T) {
+	var deadline time.Time
+	b, err := New(time.Minute, time.Minute, WithStartupProbe(func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}, time.Second), withTime(1520100000))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1520100001, 0), deadline)
+	assert.Equal(t, StateClosed, b.State())
+
+	// the probe expires on the timer of the breaker clock
+	clock := &manualClock{now: time.Unix(1520100000, 0), timers: make(chan chan time.Time, 1)}
+	go func() {
+		timer := <-clock.timers
+		timer <- clock.now.Add(time.Second)
+	}()
+	b, err = New(time.Minute, time.Minute, WithClock(clock), WithStartupProbe(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, time.Hour))
+	assert.NoError(t, err)
+	reason, _ := b.TripReason()
+	assert.Equal(t, context.DeadlineExceeded, reason.Err)
+
+	down := errors.New("connection refused")
+	b, err = New(time.Minute, time.Minute, WithStartupProbe(func(ctx context.Context) error {
+		return down
+	}, time.Second), withTime(1520100000))
+	assert.NoError(t, err)
+	assert.Equal(t, StateOpen, b.State())
+	reason, _ = b.TripReason()
+	assert.Equal(t, StrategyStartup, reason.Strategy)
+	assert.Equal(t, down, reason.Err)
+
+	// the dependency is probed after the cooldown
+	b.now = now(1520100061)
+	assert.NoError(t, b.Execute(func() error { return nil }))
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	_, err = New(time.Minute, time.Minute, WithStartupProbe(nil, time.Second))
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithStartupProbe(func(ctx context.Context) error { return nil }, 0))
+	assert.Error(t, err)
+}
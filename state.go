@@ -0,0 +1,42 @@
+package easybreaker
+
+// State is the state of the circuit breaker.
+type State int32
+
+const (
+	StateClosed   = State(closed)
+	StateHalfOpen = State(halfOpen)
+	StateOpen     = State(open)
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the state as its name, e.g. in JSON.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Counts holds the numbers of requests of the current interval.
+type Counts struct {
+	Total     uint32 // requests in total
+	Failures  uint32 // requests returned an error
+	Successes uint32 // requests returned no error, counted apart from the failures
+	Timeouts  uint32 // failures which timed out, see IsTimeout
+	Ignored   uint32 // requests neither failed nor succeeded, removed from the total, see CategoryIgnored
+	Slow      uint32 // requests slower than the threshold of WithSlowCallThreshold, failed or not
+	InFlight  uint32 // requests accepted and not finished yet, whatever the interval
+
+	// the counts of the operation classes with a budget, by class,
+	// only reported by Breaker.Counts
+	Classes map[string]Counts `json:",omitempty"`
+}
@@ -0,0 +1,161 @@
+// Package strategy provides composable predicates on the counts of a breaker,
+// combined with And, Or and Not into the state functions of WithStateFunc
+// instead of the hand-written closures:
+//
+//	toOpen := strategy.And(strategy.MinVolume(20), strategy.Or(strategy.Ratio(0.5), strategy.Consecutive(5)))
+//	b, err := easybreaker.New(time.Minute, 10*time.Second, easybreaker.WithStateFunc(toOpen, strategy.Not(strategy.Ratio(0.1))))
+package strategy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+)
+
+// Ratio holds once the ratio of the failures reaches x.
+func Ratio(x float64) easybreaker.ToState {
+	return func(total, failures uint32) bool {
+		return total > 0 && float64(failures)/float64(total) >= x
+	}
+}
+
+// MinVolume holds once n requests at least are counted,
+// e.g. to ignore the ratios of a handful of requests.
+func MinVolume(n uint32) easybreaker.ToState {
+	return func(total, failures uint32) bool {
+		return total >= n
+	}
+}
+
+// Consecutive holds once the last n requests failed. The toOpen function
+// is called on every failure, the streak is broken whenever a success was
+// counted since the previous call, so the predicate must not be shared
+// between breakers.
+func Consecutive(n uint32) easybreaker.ToState {
+	var (
+		mu        sync.Mutex
+		successes uint32 // the successes of the previous call
+		streak    uint32
+	)
+	return func(total, failures uint32) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if s := total - failures; s != successes || failures < streak {
+			successes = s
+			streak = 0
+		}
+		if failures > 0 {
+			streak++
+		}
+		return streak >= n
+	}
+}
+
+// SlowRatio holds once the ratio of the requests slower than d reaches x,
+// at the precision of the buckets of the histogram. The latencies are
+// those returned by latency, typically Breaker.Latency bound once the
+// breaker is created, and counted since the counts of the breaker last
+// dropped, i.e. in the current window.
+func SlowRatio(latency func() (easybreaker.Histogram, bool), d time.Duration, x float64) easybreaker.ToState {
+	var (
+		mu       sync.Mutex
+		total    uint32
+		baseline easybreaker.Histogram
+	)
+	return func(t, failures uint32) bool {
+		h, ok := latency()
+		if !ok {
+			return false
+		}
+
+		mu.Lock()
+		if t < total {
+			baseline = h
+		}
+		total = t
+		base := baseline
+		mu.Unlock()
+
+		var slow, count uint64
+		for i := range h.Buckets {
+			n := h.Buckets[i] - base.Buckets[i]
+			count += n
+			if i > 0 && easybreaker.BucketBound(i-1) >= d {
+				slow += n
+			}
+		}
+		return count > 0 && float64(slow)/float64(count) >= x
+	}
+}
+
+// Declining is a toClosed function holding once the failure ratio of the
+// probes is below x and declined over the last n batches of probes, the
+// half-open states, rather than below the threshold once, so the partial
+// recoveries don't close the breaker prematurely. The predicate must not be
+// shared between breakers.
+func Declining(n int, x float64) easybreaker.ToState {
+	var (
+		mu     sync.Mutex
+		last   [2]uint32 // the counts of the last batch, called again concurrently
+		ratios []float64 // of the last n batches, the oldest first
+	)
+	return func(total, failures uint32) bool {
+		ratio := 0.0
+		if total > 0 {
+			ratio = float64(failures) / float64(total)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if c := [2]uint32{total, failures}; c != last || len(ratios) == 0 {
+			last = c
+			ratios = append(ratios, ratio)
+			if len(ratios) > n {
+				ratios = ratios[1:]
+			}
+		}
+		if ratio >= x || len(ratios) < n {
+			return false
+		}
+		for i := 1; i < len(ratios); i++ {
+			if ratios[i] >= ratios[i-1] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// And holds when all the predicates hold. All of them are called,
+// so the stateful ones like Consecutive see every call.
+func And(predicates ...easybreaker.ToState) easybreaker.ToState {
+	return func(total, failures uint32) bool {
+		ok := true
+		for _, p := range predicates {
+			ok = p(total, failures) && ok
+		}
+		return ok
+	}
+}
+
+// Or holds when any of the predicates holds. All of them are called,
+// so the stateful ones like Consecutive see every call.
+func Or(predicates ...easybreaker.ToState) easybreaker.ToState {
+	return func(total, failures uint32) bool {
+		ok := false
+		for _, p := range predicates {
+			ok = p(total, failures) || ok
+		}
+		return ok
+	}
+}
+
+// Not holds when the predicate doesn't.
+func Not(predicate easybreaker.ToState) easybreaker.ToState {
+	return func(total, failures uint32) bool {
+		return !predicate(total, failures)
+	}
+}
@@ -0,0 +1,84 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rfyiamcool/easybreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredicates(t *testing.T) {
+	assert.True(t, Ratio(0.5)(10, 5))
+	assert.False(t, Ratio(0.5)(10, 4))
+	assert.False(t, Ratio(0.5)(0, 0))
+	assert.True(t, MinVolume(10)(10, 0))
+	assert.False(t, MinVolume(10)(9, 9))
+
+	p := And(MinVolume(10), Or(Ratio(0.5), Not(Ratio(0.01))))
+	assert.True(t, p(10, 5))
+	assert.True(t, p(100, 0))
+	assert.False(t, p(100, 10))
+	assert.False(t, p(5, 5))
+}
+
+func TestConsecutive(t *testing.T) {
+	p := Consecutive(3)
+	assert.False(t, p(5, 1)) // 4 successes, a failure
+	assert.False(t, p(6, 2))
+	assert.True(t, p(7, 3))
+
+	// a success breaks the streak
+	assert.False(t, p(9, 4))
+	assert.False(t, p(10, 5))
+	assert.True(t, p(11, 6))
+
+	// counted through Or even if another predicate holds
+	p = Consecutive(2)
+	or := Or(Ratio(0.5), p)
+	assert.True(t, or(1, 1))
+	assert.True(t, p(2, 2))
+}
+
+func TestSlowRatio(t *testing.T) {
+	var b *easybreaker.Breaker
+	now := time.Unix(1520100000, 0)
+	b, err := easybreaker.New(
+		time.Minute, time.Minute,
+		easybreaker.WithLatencyHistogram(),
+		easybreaker.WithNow(func() time.Time { return now }),
+		easybreaker.WithStateFunc(
+			SlowRatio(func() (easybreaker.Histogram, bool) { return b.Latency() }, 100*time.Millisecond, 0.5),
+			Ratio(0),
+		),
+	)
+	assert.NoError(t, err)
+
+	slow := func(d time.Duration) func() error {
+		return func() error {
+			now = now.Add(d)
+			return errors.New("failed")
+		}
+	}
+	b.Execute(slow(time.Millisecond))
+	b.Execute(slow(time.Millisecond))
+	b.Execute(slow(time.Second))
+	assert.Equal(t, easybreaker.StateClosed, b.State())
+	b.Execute(slow(time.Second))
+	assert.Equal(t, easybreaker.StateOpen, b.State())
+}
+
+func TestDeclining(t *testing.T) {
+	p := Declining(3, 0.1)
+	assert.False(t, p(100, 30))
+	assert.False(t, p(100, 5)) // below the threshold, 2 batches only
+	assert.False(t, p(100, 8)) // rising again
+	assert.False(t, p(100, 8)) // the same batch asked twice
+	assert.False(t, p(100, 9))
+	assert.False(t, p(100, 7))
+	assert.True(t, p(100, 2))
+
+	p = Declining(1, 0.1)
+	assert.True(t, p(100, 0))
+}
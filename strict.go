@@ -0,0 +1,58 @@
+package easybreaker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotConfigured is wrapped by the errors of New in the strict mode
+// when a setting is left to its default.
+var ErrNotConfigured = errors.New("circuit: not configured")
+
+// Strict refuses the defaults, New fails unless a strategy opening the
+// breaker, a name and a classifier of the failures, WithTaxonomy or
+// WithErrorFilter, are set, for the organizations wanting every breaker
+// configured explicitly. The errors wrap ErrNotConfigured.
+func WithStrict() OptionCall {
+	return func(b *Breaker) error {
+		b.strict = true
+		return nil
+	}
+}
+
+// setting is a set of the settings required by WithStrict, every option
+// configuring one of them marks it, so the options added later are refused
+// until they declare what they configure.
+type setting uint8
+
+const (
+	settingStrategy   setting = 1 << iota // the options opening the breaker
+	settingName                           // WithName
+	settingClassifier                     // the options classifying the failures
+)
+
+// the settings required by WithStrict, in the order of the errors
+var required = []struct {
+	setting setting
+	name    string
+}{
+	{settingStrategy, "strategy"},
+	{settingName, "name"},
+	{settingClassifier, "classifier"},
+}
+
+// set marks the setting as set explicitly by an option.
+func (b *Breaker) set(s setting) {
+	b.explicit |= s
+}
+
+// unconfigured returns the errors of the settings left to their defaults.
+func (b *Breaker) unconfigured() []error {
+	var errs []error
+	for _, r := range required {
+		if b.explicit&r.setting == 0 {
+			errs = append(errs, fmt.Errorf("%w: %s must be set", ErrNotConfigured, r.name))
+		}
+	}
+	return errs
+}
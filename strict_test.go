@@ -0,0 +1,44 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_Strict(t *testing.T) {
+	_, err := New(time.Minute, time.Minute, WithStrict())
+	assert.True(t, errors.Is(err, ErrNotConfigured))
+	assert.Contains(t, err.Error(), "strategy must be set")
+	assert.Contains(t, err.Error(), "name must be set")
+	assert.Contains(t, err.Error(), "classifier must be set")
+
+	_, err = New(time.Minute, time.Minute, WithStrict(), WithName("api"), WithMaxFailures(10))
+	assert.True(t, errors.Is(err, ErrNotConfigured))
+	assert.Equal(t, "circuit: not configured: classifier must be set", err.Error())
+
+	// the options which don't open the breaker are not strategies
+	_, err = New(time.Minute, time.Minute, WithStrict(), WithName("api"), WithLeastReqs(10), WithTaxonomy(ContextErrors))
+	assert.Equal(t, "circuit: not configured: strategy must be set", err.Error())
+
+	for _, fns := range [][]OptionCall{
+		{WithSlowCallThreshold(time.Second, 0.5), WithErrorFilter(func(error) bool { return true })},
+		{WithHangDetection(10, time.Second), WithTaxonomy(ContextErrors)},
+		{WithFailureVelocity(10, time.Second), WithTaxonomy(ContextErrors)},
+	} {
+		_, err = New(time.Minute, time.Minute, append(fns, WithStrict(), WithName("api"))...)
+		assert.NoError(t, err)
+	}
+
+	b, err := New(
+		time.Minute, time.Minute,
+		WithStrict(),
+		WithName("api"),
+		WithFailureThreshold(0.5),
+		WithTaxonomy(ContextErrors),
+	)
+	assert.NoError(t, err)
+	assert.NotNil(t, b)
+}
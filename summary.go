@@ -0,0 +1,76 @@
+package easybreaker
+
+import (
+	"sort"
+	"time"
+)
+
+// Health is the overall verdict of a registry.
+type Health string
+
+const (
+	Healthy  Health = "healthy"  // every breaker is closed
+	Degraded Health = "degraded" // some breakers are open or half-open
+	Down     Health = "down"     // every breaker is open
+)
+
+// OpenBreaker is a breaker listed by Summary as not closed.
+type OpenBreaker struct {
+	Name     string
+	State    State
+	Since    time.Time     // the time the breaker left the closed state
+	Duration time.Duration // the time spent out of the closed state
+}
+
+// Summary is the aggregated health of the breakers of a registry.
+type Summary struct {
+	Total    int
+	Closed   int
+	HalfOpen int
+	Open     int
+
+	// the breakers which are not closed, the longest open first
+	Breakers []OpenBreaker
+	Health   Health
+}
+
+// Summary returns the aggregated health of the breakers for the status pages.
+func (r *Registry) Summary() Summary {
+	var s Summary
+	r.ForEach(func(name string, b *Breaker) bool {
+		s.Total++
+		state := b.State()
+		switch state {
+		case StateClosed:
+			s.Closed++
+			return true
+		case StateHalfOpen:
+			s.HalfOpen++
+		case StateOpen:
+			s.Open++
+		}
+
+		open := OpenBreaker{Name: name, State: state}
+		if since, ok := b.OpenSince(); ok {
+			open.Since = since
+			open.Duration = b.now().Sub(since)
+		}
+		s.Breakers = append(s.Breakers, open)
+		return true
+	})
+
+	// the names are sorted already
+	sort.SliceStable(s.Breakers, func(i, j int) bool {
+		return s.Breakers[i].Duration > s.Breakers[j].Duration
+	})
+
+	switch {
+	case s.Total > 0 && s.Open == s.Total:
+		s.Health = Down
+	case s.Closed < s.Total:
+		s.Health = Degraded
+	default:
+		s.Health = Healthy
+	}
+	return s
+}
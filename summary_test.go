@@ -0,0 +1,50 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Summary(t *testing.T) {
+	ts := time.Unix(1520100000, 0)
+	r := NewRegistry(time.Minute, 2*time.Minute, WithNow(func() time.Time { return ts }))
+	assert.Equal(t, Summary{Health: Healthy}, r.Summary())
+
+	api, _ := r.Get("api")
+	db, _ := r.Get("db")
+	r.Get("cache")
+	assert.Equal(t, Healthy, r.Summary().Health)
+
+	api.Execute(func() error { return errors.New("failed") })
+	ts = ts.Add(time.Minute)
+	db.ForceOpen()
+	ts = ts.Add(30 * time.Second)
+
+	s := r.Summary()
+	assert.Equal(t, 3, s.Total)
+	assert.Equal(t, 1, s.Closed)
+	assert.Equal(t, 2, s.Open)
+	assert.Equal(t, Degraded, s.Health)
+	assert.Equal(t, []OpenBreaker{
+		{Name: "api", State: StateOpen, Since: time.Unix(1520100000, 0), Duration: 90 * time.Second},
+		{Name: "db", State: StateOpen, Since: time.Unix(1520100060, 0), Duration: 30 * time.Second},
+	}, s.Breakers)
+
+	// the probing breaker is still counted since the trip
+	ts = ts.Add(time.Minute)
+	api.Allow()
+	s = r.Summary()
+	assert.Equal(t, 1, s.HalfOpen)
+	assert.Equal(t, 150*time.Second, s.Breakers[0].Duration)
+
+	r.Remove("cache")
+	api.ForceOpen()
+	assert.Equal(t, Down, r.Summary().Health)
+
+	db.Release()
+	_, ok := db.OpenSince()
+	assert.False(t, ok)
+}
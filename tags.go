@@ -0,0 +1,59 @@
+package easybreaker
+
+import (
+	"errors"
+)
+
+// Tags labels the breaker with the tags, e.g. "team": "payments", they are
+// carried by the events and reported by Debug. SetTag and DeleteTag change
+// them at runtime.
+func WithTags(tags map[string]string) OptionCall {
+	return func(b *Breaker) error {
+		copied := make(map[string]string, len(tags))
+		for k, v := range tags {
+			if k == "" {
+				return errors.New("circuit: tag must be named")
+			}
+			copied[k] = v
+		}
+		b.tags.Store(copied)
+		return nil
+	}
+}
+
+// Tags returns the tags of the breaker. The map is shared with the events
+// and must not be modified.
+func (b *Breaker) Tags() map[string]string {
+	tags, _ := b.tags.Load().(map[string]string)
+	return tags
+}
+
+// SetTag sets a tag of the breaker at runtime, e.g. marking it "critical"
+// during an incident, the following events carry it.
+func (b *Breaker) SetTag(key, value string) error {
+	if key == "" {
+		return errors.New("circuit: tag must be named")
+	}
+	b.updateTags(func(tags map[string]string) { tags[key] = value })
+	return nil
+}
+
+// DeleteTag removes a tag of the breaker at runtime.
+func (b *Breaker) DeleteTag(key string) {
+	b.updateTags(func(tags map[string]string) { delete(tags, key) })
+}
+
+// updateTags replaces the tags with a modified copy,
+// so the readers never see them change.
+func (b *Breaker) updateTags(fn func(tags map[string]string)) {
+	b.tagsMu.Lock()
+	defer b.tagsMu.Unlock()
+
+	current := b.Tags()
+	tags := make(map[string]string, len(current)+1)
+	for k, v := range current {
+		tags[k] = v
+	}
+	fn(tags)
+	b.tags.Store(tags)
+}
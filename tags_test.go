@@ -0,0 +1,52 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_Tags(t *testing.T) {
+	var events []Event
+	b, err := New(
+		time.Minute, time.Minute,
+		WithTags(map[string]string{"team": "payments"}),
+		WithSink(SeverityInfo, func(e Event) { events = append(events, e) }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments"}, b.Tags())
+
+	tags := b.Tags()
+	assert.NoError(t, b.SetTag("severity", "critical"))
+	assert.Error(t, b.SetTag("", "critical"))
+	assert.Equal(t, map[string]string{"team": "payments", "severity": "critical"}, b.Tags())
+	// the returned maps never change
+	assert.Equal(t, map[string]string{"team": "payments"}, tags)
+
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Len(t, events, 1)
+	assert.Equal(t, map[string]string{"team": "payments", "severity": "critical"}, events[0].Tags)
+	assert.Equal(t, map[string]string{"team": "payments", "severity": "critical"}, b.Debug().Tags)
+
+	b.DeleteTag("severity")
+	assert.Equal(t, map[string]string{"team": "payments"}, b.Tags())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			b.SetTag(string(rune('a'+i)), "x")
+			b.Tags()
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, b.Tags(), 5)
+
+	_, err = New(time.Minute, time.Minute, WithTags(map[string]string{"": "x"}))
+	assert.Error(t, err)
+}
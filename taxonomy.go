@@ -0,0 +1,228 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// Category is the class of a failure given by a Taxonomy.
+type Category string
+
+// the categories of the built-in taxonomies
+const (
+	CategoryTimeout  Category = "timeout"  // the deadline expired
+	CategoryCanceled Category = "canceled" // the caller gave up
+	CategoryNetwork  Category = "network"  // the dependency is unreachable
+	CategoryOverload Category = "overload" // the dependency sheds the load, e.g. 429
+	CategoryServer   Category = "server"   // the dependency failed, e.g. 5xx
+	CategoryClient   Category = "client"   // the request is invalid, e.g. 4xx
+	CategoryOther    Category = "other"    // the errors left unclassified
+
+	// the outcome is neutral, e.g. the client aborted, the request is
+	// neither a failure nor a success and is removed from the total,
+	// so the ratios of the strategies ignore it
+	CategoryIgnored Category = "ignored"
+)
+
+// ErrIgnored marks the outcome of a request as neutral, like CategoryIgnored,
+// whatever the taxonomy and the error filter: the errors wrapping it are
+// neither failures nor successes, e.g. the requests aborted by the caller.
+var ErrIgnored = errors.New("circuit: ignored")
+
+// Taxonomy classifies the errors of the requests, it reports false
+// for the errors it doesn't know about.
+type Taxonomy interface {
+	Classify(err error) (Category, bool)
+}
+
+// TaxonomyFunc is a Taxonomy function.
+type TaxonomyFunc func(err error) (Category, bool)
+
+func (f TaxonomyFunc) Classify(err error) (Category, bool) {
+	return f(err)
+}
+
+// Chain returns the taxonomy asking the taxonomies in order,
+// the first one knowing the error classifies it.
+func Chain(taxonomies ...Taxonomy) Taxonomy {
+	return TaxonomyFunc(func(err error) (Category, bool) {
+		for _, t := range taxonomies {
+			if category, ok := t.Classify(err); ok {
+				return category, true
+			}
+		}
+		return "", false
+	})
+}
+
+// ContextErrors classifies context.DeadlineExceeded and context.Canceled.
+var ContextErrors Taxonomy = TaxonomyFunc(func(err error) (Category, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CategoryTimeout, true
+	case errors.Is(err, context.Canceled):
+		return CategoryCanceled, true
+	}
+	return "", false
+})
+
+// ClientAborts ignores context.Canceled, the caller giving up
+// says nothing about the health of the dependency.
+var ClientAborts Taxonomy = TaxonomyFunc(func(err error) (Category, bool) {
+	if errors.Is(err, context.Canceled) {
+		return CategoryIgnored, true
+	}
+	return "", false
+})
+
+// NetErrors classifies the net.Error, the timeouts or the network failures.
+var NetErrors Taxonomy = TaxonomyFunc(func(err error) (Category, bool) {
+	var ne net.Error
+	if !errors.As(err, &ne) {
+		return "", false
+	}
+	if ne.Timeout() {
+		return CategoryTimeout, true
+	}
+	return CategoryNetwork, true
+})
+
+// HTTPStatus classifies the errors by the HTTP status code returned by code,
+// 0 if the error has none, e.g. extracted from the error type of the client.
+func HTTPStatus(code func(err error) int) Taxonomy {
+	return TaxonomyFunc(func(err error) (Category, bool) {
+		status := code(err)
+		switch {
+		case status == 408 || status == 504:
+			return CategoryTimeout, true
+		case status == 429:
+			return CategoryOverload, true
+		case status >= 500 && status < 600:
+			return CategoryServer, true
+		case status >= 400 && status < 500:
+			return CategoryClient, true
+		}
+		return "", false
+	})
+}
+
+// GRPCCodes classifies the errors by the gRPC status code returned by code,
+// which is typically
+//
+//	func(err error) uint32 { return uint32(status.Code(err)) }
+func GRPCCodes(code func(err error) uint32) Taxonomy {
+	return TaxonomyFunc(func(err error) (Category, bool) {
+		switch code(err) {
+		case 1: // Canceled
+			return CategoryCanceled, true
+		case 4: // DeadlineExceeded
+			return CategoryTimeout, true
+		case 8: // ResourceExhausted
+			return CategoryOverload, true
+		case 14: // Unavailable
+			return CategoryNetwork, true
+		case 2, 10, 12, 13, 15: // Unknown, Aborted, Unimplemented, Internal, DataLoss
+			return CategoryServer, true
+		case 3, 5, 6, 7, 9, 11, 16: // InvalidArgument, NotFound, AlreadyExists, PermissionDenied, FailedPrecondition, OutOfRange, Unauthenticated
+			return CategoryClient, true
+		}
+		return "", false
+	})
+}
+
+// Taxonomy classifies the failures of the breaker, the timeouts are the
+// failures of CategoryTimeout, the category of the failure tripping the breaker
+// is set in its TripReason and the failures are counted by category in Breakdown.
+// By default the timeouts are the errors of IsTimeout and nothing else is classified.
+func WithTaxonomy(t Taxonomy) OptionCall {
+	return func(b *Breaker) error {
+		if t == nil {
+			return errors.New("circuit: taxonomy must be defined")
+		}
+		b.taxonomy = t
+		b.set(settingClassifier)
+		b.breakdown = &sync.Map{}
+		return nil
+	}
+}
+
+// ErrorFilter decides whether the error of a request counts as a failure,
+// the errors it refuses count as successes since the dependency answered,
+// e.g. the validation errors or the 4xx of the clients. All the errors count
+// by default, see CategoryIgnored for the neutral outcomes.
+func WithErrorFilter(counts func(err error) bool) OptionCall {
+	return func(b *Breaker) error {
+		if counts == nil {
+			return errors.New("circuit: error filter must be defined")
+		}
+		b.errorFilter = counts
+		b.set(settingClassifier)
+		return nil
+	}
+}
+
+// failed reports whether the error of a request counts as a failure.
+func (b *Breaker) failed(err error) bool {
+	return err != nil && (b.errorFilter == nil || b.errorFilter(err) || errors.Is(err, ErrIgnored))
+}
+
+// classify returns the category of a failure, it's counted in the breakdown
+// unless it's ignored.
+func (b *Breaker) classify(err error) Category {
+	if errors.Is(err, ErrIgnored) {
+		return CategoryIgnored
+	}
+	if b.taxonomy == nil {
+		if IsTimeout(err) {
+			return CategoryTimeout
+		}
+		return CategoryOther
+	}
+
+	category, ok := b.taxonomy.Classify(err)
+	if !ok {
+		category = CategoryOther
+	}
+	if category == CategoryIgnored {
+		return category
+	}
+	n, ok := b.breakdown.Load(category)
+	if !ok {
+		n, _ = b.breakdown.LoadOrStore(category, new(uint64))
+	}
+	atomic.AddUint64(n.(*uint64), 1)
+	return category
+}
+
+// Breakdown returns the number of the failures by category since the breaker
+// was created, nil without WithTaxonomy.
+func (b *Breaker) Breakdown() map[Category]uint64 {
+	if b.breakdown == nil {
+		return nil
+	}
+	breakdown := make(map[Category]uint64)
+	b.breakdown.Range(func(category, n interface{}) bool {
+		breakdown[category.(Category)] = atomic.LoadUint64(n.(*uint64))
+		return true
+	})
+	return breakdown
+}
+
+// ignores reports whether the error of a request is ignored,
+// without counting it in the breakdown.
+func (b *Breaker) ignores(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrIgnored) {
+		return true
+	}
+	if b.taxonomy == nil {
+		return false
+	}
+	category, ok := b.taxonomy.Classify(err)
+	return ok && category == CategoryIgnored
+}
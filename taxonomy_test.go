@@ -0,0 +1,147 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusError struct {
+	code int
+}
+
+func (e statusError) Error() string { return "status" }
+
+func TestTaxonomies(t *testing.T) {
+	httpStatus := HTTPStatus(func(err error) int {
+		var se statusError
+		if errors.As(err, &se) {
+			return se.code
+		}
+		return 0
+	})
+	grpcCodes := GRPCCodes(func(err error) uint32 {
+		var se statusError
+		if errors.As(err, &se) {
+			return uint32(se.code)
+		}
+		return 2
+	})
+	taxonomy := Chain(ContextErrors, NetErrors, httpStatus)
+
+	for _, c := range []struct {
+		taxonomy Taxonomy
+		err      error
+		category Category
+		ok       bool
+	}{
+		{taxonomy, context.DeadlineExceeded, CategoryTimeout, true},
+		{taxonomy, context.Canceled, CategoryCanceled, true},
+		{taxonomy, &net.DNSError{IsTimeout: true}, CategoryTimeout, true},
+		{taxonomy, &net.OpError{Op: "dial", Err: errors.New("refused")}, CategoryNetwork, true},
+		{taxonomy, statusError{503}, CategoryServer, true},
+		{taxonomy, statusError{504}, CategoryTimeout, true},
+		{taxonomy, statusError{429}, CategoryOverload, true},
+		{taxonomy, statusError{404}, CategoryClient, true},
+		{taxonomy, errors.New("failed"), "", false},
+		{grpcCodes, statusError{14}, CategoryNetwork, true},
+		{grpcCodes, statusError{3}, CategoryClient, true},
+		{grpcCodes, errors.New("failed"), CategoryServer, true},
+		{grpcCodes, statusError{0}, "", false},
+	} {
+		category, ok := c.taxonomy.Classify(c.err)
+		assert.Equal(t, c.category, category, c.err.Error())
+		assert.Equal(t, c.ok, ok, c.err.Error())
+	}
+}
+
+func TestBreaker_Taxonomy(t *testing.T) {
+	b, err := New(
+		time.Minute, time.Minute,
+		WithStateFunc(func(total, failures uint32) bool { return failures >= 3 }, defaultToClosed),
+		WithTaxonomy(Chain(ContextErrors, HTTPStatus(func(err error) int {
+			if se, ok := err.(statusError); ok {
+				return se.code
+			}
+			return 0
+		}))),
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, (&Breaker{}).Breakdown())
+
+	b.Execute(func() error { return statusError{504} })
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return statusError{500} })
+
+	assert.Equal(t, map[Category]uint64{CategoryTimeout: 1, CategoryOther: 1, CategoryServer: 1}, b.Breakdown())
+	reason, _ := b.TripReason()
+	assert.Equal(t, CategoryServer, reason.Category)
+	assert.Equal(t, uint32(3), reason.Counts.Failures)
+	w, _ := b.LastWindow()
+	assert.Equal(t, uint32(1), w.Counts.Timeouts)
+
+	_, err = New(time.Minute, time.Minute, WithTaxonomy(nil))
+	assert.Error(t, err)
+}
+
+func TestBreaker_Ignored(t *testing.T) {
+	b, err := New(
+		time.Minute, time.Minute,
+		WithLeastReqs(2),
+		WithFailureThreshold(0.5),
+		WithClassBudget(ClassWrite, func(total, failures uint32) bool { return failures > 0 }),
+		WithTaxonomy(Chain(ClientAborts, ContextErrors)),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return nil })
+	b.ExecuteWeighted(3, func() error { return fmt.Errorf("aborted: %w", context.Canceled) })
+	b.ExecuteClass(ClassWrite, func() error { return context.Canceled })
+
+	// neither a success nor a failure, the ratio is not diluted
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, Counts{Total: 1, Successes: 1, Ignored: 4, Classes: map[string]Counts{ClassWrite: {}}}, b.Counts())
+	assert.Equal(t, StateClosed, b.ClassState(ClassWrite))
+	assert.Empty(t, b.Breakdown())
+
+	// ErrIgnored is neutral whatever the taxonomy
+	b.Execute(func() error { return fmt.Errorf("canceled: %w", ErrIgnored) })
+	assert.Equal(t, uint32(5), b.Counts().Ignored)
+
+	b.Execute(func() error { return context.DeadlineExceeded })
+	assert.Equal(t, StateOpen, b.State())
+	w, _ := b.LastWindow()
+	assert.Equal(t, Counts{Total: 2, Failures: 1, Successes: 1, Timeouts: 1, Ignored: 5}, w.Counts)
+}
+
+func TestBreaker_ErrorFilter(t *testing.T) {
+	invalid := errors.New("invalid")
+	b, err := New(
+		time.Minute, time.Minute,
+		WithMaxFailures(1),
+		WithStateFunc(func(uint32, uint32) bool { return false }, defaultToClosed),
+		WithClassBudget("write", func(total, failures uint32) bool { return failures > 0 }),
+		WithErrorFilter(func(err error) bool { return !errors.Is(err, invalid) && !errors.Is(err, context.Canceled) }),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, invalid, b.Execute(func() error { return invalid }))
+	assert.Equal(t, context.Canceled, b.ExecuteWeighted(2, func() error { return context.Canceled }))
+	assert.Equal(t, invalid, b.ExecuteClass("write", func() error { return invalid }))
+	assert.Equal(t, StateClosed, b.ClassState("write"))
+	assert.Equal(t, Counts{Total: 4, Successes: 4}, b.current())
+	b.Execute(func() error { return ErrIgnored })
+	assert.Equal(t, Counts{Total: 4, Successes: 4, Ignored: 1}, b.current())
+
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+
+	_, err = New(time.Minute, time.Minute, WithErrorFilter(nil))
+	assert.Error(t, err)
+}
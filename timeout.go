@@ -0,0 +1,36 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+)
+
+// StrategyTimeouts is the strategy of WithTimeoutFunc,
+// the value is the ratio of the timeouts.
+const StrategyTimeouts = "timeouts"
+
+// TimeoutFunc is called whenever a request fails in the closed state with
+// the counts of the interval, whose Timeouts are the failures which timed out.
+// If it returns true, the circuit breaker will be placed into the open state,
+// e.g. on a lower ratio of timeouts than of explicit errors.
+func WithTimeoutFunc(toOpen func(counts Counts) bool) OptionCall {
+	return func(b *Breaker) error {
+		if toOpen == nil {
+			return errors.New("circuit: timeout func must be defined")
+		}
+		b.timeoutToOpen = toOpen
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
+// IsTimeout reports whether the error of a request is a timeout,
+// context.DeadlineExceeded or an error with a Timeout method returning true,
+// e.g. a net.Error.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var t interface{ Timeout() bool }
+	return errors.As(err, &t) && t.Timeout()
+}
@@ -0,0 +1,48 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTimeout(t *testing.T) {
+	assert.True(t, IsTimeout(context.DeadlineExceeded))
+	assert.True(t, IsTimeout(fmt.Errorf("get: %w", context.DeadlineExceeded)))
+	assert.True(t, IsTimeout(&net.DNSError{IsTimeout: true}))
+	assert.False(t, IsTimeout(&net.DNSError{}))
+	assert.False(t, IsTimeout(errors.New("failed")))
+	assert.False(t, IsTimeout(nil))
+}
+
+func TestBreaker_TimeoutFunc(t *testing.T) {
+	b, err := New(
+		time.Minute, time.Minute,
+		WithStateFunc(func(total, failures uint32) bool { return failures >= 4 }, defaultToClosed),
+		WithTimeoutFunc(func(counts Counts) bool { return counts.Timeouts >= 2 }),
+	)
+	assert.NoError(t, err)
+
+	b.Execute(func() error { return errors.New("failed") })
+	b.Execute(func() error { return context.DeadlineExceeded })
+	assert.Equal(t, Counts{Total: 2, Failures: 2, Timeouts: 1}, b.Counts())
+	assert.Equal(t, StateClosed, b.State())
+
+	b.Execute(func() error { return context.DeadlineExceeded })
+	assert.Equal(t, StateOpen, b.State())
+	reason, _ := b.TripReason()
+	assert.Equal(t, StrategyTimeouts, reason.Strategy)
+	assert.InDelta(t, 0.66, reason.Value, 0.01)
+
+	w, _ := b.LastWindow()
+	assert.Equal(t, Counts{Total: 3, Failures: 3, Timeouts: 2}, w.Counts)
+	assert.Equal(t, Counts{}, b.Counts())
+
+	_, err = New(time.Minute, time.Minute, WithTimeoutFunc(nil))
+	assert.Error(t, err)
+}
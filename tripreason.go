@@ -0,0 +1,95 @@
+package easybreaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// the strategies of TripReason
+const (
+	StrategyMaxFailures = "max-failures" // WithMaxFailures, the value is the failures of the interval
+	StrategyStateFunc   = "state-func"   // the toOpen function, the value is the failure ratio
+	StrategyVelocity    = "velocity"     // WithFailureVelocity, the value is the failures per second
+	StrategySpike       = "spike"        // WithSpikeDetection, the value is the failure ratio
+	StrategyLoad        = "load"         // WithLoadFunc, the value is the observed load
+	StrategyProbes      = "probes"       // the toClosed function failed the probes, the value is the failure ratio
+	StrategyGroup       = "group"        // Group.TripAllAbove, the value is the ratio of the open shards
+	StrategyForced      = "forced"       // ForceOpen
+	StrategyManual      = "manual"       // Trip
+)
+
+// TripReason is the condition which opened the breaker.
+type TripReason struct {
+	Strategy  string
+	Threshold float64 // the limit of the strategy if any
+	Value     float64 // the observed value, see the strategies
+	Counts    Counts  // the counts when the breaker opened
+	Err       error   // the error of the failed request which tripped the breaker, if any
+	Category  Category
+	Time      time.Time
+}
+
+func (r TripReason) String() string {
+	s := fmt.Sprintf("%s value=%g", r.Strategy, r.Value)
+	if r.Threshold != 0 {
+		s += fmt.Sprintf(" threshold=%g", r.Threshold)
+	}
+	s += fmt.Sprintf(" total=%d failures=%d", r.Counts.Total, r.Counts.Failures)
+	if r.Err != nil {
+		s += fmt.Sprintf(" err=%q", r.Err.Error())
+	}
+	return s
+}
+
+// OpenError is the error of the rejected requests with WithOpenError,
+// errors.Is(err, ErrBreakerOpen) holds.
+type OpenError struct {
+	Name   string
+	Reason TripReason
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit: breaker %q open: %s", e.Name, e.Reason)
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrBreakerOpen
+}
+
+// OpenError makes the rejected requests return an *OpenError carrying
+// the trip reason rather than ErrBreakerOpen itself.
+func WithOpenError() OptionCall {
+	return func(b *Breaker) error {
+		b.openError = true
+		return nil
+	}
+}
+
+// TripReason returns why the breaker opened the last time,
+// false if it never did.
+func (b *Breaker) TripReason() (TripReason, bool) {
+	reason, ok := b.tripReason.Load().(TripReason)
+	return reason, ok
+}
+
+func (b *Breaker) setTripReason(reason TripReason, total uint32, failures uint32) {
+	reason.Counts = Counts{Total: total, Failures: failures}
+	reason.Time = b.now()
+	b.tripReason.Store(reason)
+}
+
+// errOpen returns the error of a rejected request.
+func (b *Breaker) errOpen() error {
+	if !b.openError {
+		return ErrBreakerOpen
+	}
+	reason, _ := b.TripReason()
+	return &OpenError{Name: b.name, Reason: reason}
+}
+
+func failureRatio(total uint32, failures uint32) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(failures) / float64(total)
+}
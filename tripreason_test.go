@@ -0,0 +1,62 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_TripReason(t *testing.T) {
+	var events []Event
+	b, err := New(
+		time.Minute, time.Minute,
+		WithName("api"),
+		WithMaxFailures(1),
+		WithStateFunc(func(total, failures uint32) bool { return false }, func(total, failures uint32) bool { return true }),
+		WithOpenError(),
+		WithSink(SeverityWarn, func(e Event) { events = append(events, e) }),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	_, ok := b.TripReason()
+	assert.False(t, ok)
+
+	failed := errors.New("failed")
+	b.Execute(func() error { return failed })
+	b.Execute(func() error { return failed })
+
+	reason, ok := b.TripReason()
+	assert.True(t, ok)
+	assert.Equal(t, TripReason{
+		Strategy:  StrategyMaxFailures,
+		Threshold: 1,
+		Value:     2,
+		Counts:    Counts{Total: 2, Failures: 2},
+		Err:       failed,
+		Category:  CategoryOther,
+		Time:      time.Unix(1520100000, 0),
+	}, reason)
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, &reason, events[0].Reason)
+
+	err = b.Execute(func() error { return nil })
+	assert.True(t, errors.Is(err, ErrBreakerOpen))
+	assert.Equal(t, &OpenError{Name: "api", Reason: reason}, err)
+	assert.Equal(t, `circuit: breaker "api" open: max-failures value=2 threshold=1 total=2 failures=2 err="failed"`, err.Error())
+}
+
+func TestBreaker_TripReasonForced(t *testing.T) {
+	b, err := New(time.Minute, time.Minute)
+	assert.NoError(t, err)
+
+	b.ForceOpen()
+	assert.Equal(t, ErrBreakerOpen, b.Allow())
+
+	reason, ok := b.TripReason()
+	assert.True(t, ok)
+	assert.Equal(t, StrategyForced, reason.Strategy)
+}
@@ -0,0 +1,80 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const velocityBuckets = 10
+
+// velocity counts the failures of the last window in buckets
+type velocity struct {
+	limit  float64 // failures per second
+	window int64
+	width  int64 // the width of a bucket
+
+	mu      sync.Mutex
+	starts  [velocityBuckets]int64
+	buckets [velocityBuckets]uint32
+}
+
+// FailureVelocity opens the breaker once the failures per second over the last
+// window exceed the limit, catching the sharp error spikes faster than a ratio
+// over the whole interval can. The window is split into 10 buckets.
+func WithFailureVelocity(perSecond float64, window time.Duration) OptionCall {
+	return func(b *Breaker) error {
+		if perSecond <= 0 {
+			return errors.New("circuit: failure velocity must be positive")
+		}
+		if window < velocityBuckets {
+			return errors.New("circuit: failure velocity window too short")
+		}
+		b.velocity = &velocity{
+			limit:  perSecond,
+			window: window.Nanoseconds(),
+			width:  window.Nanoseconds() / velocityBuckets,
+		}
+		b.set(settingStrategy)
+		return nil
+	}
+}
+
+// exceeded records a failure at now and reports whether the limit is exceeded,
+// along with the failures per second.
+func (v *velocity) exceeded(now int64) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	start := now - now%v.width
+	i := (now / v.width) % velocityBuckets
+	if v.starts[i] != start {
+		v.starts[i] = start
+		v.buckets[i] = 0
+	}
+	v.buckets[i]++
+
+	var failures uint32
+	for i, bucketStart := range v.starts {
+		if bucketStart > now-v.window {
+			failures += v.buckets[i]
+		}
+	}
+	rate := float64(failures) / time.Duration(v.window).Seconds()
+	return rate, rate > v.limit
+}
+
+func (v *velocity) reset() {
+	if v == nil {
+		return
+	}
+
+	v.mu.Lock()
+	v.starts = [velocityBuckets]int64{}
+	v.buckets = [velocityBuckets]uint32{}
+	v.mu.Unlock()
+}
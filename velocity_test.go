@@ -0,0 +1,55 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_FailureVelocity(t *testing.T) {
+	ts := time.Unix(1520100000, 0)
+	b, err := New(
+		time.Hour, time.Minute,
+		WithLeastReqs(1),
+		WithFailureVelocity(2, time.Second),
+		WithStateFunc(func(uint32, uint32) bool { return false }, defaultToClosed),
+		WithNow(func() time.Time { return ts }),
+	)
+	assert.NoError(t, err)
+
+	fail := func() error { return errors.New("failed") }
+
+	// 2 failures per second don't exceed the limit
+	for i := 0; i < 10; i++ {
+		b.Execute(fail)
+		ts = ts.Add(500 * time.Millisecond)
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	// a spike of 3 failures within a second
+	ts = ts.Add(time.Second)
+	b.Execute(fail)
+	ts = ts.Add(100 * time.Millisecond)
+	b.Execute(fail)
+	assert.Equal(t, StateClosed, b.State())
+	b.Execute(fail)
+	assert.Equal(t, StateOpen, b.State())
+
+	// the failures before the recovery are forgotten
+	ts = ts.Add(time.Minute)
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+	assert.Equal(t, StateClosed, b.State())
+	b.Execute(fail)
+	b.Execute(fail)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestWithFailureVelocity(t *testing.T) {
+	_, err := New(time.Minute, time.Minute, WithFailureVelocity(0, time.Second))
+	assert.Error(t, err)
+	_, err = New(time.Minute, time.Minute, WithFailureVelocity(1, 0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package easybreaker
+
+import "time"
+
+// View is a read-only view of a breaker for the exporters and the dashboards,
+// the state, the counts and the events without the control methods, so the
+// monitoring code can't trip, reset or override the breaker by accident.
+type View struct {
+	b *Breaker
+}
+
+// View returns the read-only view of the breaker.
+func (b *Breaker) View() View {
+	return View{b: b}
+}
+
+// Name returns the name of the breaker.
+func (v View) Name() string {
+	return v.b.name
+}
+
+// State returns the current state of the breaker, see Breaker.State.
+func (v View) State() State {
+	return v.b.State()
+}
+
+// Counts returns the counts of the current interval, see Breaker.Counts.
+func (v View) Counts() Counts {
+	return v.b.Counts()
+}
+
+// Trips returns the number of times the breaker tripped from the closed state.
+func (v View) Trips() uint64 {
+	return v.b.Trips()
+}
+
+// Tags returns the tags of the breaker, the map must not be modified.
+func (v View) Tags() map[string]string {
+	return v.b.Tags()
+}
+
+// TripReason returns why the breaker opened the last time, see Breaker.TripReason.
+func (v View) TripReason() (TripReason, bool) {
+	return v.b.TripReason()
+}
+
+// OpenSince returns the time the breaker left the closed state, see Breaker.OpenSince.
+func (v View) OpenSince() (time.Time, bool) {
+	return v.b.OpenSince()
+}
+
+// LastWindow returns the last finished window, see Breaker.LastWindow.
+func (v View) LastWindow() (Window, bool) {
+	return v.b.LastWindow()
+}
+
+// History returns the audit trail of the manual control actions.
+func (v View) History() []Action {
+	return v.b.History()
+}
+
+// Subscribe attaches fn to the events, see Breaker.Subscribe.
+func (v View) Subscribe(severity Severity, fn func(Event)) (func(), error) {
+	return v.b.Subscribe(severity, fn)
+}
+
+func (v View) String() string {
+	return v.b.String()
+}
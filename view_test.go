@@ -0,0 +1,36 @@
+package easybreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_View(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithName("api"), WithTags(map[string]string{"team": "payments"}), WithLeastReqs(1))
+	assert.NoError(t, err)
+	v := b.View()
+
+	var events []Event
+	unsubscribe, err := v.Subscribe(SeverityWarn, func(e Event) { events = append(events, e) })
+	assert.NoError(t, err)
+	defer unsubscribe()
+
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, "api", v.Name())
+	assert.Equal(t, StateOpen, v.State())
+	assert.Equal(t, Counts{}, v.Counts())
+	assert.Equal(t, uint64(1), v.Trips())
+	assert.Equal(t, map[string]string{"team": "payments"}, v.Tags())
+	_, ok := v.TripReason()
+	assert.True(t, ok)
+	_, ok = v.OpenSince()
+	assert.True(t, ok)
+	_, ok = v.LastWindow()
+	assert.True(t, ok)
+	assert.Empty(t, v.History())
+	assert.Equal(t, b.String(), v.String())
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,104 @@
+package easybreaker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Definition is the configuration of a breaker of a manifest, its settings
+// are layered like the ones of Configure.
+type Definition struct {
+	Name     string
+	Interval time.Duration
+	Cooldown time.Duration
+	Options  []OptionCall
+}
+
+// Warm creates the breakers of the manifest at the startup instead of on
+// the first use, so the exporters and the dashboards show all the expected
+// breakers immediately and the configuration mistakes fail the boot.
+//
+// The manifest is applied atomically, the existing breakers are replaced
+// like with Configure and nothing is changed if any definition is invalid.
+// The returned error joins the errors of all the invalid definitions.
+func (r *Registry) Warm(manifest ...Definition) error {
+	_, errs := r.apply(manifest)
+	return errors.Join(errs...)
+}
+
+// apply creates or replaces the breakers of the definitions atomically,
+// it returns whether each breaker existed and the error of each definition,
+// nothing is changed if any is not nil.
+func (r *Registry) apply(manifest []Definition) (existed []bool, errs []error) {
+	errs = make([]error, len(manifest))
+	failed := false
+	seen := make(map[string]bool, len(manifest))
+	for i, def := range manifest {
+		if err := validName(def.Name); err != nil {
+			errs[i], failed = err, true
+			continue
+		}
+		if seen[def.Name] {
+			errs[i], failed = fmt.Errorf("circuit: breaker %q defined twice", def.Name), true
+			continue
+		}
+		seen[def.Name] = true
+	}
+	if failed {
+		return nil, errs
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := make(map[string]layer, len(manifest))
+	for _, def := range manifest {
+		if own, ok := r.names[def.Name]; ok {
+			previous[def.Name] = own
+		}
+		r.names[def.Name] = layer{interval: def.Interval, cooldown: def.Cooldown, fns: def.Options}
+	}
+
+	created := make([]*Breaker, len(manifest))
+	for i, def := range manifest {
+		b, err := r.create(def.Name)
+		if err != nil {
+			errs[i], failed = fmt.Errorf("circuit: breaker %q: %w", def.Name, err), true
+			continue
+		}
+		created[i] = b
+	}
+
+	if failed {
+		for _, def := range manifest {
+			if own, ok := previous[def.Name]; ok {
+				r.names[def.Name] = own
+			} else {
+				delete(r.names, def.Name)
+			}
+		}
+		return nil, errs
+	}
+
+	existed = make([]bool, len(manifest))
+	for i, def := range manifest {
+		_, existed[i] = r.Lookup(def.Name)
+		r.store(def.Name, created[i])
+	}
+	return existed, nil
+}
+
+// validName checks a hierarchical name, without empty segments.
+func validName(name string) error {
+	if name == "" {
+		return errors.New("circuit: breaker must be named")
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" || strings.TrimSpace(segment) != segment {
+			return fmt.Errorf("circuit: invalid breaker name %q", name)
+		}
+	}
+	return nil
+}
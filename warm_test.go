@@ -0,0 +1,53 @@
+package easybreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Warm(t *testing.T) {
+	r := NewRegistry(time.Minute, 10*time.Second)
+	r.Namespace("payments", 0, 30*time.Second, WithLeastReqs(20))
+
+	err := r.Warm(
+		Definition{Name: "payments/stripe", Interval: time.Minute, Cooldown: time.Minute},
+		Definition{Name: "search", Interval: time.Second, Cooldown: time.Second, Options: []OptionCall{WithLeastReqs(5)}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"payments/stripe", "search"}, r.Names())
+
+	b, ok := r.Lookup("payments/stripe")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(20), b.atLeastReqs)
+	search, _ := r.Lookup("search")
+	assert.Equal(t, uint32(5), search.atLeastReqs)
+
+	// the mistakes are all reported and nothing is changed
+	err = r.Warm(
+		Definition{Name: "search", Interval: time.Second, Cooldown: time.Second, Options: []OptionCall{WithLeastReqs(50)}},
+		Definition{Name: "payments//stripe", Interval: time.Second, Cooldown: time.Second},
+		Definition{Name: "orders", Cooldown: time.Second},
+		Definition{Name: "search", Interval: time.Second, Cooldown: time.Second},
+	)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `"payments//stripe"`)
+	assert.Contains(t, err.Error(), `"search" defined twice`)
+
+	err = r.Warm(
+		Definition{Name: "search", Interval: time.Second, Cooldown: time.Second, Options: []OptionCall{WithLeastReqs(50)}},
+		Definition{Name: "orders", Cooldown: time.Second},
+	)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `"orders"`)
+
+	got, _ := r.Lookup("search")
+	assert.True(t, search == got)
+	assert.Equal(t, []string{"payments/stripe", "search"}, r.Names())
+
+	// the settings of the previous manifest are kept
+	assert.NoError(t, r.Propagate(""))
+	got, _ = r.Lookup("search")
+	assert.Equal(t, uint32(5), got.atLeastReqs)
+}
@@ -0,0 +1,49 @@
+package easybreaker
+
+import (
+	"sync/atomic"
+)
+
+// MaxWeight is the highest cost of ExecuteWeighted, the higher ones are
+// clamped so a single request can't overflow the counts of the interval.
+const MaxWeight = 1 << 16
+
+// ExecuteWeighted is Execute for a request costing as much as cost ordinary
+// requests, e.g. a big scan or a batch write, its outcome is counted cost
+// times in the total, the failures and the timeouts, so the strategies
+// weigh it proportionally instead of counting it as a cheap ping.
+// It's still a single request in flight and a single probe of the
+// half-open state, a zero cost counts as 1, a cost above MaxWeight as
+// MaxWeight.
+func (b *Breaker) ExecuteWeighted(cost uint32, req func() error) error {
+	counted, err := b.allow(true)
+	if err != nil {
+		return err
+	}
+
+	start := b.begin()
+	start.uncounted = !counted
+	if counted {
+		start.weight = weight(cost)
+		// counted in the total at once, Done decides on the whole cost
+		atomic.AddUint64(&b.counts, uint64(start.weight-1)*totalUnit)
+	}
+	if b.chaosFailed() {
+		err = ErrChaos
+	} else {
+		err = req()
+	}
+	b.finish(start, err)
+	return err
+}
+
+// weight returns the cost counted for a request.
+func weight(cost uint32) uint32 {
+	switch {
+	case cost == 0:
+		return 1
+	case cost > MaxWeight:
+		return MaxWeight
+	}
+	return cost
+}
@@ -0,0 +1,62 @@
+package easybreaker
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_ExecuteWeighted(t *testing.T) {
+	b, err := New(time.Minute, time.Minute, WithMaxFailures(5), WithStateFunc(
+		func(total, failures uint32) bool { return false },
+		defaultToClosed,
+	))
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.ExecuteWeighted(10, func() error { return nil }))
+	assert.NoError(t, b.ExecuteWeighted(0, func() error { return nil }))
+	assert.Equal(t, Counts{Total: 11, Successes: 11}, b.Counts())
+
+	failed := errors.New("failed")
+	assert.Equal(t, failed, b.ExecuteWeighted(5, func() error { return failed }))
+	assert.Equal(t, Counts{Total: 16, Failures: 5, Successes: 11}, b.Counts())
+	assert.Equal(t, StateClosed, b.State())
+
+	// a single expensive failure trips the breaker
+	assert.Equal(t, failed, b.ExecuteWeighted(2, func() error { return failed }))
+	assert.Equal(t, StateOpen, b.State())
+	reason, _ := b.TripReason()
+	assert.Equal(t, float64(7), reason.Value)
+
+	// the timeouts are weighted, the huge costs clamped
+	b, err = New(time.Minute, time.Minute, WithStateFunc(func(total, failures uint32) bool { return false }, defaultToClosed))
+	assert.NoError(t, err)
+	b.ExecuteWeighted(3, func() error { return context.DeadlineExceeded })
+	b.ExecuteWeighted(math.MaxUint32, func() error { return nil })
+	assert.Equal(t, Counts{Total: 3 + MaxWeight, Failures: 3, Successes: MaxWeight, Timeouts: 3}, b.Counts())
+}
+
+func TestBreaker_ExecuteWeightedHalfOpen(t *testing.T) {
+	b, err := New(
+		time.Minute, time.Minute,
+		WithStateFunc(func(total, failures uint32) bool { return true }, defaultToClosed),
+		WithHalfOpenPercent(50),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+	b.Execute(func() error { return errors.New("failed") })
+	assert.Equal(t, StateOpen, b.State())
+	b.now = now(1520100000 + 61)
+
+	// the request moving the breaker to the half-open state, then every
+	// other one, the weighted ones included
+	assert.NoError(t, b.ExecuteWeighted(4, func() error { return nil }))
+	assert.NoError(t, b.ExecuteWeighted(4, func() error { return nil }))
+	assert.Equal(t, ErrBreakerOpen, b.ExecuteWeighted(4, func() error { return nil }))
+	assert.Equal(t, uint32(8), b.Counts().Successes)
+	assert.Equal(t, uint32(0), b.Counts().InFlight)
+}
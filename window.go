@@ -0,0 +1,103 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	totalUnit   = uint64(1) << 32
+	failureUnit = uint64(1)
+)
+
+func packCounts(total uint32, failures uint32) uint64 {
+	return uint64(total)<<32 | uint64(failures)
+}
+
+func unpackCounts(counts uint64) (total uint32, failures uint32) {
+	return uint32(counts >> 32), uint32(counts)
+}
+
+// ignore removes n requests whose outcome is neutral from the total.
+func (b *Breaker) ignore(n uint32) {
+	uncount(&b.counts, n)
+	atomic.AddUint32(&b.ignored, n)
+}
+
+// uncount removes n requests from the total of the packed counts,
+// the total of a window started meanwhile doesn't go below zero.
+func uncount(counts *uint64, n uint32) {
+	for {
+		old := atomic.LoadUint64(counts)
+		total, failures := unpackCounts(old)
+		m := n
+		if m > total {
+			m = total
+		}
+		if m == 0 || atomic.CompareAndSwapUint64(counts, old, packCounts(total-m, failures)) {
+			return
+		}
+	}
+}
+
+// ResetPolicy sets the transitions zeroing the counts, the counts are carried
+// into the next window otherwise. The counts are always zeroed on trip.
+type ResetPolicy uint8
+
+const (
+	ResetOnHalfOpen ResetPolicy = 1 << iota // open -> half-open
+	ResetOnClosed                           // half-open -> closed
+	ResetOnRollover                         // the interval of the closed state elapsed
+
+	DefaultResetPolicy = ResetOnHalfOpen | ResetOnClosed | ResetOnRollover
+)
+
+// ResetPolicy sets the transitions zeroing the counts, DefaultResetPolicy
+// by default, e.g. without ResetOnClosed the requests of the half-open state
+// are counted in the first interval of the closed state.
+func WithResetPolicy(policy ResetPolicy) OptionCall {
+	return func(b *Breaker) error {
+		if policy&^DefaultResetPolicy != 0 {
+			return errors.New("circuit: invalid reset policy")
+		}
+		b.resetPolicy = policy
+		return nil
+	}
+}
+
+// SeedFromProbes counts the successful probes of the half-open state
+// in the first interval of the closed state, so a single early failure
+// after the recovery doesn't trip the breaker again.
+func WithSeedFromProbes() OptionCall {
+	return func(b *Breaker) error {
+		b.seedFromProbes = true
+		return nil
+	}
+}
+
+func (b *Breaker) resetsOn(to int32) bool {
+	switch to {
+	case halfOpen:
+		return b.resetPolicy&ResetOnHalfOpen != 0
+	case closed:
+		return b.resetPolicy&ResetOnClosed != 0
+	}
+	return true
+}
+
+// Window is the snapshot of the counts of a finished interval or state.
+type Window struct {
+	Epoch  uint64 // the sequence number of the window, from 0
+	State  State  // the state of the breaker during the window
+	Start  time.Time
+	End    time.Time
+	Counts Counts
+}
+
+// LastWindow returns the last finished window,
+// false if the breaker is still in its first one.
+func (b *Breaker) LastWindow() (Window, bool) {
+	w, ok := b.lastWindow.Load().(Window)
+	return w, ok
+}
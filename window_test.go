@@ -0,0 +1,123 @@
+package easybreaker
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_LastWindow(t *testing.T) {
+	b, err := New(time.Minute, 2*time.Minute, withTime(1520100000))
+	assert.NoError(t, err)
+
+	_, ok := b.LastWindow()
+	assert.False(t, ok)
+
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+	b.now = now(1520100061)
+	b.Execute(func() error { return nil })
+
+	w, ok := b.LastWindow()
+	assert.True(t, ok)
+	assert.Equal(t, Window{
+		Epoch:  0,
+		State:  StateClosed,
+		Start:  time.Unix(1520100000, 0),
+		End:    time.Unix(1520100061, 0),
+		Counts: Counts{Total: 2, Successes: 2},
+	}, w)
+}
+
+func TestBreaker_RolloverSnapshot(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		rolled uint32
+		ticks  int64
+	)
+	b, err := New(
+		10*time.Millisecond, time.Minute,
+		WithNow(func() time.Time {
+			return time.Unix(0, atomic.AddInt64(&ticks, int64(time.Millisecond)))
+		}),
+		WithSink(SeverityInfo, func(e Event) {
+			mu.Lock()
+			rolled += e.Counts.Total
+			mu.Unlock()
+		}),
+	)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				b.Execute(func() error { return nil })
+			}
+		}()
+	}
+	wg.Wait()
+
+	// every request is counted in exactly one window
+	assert.Equal(t, uint32(8000), rolled+b.Counts().Total)
+	w, _ := b.LastWindow()
+	assert.True(t, w.Epoch > 0)
+}
+
+func TestBreaker_ResetPolicy(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithLeastReqs(2),
+		WithResetPolicy(ResetOnHalfOpen),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	// the counts are carried over the rollover
+	b.Execute(func() error { return nil })
+	b.now = now(1520100061)
+	b.Execute(func() error { return nil })
+	assert.Equal(t, uint32(2), b.Counts().Total)
+
+	// and from the half-open state into the closed one
+	b.state = halfOpen
+	b.counts = 0
+	b.until = 1520100061 * int64(time.Second)
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+	b.Execute(func() error { return nil })
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, uint32(3), b.Counts().Total)
+
+	_, err = New(time.Minute, time.Minute, WithResetPolicy(1<<5))
+	assert.Error(t, err)
+}
+
+func TestBreaker_SeedFromProbes(t *testing.T) {
+	b, err := New(
+		time.Minute, 2*time.Minute,
+		WithLeastReqs(4),
+		WithStateFunc(
+			func(total uint32, failures uint32) bool { return failures*2 >= total },
+			func(total uint32, failures uint32) bool { return failures <= 1 },
+		),
+		WithSeedFromProbes(),
+		withTime(1520100000),
+	)
+	assert.NoError(t, err)
+
+	b.state = halfOpen
+	b.counts = packCounts(4, 1)
+	b.successes = 3
+	b.Execute(func() error { return errors.New("failed") })
+
+	// the 3 successful probes are kept, the early failure doesn't trip
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, Counts{Total: 4, Failures: 1, Successes: 3}, b.Counts())
+}